@@ -0,0 +1,326 @@
+package authz
+
+import (
+	"errors"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var ErrNoActor = errors.New("no authenticated user in context")
+
+// Actor - user yang sedang melakukan request beserta profil akademiknya
+type Actor struct {
+	User       *models.User
+	RoleName   string
+	StudentID  *uuid.UUID // profil mahasiswa, jika ada
+	LecturerID *uuid.UUID // profil dosen, jika ada
+	Scopes     []models.UserScope
+
+	// APIKeyPermissions - permission key jika request diautentikasi dengan API key
+	// (nil = bukan API key). Policy dibatasi ke aksi yang diizinkan key.
+	APIKeyPermissions []string
+}
+
+// ViaAPIKey - request diautentikasi dengan API key
+func (a *Actor) ViaAPIKey() bool {
+	return a.APIKeyPermissions != nil
+}
+
+// KeyAllows - API key punya permission untuk aksi ini (selalu true untuk login biasa)
+func (a *Actor) KeyAllows(action Action) bool {
+	if !a.ViaAPIKey() {
+		return true
+	}
+	required, ok := ActionPermissions[action]
+	if !ok {
+		return false
+	}
+	for _, p := range a.APIKeyPermissions {
+		if p == required {
+			return true
+		}
+	}
+	return false
+}
+
+// Resource - objek yang diakses, dideskripsikan lewat relasinya ke mahasiswa / dosen
+type Resource struct {
+	OwnerStudentID *uuid.UUID // mahasiswa pemilik (achievement, profil mahasiswa, report)
+	AdvisorID      *uuid.UUID // dosen wali dari pemilik
+	LecturerID     *uuid.UUID // profil dosen (advisees)
+
+	ProgramStudy      string // prodi mahasiswa pemilik
+	AdvisorDepartment string // departemen dosen wali pemilik
+
+	MemberStudentIDs []uuid.UUID // anggota tim (diundang / sudah menerima), selain pemilik
+	MemberAdvisorIDs []uuid.UUID // dosen wali anggota tim yang sudah menerima
+}
+
+// Grants - hasil evaluasi policy untuk satu aksi, dipakai juga untuk menentukan scope list
+type Grants []Relation
+
+func (g Grants) Has(relation Relation) bool {
+	for _, r := range g {
+		if r == relation {
+			return true
+		}
+	}
+	return false
+}
+
+type Authorizer struct {
+	roleRepo      repository.RoleRepository
+	studentRepo   repository.StudentRepository
+	lecturerRepo  repository.LecturerRepository
+	userScopeRepo repository.UserScopeRepository
+	memberRepo    repository.AchievementMemberRepository
+	policy        Policy
+}
+
+func NewAuthorizer(
+	roleRepo repository.RoleRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	userScopeRepo repository.UserScopeRepository,
+	memberRepo repository.AchievementMemberRepository,
+) *Authorizer {
+	return &Authorizer{
+		roleRepo:      roleRepo,
+		studentRepo:   studentRepo,
+		lecturerRepo:  lecturerRepo,
+		userScopeRepo: userScopeRepo,
+		memberRepo:    memberRepo,
+		policy:        DefaultPolicy,
+	}
+}
+
+// actorLocalsKey - Actor di-cache per request (mis. bulk verify memanggil policy berkali-kali)
+const actorLocalsKey = "authz_actor"
+
+// ActorFromContext - bangun Actor dari user yang di-set middleware.RequireAuth
+func (a *Authorizer) ActorFromContext(c *fiber.Ctx) (*Actor, error) {
+	if actor, ok := c.Locals(actorLocalsKey).(*Actor); ok {
+		return actor, nil
+	}
+
+	user, ok := c.Locals("user").(*models.User)
+	if !ok || user == nil {
+		return nil, ErrNoActor
+	}
+
+	role, err := a.roleRepo.GetByID(user.RoleID)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errors.New("role not found")
+	}
+
+	actor := &Actor{User: user, RoleName: role.Name}
+
+	// Permission efektif API key (lihat middleware.RequireAuth) membatasi semua keputusan policy
+	if _, isAPIKey := c.Locals("api_key").(*models.APIKey); isAPIKey {
+		permissions, _ := c.Locals("permissions").([]string)
+		actor.APIKeyPermissions = append([]string{}, permissions...)
+	}
+
+	student, err := a.studentRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if student != nil {
+		actor.StudentID = &student.ID
+	}
+
+	lecturer, err := a.lecturerRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if lecturer != nil {
+		actor.LecturerID = &lecturer.ID
+	}
+
+	actor.Scopes, err = a.userScopeRepo.GetByUserID(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	c.Locals(actorLocalsKey, actor)
+	return actor, nil
+}
+
+// Grants - relasi yang dimiliki role actor untuk aksi ini. Relasi owner/advisor/self
+// hanya dihitung jika actor memang punya profil mahasiswa/dosen. Untuk API key,
+// aksi di luar permission key tidak mendapat relasi apa pun.
+func (a *Authorizer) Grants(actor *Actor, action Action) Grants {
+	var grants Grants
+	if !actor.KeyAllows(action) {
+		return grants
+	}
+	for _, relation := range a.policy.Relations(actor.RoleName, action) {
+		switch relation {
+		case RelationOwner, RelationMember:
+			if actor.StudentID == nil {
+				continue
+			}
+		case RelationAdvisor, RelationSelf, RelationMemberAdvisor:
+			if actor.LecturerID == nil {
+				continue
+			}
+		case RelationScope:
+			if len(actor.Scopes) == 0 {
+				continue
+			}
+		}
+		grants = append(grants, relation)
+	}
+	return grants
+}
+
+// Can - apakah actor boleh melakukan aksi terhadap resource
+func (a *Authorizer) Can(actor *Actor, action Action, resource Resource) bool {
+	for _, relation := range a.Grants(actor, action) {
+		if holds(actor, relation, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Satisfies - actor punya salah satu role, atau salah satu relasi terhadap resource.
+// Dipakai untuk aturan di luar Policy, mis. penanggung jawab stage verifikasi.
+func (a *Authorizer) Satisfies(actor *Actor, roles []string, relations []Relation, resource Resource) bool {
+	for _, role := range roles {
+		if actor.RoleName == role {
+			return true
+		}
+	}
+	for _, relation := range relations {
+		if holds(actor, relation, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+func holds(actor *Actor, relation Relation, resource Resource) bool {
+	switch relation {
+	case RelationAny:
+		return true
+	case RelationOwner:
+		return sameID(actor.StudentID, resource.OwnerStudentID)
+	case RelationAdvisor:
+		return sameID(actor.LecturerID, resource.AdvisorID)
+	case RelationSelf:
+		return sameID(actor.LecturerID, resource.LecturerID)
+	case RelationScope:
+		return inScope(actor.Scopes, resource)
+	case RelationMember:
+		return containsID(resource.MemberStudentIDs, actor.StudentID)
+	case RelationMemberAdvisor:
+		return containsID(resource.MemberAdvisorIDs, actor.LecturerID)
+	}
+	return false
+}
+
+func containsID(ids []uuid.UUID, id *uuid.UUID) bool {
+	for i := range ids {
+		if sameID(id, &ids[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+func inScope(scopes []models.UserScope, resource Resource) bool {
+	for _, scope := range scopes {
+		switch scope.ScopeType {
+		case models.ScopeTypeProgramStudy:
+			if resource.ProgramStudy != "" && scope.ScopeValue == resource.ProgramStudy {
+				return true
+			}
+		case models.ScopeTypeDepartment:
+			if resource.AdvisorDepartment != "" && scope.ScopeValue == resource.AdvisorDepartment {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func sameID(a, b *uuid.UUID) bool {
+	return a != nil && b != nil && *a != uuid.Nil && *a == *b
+}
+
+// StudentResource - resource berupa mahasiswa (profil, report, atau pemilik achievement).
+// Departemen dosen wali ikut di-load untuk keperluan scope.
+func (a *Authorizer) StudentResource(student *models.Student) (Resource, error) {
+	if student == nil {
+		return Resource{}, nil
+	}
+
+	resource := Resource{
+		OwnerStudentID: &student.ID,
+		AdvisorID:      student.AdvisorID,
+		ProgramStudy:   student.ProgramStudy,
+	}
+	if student.AdvisorID != nil {
+		advisor, err := a.lecturerRepo.GetByID(*student.AdvisorID)
+		if err != nil {
+			return Resource{}, err
+		}
+		if advisor != nil {
+			resource.AdvisorDepartment = advisor.Department
+		}
+	}
+	return resource, nil
+}
+
+func LecturerResource(lecturerID uuid.UUID) Resource {
+	return Resource{LecturerID: &lecturerID}
+}
+
+// StudentResourceByID - load mahasiswa untuk mengetahui dosen wali & prodinya
+func (a *Authorizer) StudentResourceByID(studentID uuid.UUID) (Resource, error) {
+	student, err := a.studentRepo.GetByID(studentID)
+	if err != nil {
+		return Resource{}, err
+	}
+	if student == nil {
+		return Resource{OwnerStudentID: &studentID}, nil
+	}
+	return a.StudentResource(student)
+}
+
+// AchievementResource - achievement dinilai lewat mahasiswa pemiliknya, ditambah anggota
+// tim beserta dosen walinya
+func (a *Authorizer) AchievementResource(ref *models.AchievementReference) (Resource, error) {
+	resource, err := a.StudentResourceByID(ref.StudentID)
+	if err != nil {
+		return Resource{}, err
+	}
+
+	members, err := a.memberRepo.GetByAchievement(ref.ID)
+	if err != nil {
+		return Resource{}, err
+	}
+	for _, member := range members {
+		if member.Owner || member.Status == models.MemberStatusDeclined {
+			continue
+		}
+		resource.MemberStudentIDs = append(resource.MemberStudentIDs, member.StudentID)
+		if member.Status == models.MemberStatusAccepted && member.AdvisorID != nil {
+			resource.MemberAdvisorIDs = append(resource.MemberAdvisorIDs, *member.AdvisorID)
+		}
+	}
+	return resource, nil
+}
+
+// ScopedStudentIDs - mahasiswa yang terlihat lewat scope actor (termasuk mahasiswa bimbingannya)
+func (a *Authorizer) ScopedStudentIDs(actor *Actor) ([]uuid.UUID, error) {
+	return a.userScopeRepo.GetScopedStudentIDs(actor.User.ID)
+}
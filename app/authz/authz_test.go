@@ -0,0 +1,49 @@
+package authz
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestGrantsLimitedByAPIKeyPermissions(t *testing.T) {
+	authorizer := &Authorizer{policy: DefaultPolicy}
+	studentID := uuid.New()
+
+	tests := []struct {
+		name        string
+		permissions []string // nil = login biasa
+		action      Action
+		want        bool
+	}{
+		{"login ignores key permissions", nil, ActionAchievementDelete, true},
+		{"read key can read", []string{"achievement:read"}, ActionAchievementRead, true},
+		{"read key can list", []string{"achievement:read"}, ActionAchievementList, true},
+		{"read key cannot update", []string{"achievement:read"}, ActionAchievementUpdate, false},
+		{"read key cannot submit", []string{"achievement:read"}, ActionAchievementSubmit, false},
+		{"update key can submit", []string{"achievement:update"}, ActionAchievementSubmit, true},
+		{"read key cannot comment", []string{"achievement:read"}, ActionAchievementComment, false},
+		{"comment key can comment", []string{"achievement:comment"}, ActionAchievementComment, true},
+		{"empty key gets nothing", []string{}, ActionAchievementRead, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actor := &Actor{RoleName: "Mahasiswa", StudentID: &studentID, APIKeyPermissions: tt.permissions}
+			resource := Resource{OwnerStudentID: &studentID}
+			if got := authorizer.Can(actor, tt.action, resource); got != tt.want {
+				t.Fatalf("Can(%s) = %v, want %v", tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEveryPolicyActionHasAPIKeyPermission(t *testing.T) {
+	for role, actions := range DefaultPolicy {
+		for action := range actions {
+			if _, ok := ActionPermissions[action]; !ok {
+				t.Errorf("%s: action %s has no API key permission, API keys can never use it", role, action)
+			}
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package authz
+
+// Action - aksi yang dicek oleh policy, satu aksi per kebutuhan handler
+type Action string
+
+const (
+	ActionAchievementList   Action = "achievement:list"
+	ActionAchievementRead   Action = "achievement:read"
+	ActionAchievementCreate Action = "achievement:create"
+	ActionAchievementUpdate Action = "achievement:update"
+	ActionAchievementDelete Action = "achievement:delete"
+	ActionAchievementSubmit Action = "achievement:submit"
+	ActionAchievementAttach Action = "achievement:attach"
+	// ActionAchievementComment - menulis di thread diskusi (membaca mengikuti ActionAchievementRead)
+	ActionAchievementComment Action = "achievement:comment"
+
+	ActionStudentList      Action = "student:list"
+	ActionStudentRead      Action = "student:read"
+	ActionStudentAssign    Action = "student:assign_advisor"
+	ActionLecturerList     Action = "lecturer:list"
+	ActionLecturerAdvisees Action = "lecturer:read_advisees"
+
+	ActionReportStatistics Action = "report:statistics"
+	ActionReportStudent    Action = "report:student"
+)
+
+// ActionPermissions - permission API key yang dibutuhkan untuk tiap aksi. Request lewat
+// API key hanya mendapat relasi dari policy jika key punya permission ini; aksi yang
+// tidak terdaftar selalu ditolak untuk API key.
+var ActionPermissions = map[Action]string{
+	ActionAchievementList:    "achievement:read",
+	ActionAchievementRead:    "achievement:read",
+	ActionAchievementComment: "achievement:comment",
+	ActionAchievementCreate:  "achievement:create",
+	ActionAchievementUpdate:  "achievement:update",
+	ActionAchievementSubmit:  "achievement:update",
+	ActionAchievementAttach:  "achievement:update",
+	ActionAchievementDelete:  "achievement:delete",
+
+	ActionStudentList:      "achievement:read",
+	ActionStudentRead:      "achievement:read",
+	ActionLecturerList:     "achievement:read",
+	ActionLecturerAdvisees: "achievement:read",
+	ActionReportStatistics: "achievement:read",
+	ActionReportStudent:    "achievement:read",
+
+	// Sama dengan endpoint admin lain (middleware.AdminOnly)
+	ActionStudentAssign: "user:manage",
+}
+
+// Relation - hubungan actor dengan resource yang membuat aksi diizinkan
+type Relation string
+
+const (
+	// RelationAny - semua resource (admin)
+	RelationAny Relation = "any"
+	// RelationOwner - mahasiswa pemilik resource
+	RelationOwner Relation = "owner"
+	// RelationAdvisor - dosen wali dari mahasiswa pemilik resource
+	RelationAdvisor Relation = "advisor"
+	// RelationSelf - resource adalah profil dosen actor sendiri
+	RelationSelf Relation = "self"
+	// RelationScope - mahasiswa pemilik masuk prodi / departemen yang di-assign ke actor
+	RelationScope Relation = "scope"
+	// RelationMember - mahasiswa anggota tim prestasi (diundang atau sudah menerima)
+	RelationMember Relation = "member"
+	// RelationMemberAdvisor - dosen wali dari anggota tim yang sudah menerima
+	RelationMemberAdvisor Relation = "member_advisor"
+)
+
+// AnyRole - entry policy yang berlaku untuk semua role (termasuk role buatan admin)
+const AnyRole = "*"
+
+// RolePolicy - aksi -> relasi yang diizinkan untuk satu role
+type RolePolicy map[Action][]Relation
+
+// Policy - role name -> RolePolicy. Role baru cukup ditambahkan di sini,
+// handler tidak perlu diubah.
+type Policy map[string]RolePolicy
+
+// DefaultPolicy - aturan akses yang sebelumnya tersebar di switch role.Name tiap handler
+var DefaultPolicy = Policy{
+	"Admin": {
+		ActionAchievementList:    {RelationAny},
+		ActionAchievementRead:    {RelationAny},
+		ActionAchievementCreate:  {RelationAny},
+		ActionAchievementUpdate:  {RelationAny},
+		ActionAchievementDelete:  {RelationAny},
+		ActionAchievementSubmit:  {RelationAny},
+		ActionAchievementAttach:  {RelationAny},
+		ActionAchievementComment: {RelationAny},
+		ActionStudentList:        {RelationAny},
+		ActionStudentRead:        {RelationAny},
+		ActionStudentAssign:      {RelationAny},
+		ActionLecturerList:       {RelationAny},
+		ActionLecturerAdvisees:   {RelationAny},
+		ActionReportStatistics:   {RelationAny},
+		ActionReportStudent:      {RelationAny},
+	},
+	"Mahasiswa": {
+		ActionAchievementList:    {RelationOwner},
+		ActionAchievementRead:    {RelationOwner, RelationMember},
+		ActionAchievementCreate:  {RelationOwner},
+		ActionAchievementUpdate:  {RelationOwner},
+		ActionAchievementDelete:  {RelationOwner},
+		ActionAchievementSubmit:  {RelationOwner},
+		ActionAchievementAttach:  {RelationOwner},
+		ActionAchievementComment: {RelationOwner, RelationMember},
+		ActionStudentRead:        {RelationOwner},
+		ActionReportStatistics:   {RelationOwner},
+		ActionReportStudent:      {RelationOwner},
+	},
+	"Dosen Wali": {
+		ActionAchievementList:    {RelationAdvisor},
+		ActionAchievementRead:    {RelationAdvisor, RelationMemberAdvisor},
+		ActionAchievementComment: {RelationAdvisor, RelationMemberAdvisor},
+		ActionStudentRead:        {RelationAdvisor},
+		ActionLecturerAdvisees:   {RelationSelf},
+		ActionReportStatistics:   {RelationAdvisor},
+		ActionReportStudent:      {RelationAdvisor},
+	},
+	// Scope prodi / departemen hanya aktif jika user punya user_scopes.
+	// Verifikasi tidak diatur di sini, tapi oleh stage workflow (lihat app/workflow).
+	AnyRole: {
+		ActionAchievementList:  {RelationScope},
+		ActionAchievementRead:  {RelationScope},
+		ActionStudentList:      {RelationScope},
+		ActionStudentRead:      {RelationScope},
+		ActionReportStatistics: {RelationScope},
+		ActionReportStudent:    {RelationScope},
+	},
+}
+
+// Relations - relasi yang diizinkan untuk role pada aksi tertentu, ditambah entry AnyRole
+func (p Policy) Relations(roleName string, action Action) []Relation {
+	relations := append([]Relation{}, p[roleName][action]...)
+	return append(relations, p[AnyRole][action]...)
+}
@@ -0,0 +1,151 @@
+package authz
+
+import (
+	"testing"
+
+	"UAS/app/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCanDefaultPolicy(t *testing.T) {
+	authorizer := &Authorizer{policy: DefaultPolicy}
+
+	owner, member, stranger := uuid.New(), uuid.New(), uuid.New()
+	advisor, memberAdvisor, otherLecturer := uuid.New(), uuid.New(), uuid.New()
+
+	achievement := Resource{
+		OwnerStudentID:   &owner,
+		AdvisorID:        &advisor,
+		MemberStudentIDs: []uuid.UUID{member},
+		MemberAdvisorIDs: []uuid.UUID{memberAdvisor},
+	}
+
+	admin := &Actor{RoleName: "Admin"}
+	ownerActor := &Actor{RoleName: "Mahasiswa", StudentID: &owner}
+	memberActor := &Actor{RoleName: "Mahasiswa", StudentID: &member}
+	strangerActor := &Actor{RoleName: "Mahasiswa", StudentID: &stranger}
+	// Role Mahasiswa tanpa profil mahasiswa tidak boleh cocok dengan relasi owner
+	noProfile := &Actor{RoleName: "Mahasiswa"}
+	advisorActor := &Actor{RoleName: "Dosen Wali", LecturerID: &advisor}
+	memberAdvisorActor := &Actor{RoleName: "Dosen Wali", LecturerID: &memberAdvisor}
+	otherLecturerActor := &Actor{RoleName: "Dosen Wali", LecturerID: &otherLecturer}
+	customRole := &Actor{RoleName: "Kaprodi"}
+
+	tests := []struct {
+		name     string
+		actor    *Actor
+		action   Action
+		resource Resource
+		want     bool
+	}{
+		{"admin reads any achievement", admin, ActionAchievementRead, achievement, true},
+		{"admin assigns advisor", admin, ActionStudentAssign, achievement, true},
+
+		{"owner reads", ownerActor, ActionAchievementRead, achievement, true},
+		{"owner updates", ownerActor, ActionAchievementUpdate, achievement, true},
+		{"owner deletes", ownerActor, ActionAchievementDelete, achievement, true},
+		{"owner cannot assign advisor", ownerActor, ActionStudentAssign, achievement, false},
+		{"member reads", memberActor, ActionAchievementRead, achievement, true},
+		{"member comments", memberActor, ActionAchievementComment, achievement, true},
+		{"member cannot update", memberActor, ActionAchievementUpdate, achievement, false},
+		{"member cannot delete", memberActor, ActionAchievementDelete, achievement, false},
+		{"stranger cannot read", strangerActor, ActionAchievementRead, achievement, false},
+		{"student without profile", noProfile, ActionAchievementRead, Resource{}, false},
+		{"student cannot list students", ownerActor, ActionStudentList, achievement, false},
+
+		{"advisor reads", advisorActor, ActionAchievementRead, achievement, true},
+		{"advisor cannot update", advisorActor, ActionAchievementUpdate, achievement, false},
+		{"member advisor reads", memberAdvisorActor, ActionAchievementRead, achievement, true},
+		{"member advisor cannot see student report", memberAdvisorActor, ActionReportStudent, achievement, false},
+		{"other lecturer cannot read", otherLecturerActor, ActionAchievementRead, achievement, false},
+		{"lecturer reads own advisees", advisorActor, ActionLecturerAdvisees, LecturerResource(advisor), true},
+		{"lecturer cannot read other advisees", advisorActor, ActionLecturerAdvisees, LecturerResource(otherLecturer), false},
+
+		{"custom role without scope", customRole, ActionAchievementRead, achievement, false},
+		{"unknown action", admin, Action("achievement:unknown"), achievement, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authorizer.Can(tt.actor, tt.action, tt.resource); got != tt.want {
+				t.Fatalf("Can(%s, %s) = %v, want %v", tt.actor.RoleName, tt.action, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	authorizer := &Authorizer{policy: DefaultPolicy}
+	owner, advisor := uuid.New(), uuid.New()
+	resource := Resource{OwnerStudentID: &owner, AdvisorID: &advisor}
+
+	tests := []struct {
+		name      string
+		actor     *Actor
+		roles     []string
+		relations []Relation
+		want      bool
+	}{
+		{"matching role", &Actor{RoleName: "Admin"}, []string{"Admin"}, nil, true},
+		{"matching relation", &Actor{RoleName: "Dosen Wali", LecturerID: &advisor}, nil, []Relation{RelationAdvisor}, true},
+		{"neither", &Actor{RoleName: "Dosen Wali", LecturerID: &owner}, []string{"Admin"}, []Relation{RelationAdvisor}, false},
+		{"nil ids never match", &Actor{RoleName: "Mahasiswa"}, nil, []Relation{RelationOwner}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authorizer.Satisfies(tt.actor, tt.roles, tt.relations, resource); got != tt.want {
+				t.Fatalf("Satisfies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPolicyRelationsIncludeAnyRole(t *testing.T) {
+	relations := DefaultPolicy.Relations("Kaprodi", ActionAchievementList)
+	if len(relations) != 1 || relations[0] != RelationScope {
+		t.Fatalf("Relations(Kaprodi) = %v, want [scope]", relations)
+	}
+
+	// Relations tidak boleh mengubah slice milik policy
+	relations = DefaultPolicy.Relations("Mahasiswa", ActionAchievementRead)
+	relations[0] = RelationAny
+	if DefaultPolicy["Mahasiswa"][ActionAchievementRead][0] != RelationOwner {
+		t.Fatal("Relations mutated DefaultPolicy")
+	}
+}
+
+func TestGrantsSkipRelationsWithoutProfile(t *testing.T) {
+	authorizer := &Authorizer{policy: DefaultPolicy}
+	scope := []models.UserScope{{ScopeType: models.ScopeTypeProgramStudy, ScopeValue: "Informatika"}}
+
+	tests := []struct {
+		name  string
+		actor *Actor
+		want  Grants
+	}{
+		{"student with profile", &Actor{RoleName: "Mahasiswa", StudentID: ptr(uuid.New())}, Grants{RelationOwner}},
+		{"student without profile", &Actor{RoleName: "Mahasiswa"}, nil},
+		{"lecturer without profile", &Actor{RoleName: "Dosen Wali"}, nil},
+		{"custom role with scope", &Actor{RoleName: "Kaprodi", Scopes: scope}, Grants{RelationScope}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := authorizer.Grants(tt.actor, ActionAchievementList)
+			if len(got) != len(tt.want) {
+				t.Fatalf("Grants = %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("Grants = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func ptr(id uuid.UUID) *uuid.UUID {
+	return &id
+}
@@ -0,0 +1,50 @@
+package authz
+
+import (
+	"testing"
+
+	"UAS/app/models"
+
+	"github.com/google/uuid"
+)
+
+func TestCanWithScopes(t *testing.T) {
+	authorizer := &Authorizer{policy: DefaultPolicy}
+	owner := uuid.New()
+
+	informatika := Resource{OwnerStudentID: &owner, ProgramStudy: "Informatika", AdvisorDepartment: "Teknik"}
+	noAdvisor := Resource{OwnerStudentID: &owner, ProgramStudy: "Sistem Informasi"}
+
+	prodi := []models.UserScope{{ScopeType: models.ScopeTypeProgramStudy, ScopeValue: "Informatika"}}
+	department := []models.UserScope{{ScopeType: models.ScopeTypeDepartment, ScopeValue: "Teknik"}}
+	// Nilai scope kosong tidak boleh cocok dengan resource yang field-nya kosong
+	emptyDepartment := []models.UserScope{{ScopeType: models.ScopeTypeDepartment, ScopeValue: ""}}
+
+	tests := []struct {
+		name     string
+		actor    *Actor
+		action   Action
+		resource Resource
+		want     bool
+	}{
+		{"program study scope reads", &Actor{RoleName: "Kaprodi", Scopes: prodi}, ActionAchievementRead, informatika, true},
+		{"program study scope lists students", &Actor{RoleName: "Kaprodi", Scopes: prodi}, ActionStudentRead, informatika, true},
+		{"program study scope sees report", &Actor{RoleName: "Kaprodi", Scopes: prodi}, ActionReportStudent, informatika, true},
+		{"other program study", &Actor{RoleName: "Kaprodi", Scopes: prodi}, ActionAchievementRead, noAdvisor, false},
+		{"department scope via advisor", &Actor{RoleName: "Dekan", Scopes: department}, ActionAchievementRead, informatika, true},
+		{"department scope, no advisor", &Actor{RoleName: "Dekan", Scopes: department}, ActionAchievementRead, noAdvisor, false},
+		{"empty scope value", &Actor{RoleName: "Dekan", Scopes: emptyDepartment}, ActionAchievementRead, noAdvisor, false},
+		{"scope does not allow update", &Actor{RoleName: "Kaprodi", Scopes: prodi}, ActionAchievementUpdate, informatika, false},
+		{"scope does not allow assign", &Actor{RoleName: "Kaprodi", Scopes: prodi}, ActionStudentAssign, informatika, false},
+		{"lecturer role also gets scope", &Actor{RoleName: "Dosen Wali", LecturerID: ptr(uuid.New()), Scopes: prodi}, ActionAchievementRead, informatika, true},
+		{"unknown scope type", &Actor{RoleName: "Kaprodi", Scopes: []models.UserScope{{ScopeType: "faculty", ScopeValue: "Informatika"}}}, ActionAchievementRead, informatika, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := authorizer.Can(tt.actor, tt.action, tt.resource); got != tt.want {
+				t.Fatalf("Can(%s) = %v, want %v", tt.action, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+// Package duplicate mendeteksi prestasi yang kemungkinan sama dengan prestasi lain:
+// cocok persis (nomor sertifikat, hash lampiran) atau mirip (judul + tanggal + penyelenggara).
+package duplicate
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"UAS/app/models"
+)
+
+const (
+	ReasonCertificationNumber = "certification_number"
+	ReasonAttachmentHash      = "attachment_hash"
+	ReasonSimilarEvent        = "similar_event"
+)
+
+// TitleThreshold - kemiripan judul minimal (0..1) untuk dianggap event yang sama
+const TitleThreshold = 0.8
+
+// Compare - alasan b dianggap duplikat a (kosong = bukan duplikat) dan kemiripan judulnya
+func Compare(a, b *models.Achievement) ([]string, float64) {
+	reasons := []string{}
+
+	if number := NormalizeNumber(a.Details.CertificationNumber); number != "" &&
+		number == NormalizeNumber(b.Details.CertificationNumber) {
+		reasons = append(reasons, ReasonCertificationNumber)
+	}
+
+	if sharesHash(a.Attachments, b.Attachments) {
+		reasons = append(reasons, ReasonAttachmentHash)
+	}
+
+	similarity := titleSimilarity(a, b)
+	if similarity >= TitleThreshold && sameDay(a.Details.EventDate, b.Details.EventDate) &&
+		sameOrganizer(a.Details.Organizer, b.Details.Organizer) {
+		reasons = append(reasons, ReasonSimilarEvent)
+	}
+
+	return reasons, similarity
+}
+
+// Hashes - hash lampiran yang sudah dihitung saat upload
+func Hashes(attachments []models.Attachment) []string {
+	hashes := []string{}
+	for _, attachment := range attachments {
+		if attachment.SHA256 != "" {
+			hashes = append(hashes, attachment.SHA256)
+		}
+	}
+	return hashes
+}
+
+// Similarity - koefisien Dice atas bigram huruf dari teks yang sudah dinormalisasi
+func Similarity(a, b string) float64 {
+	a, b = normalize(a), normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	left, right := bigrams(a), bigrams(b)
+	if len(left) == 0 || len(right) == 0 {
+		return 0
+	}
+
+	counts := map[string]int{}
+	for _, gram := range left {
+		counts[gram]++
+	}
+	shared := 0
+	for _, gram := range right {
+		if counts[gram] > 0 {
+			counts[gram]--
+			shared++
+		}
+	}
+	return float64(2*shared) / float64(len(left)+len(right))
+}
+
+// titleSimilarity - judul dibandingkan dengan judul, dan nama kompetisi dengan nama kompetisi
+func titleSimilarity(a, b *models.Achievement) float64 {
+	similarity := Similarity(a.Title, b.Title)
+	if competition := Similarity(a.Details.CompetitionName, b.Details.CompetitionName); competition > similarity {
+		similarity = competition
+	}
+	return similarity
+}
+
+func sharesHash(a, b []models.Attachment) bool {
+	hashes := map[string]bool{}
+	for _, hash := range Hashes(a) {
+		hashes[hash] = true
+	}
+	for _, hash := range Hashes(b) {
+		if hashes[hash] {
+			return true
+		}
+	}
+	return false
+}
+
+func sameDay(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return false
+	}
+	ay, am, ad := a.UTC().Date()
+	by, bm, bd := b.UTC().Date()
+	return ay == by && am == bm && ad == bd
+}
+
+// sameOrganizer - penyelenggara harus diisi di keduanya; ejaan sedikit berbeda masih dianggap sama
+func sameOrganizer(a, b string) bool {
+	return Similarity(a, b) >= TitleThreshold
+}
+
+// NormalizeNumber - nomor sertifikat hanya huruf kecil dan angka, jadi "ABC-123",
+// "abc 123" dan "ABC/123" dianggap sama
+func NormalizeNumber(s string) string {
+	return strings.ReplaceAll(normalize(s), " ", "")
+}
+
+// normalize - huruf kecil, hanya huruf/angka, spasi tunggal
+func normalize(s string) string {
+	var b strings.Builder
+	space := false
+	for _, r := range strings.ToLower(s) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			if space && b.Len() > 0 {
+				b.WriteRune(' ')
+			}
+			b.WriteRune(r)
+			space = false
+		} else {
+			space = true
+		}
+	}
+	return b.String()
+}
+
+func bigrams(s string) []string {
+	runes := []rune(s)
+	grams := make([]string, 0, len(runes))
+	for i := 0; i+1 < len(runes); i++ {
+		grams = append(grams, string(runes[i:i+2]))
+	}
+	return grams
+}
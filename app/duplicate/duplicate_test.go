@@ -0,0 +1,130 @@
+package duplicate
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"UAS/app/models"
+)
+
+func TestNormalizeNumber(t *testing.T) {
+	tests := []struct {
+		number string
+		want   string
+	}{
+		{"ABC-123", "abc123"},
+		{"ABC 123", "abc123"},
+		{" abc/123 ", "abc123"},
+		{"abc.1.2.3", "abc123"},
+		{"--", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		if got := NormalizeNumber(tt.number); got != tt.want {
+			t.Errorf("NormalizeNumber(%q) = %q, want %q", tt.number, got, tt.want)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b    string
+		min     float64
+		max     float64
+		comment string
+	}{
+		{"Juara 1 Gemastik", "juara 1 gemastik!", 1, 1, "case and punctuation ignored"},
+		{"Juara 1 Gemastik 2024", "Juara I Gemastik 2024", TitleThreshold, 1, "small spelling difference"},
+		{"Juara 1 Gemastik", "Sertifikasi CCNA", 0, 0.3, "different titles"},
+		{"", "Gemastik", 0, 0, "empty title"},
+		{"a", "b", 0, 0, "too short for bigrams"},
+	}
+
+	for _, tt := range tests {
+		got := Similarity(tt.a, tt.b)
+		if got < tt.min || got > tt.max {
+			t.Errorf("%s: Similarity(%q, %q) = %.2f, want between %.2f and %.2f", tt.comment, tt.a, tt.b, got, tt.min, tt.max)
+		}
+	}
+}
+
+func TestCompare(t *testing.T) {
+	day := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	sameDayLater := day.Add(5 * time.Hour)
+	nextDay := day.AddDate(0, 0, 1)
+
+	base := func() *models.Achievement {
+		return &models.Achievement{
+			Title:       "Juara 1 Gemastik 2024",
+			Details:     models.AchievementDetails{EventDate: &day, Organizer: "Puspresnas"},
+			Attachments: []models.Attachment{{SHA256: "aaa"}},
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(b *models.Achievement)
+		want   []string
+	}{
+		{"same event, same attachment", func(b *models.Achievement) {}, []string{ReasonAttachmentHash, ReasonSimilarEvent}},
+		{"different title, no shared attachment", func(b *models.Achievement) {
+			b.Title = "Sertifikasi"
+			b.Attachments = nil
+		}, []string{}},
+		{"different event date", func(b *models.Achievement) {
+			b.Attachments = nil
+			b.Details.EventDate = &nextDay
+		}, []string{}},
+		{"same day, different time", func(b *models.Achievement) {
+			b.Attachments = nil
+			b.Details.EventDate = &sameDayLater
+		}, []string{ReasonSimilarEvent}},
+		{"different organizer", func(b *models.Achievement) {
+			b.Attachments = nil
+			b.Details.Organizer = "Kampus Merdeka"
+		}, []string{}},
+		{"missing organizer", func(b *models.Achievement) {
+			b.Attachments = nil
+			b.Details.Organizer = ""
+		}, []string{}},
+		{"empty attachment hash ignored", func(b *models.Achievement) {
+			b.Title = "Lain"
+			b.Attachments = []models.Attachment{{SHA256: ""}}
+		}, []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a, b := base(), base()
+			tt.modify(b)
+			reasons, _ := Compare(a, b)
+			if !reflect.DeepEqual(reasons, tt.want) {
+				t.Fatalf("reasons = %v, want %v", reasons, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompareCertificationNumber(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"ABC-123", "ABC 123", true},
+		{"ABC-123", "abc123", true},
+		{"ABC-123", "ABC-124", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		a := &models.Achievement{Title: "Sertifikasi A", Details: models.AchievementDetails{CertificationNumber: tt.a}}
+		b := &models.Achievement{Title: "Pelatihan B", Details: models.AchievementDetails{CertificationNumber: tt.b}}
+		reasons, _ := Compare(a, b)
+		got := reflect.DeepEqual(reasons, []string{ReasonCertificationNumber})
+		if got != tt.want {
+			t.Errorf("Compare(%q, %q) reasons = %v, want match %v", tt.a, tt.b, reasons, tt.want)
+		}
+	}
+}
@@ -31,5 +31,4 @@ type CreateAchievementRequest struct {
 	Details         AchievementDetails `json:"details"`
 	Attachments     []Attachment       `json:"attachments"`
 	Tags            []string           `json:"tags"`
-	Points          int                `json:"points"`
 }
\ No newline at end of file
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type AchievementComment struct {
+	ID               uuid.UUID `json:"id"`
+	AchievementRefID uuid.UUID `json:"achievement_id"`
+	AuthorID         uuid.UUID `json:"author_id"`
+	AuthorName       string    `json:"author_name"`
+	AuthorRole       string    `json:"author_role"`
+	Body             string    `json:"body"`
+	// ChangeRequested - reviewer meminta mahasiswa mengubah sesuatu
+	ChangeRequested bool `json:"change_requested"`
+	// Fields - field AchievementDetails yang dibahas (nama JSON, mis. "competition_level")
+	Fields    []string   `json:"fields"`
+	CreatedAt time.Time  `json:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at"`
+	Edited    bool       `json:"edited"`
+	DeletedAt *time.Time `json:"-"`
+}
+
+type CreateAchievementCommentRequest struct {
+	Body            string   `json:"body"`
+	ChangeRequested bool     `json:"change_requested"`
+	Fields          []string `json:"fields"`
+}
+
+type UpdateAchievementCommentRequest struct {
+	Body            *string   `json:"body"`
+	ChangeRequested *bool     `json:"change_requested"`
+	Fields          *[]string `json:"fields"`
+}
+
+// AchievementDetailFields - nama JSON semua field AchievementDetails yang bisa disebut di komentar
+func AchievementDetailFields() []string {
+	t := reflect.TypeOf(AchievementDetails{})
+	fields := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	return fields
+}
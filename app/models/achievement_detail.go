@@ -24,6 +24,8 @@ type AchievementDetails struct {
 	CertificationName    string      `bson:"certificationName,omitempty" json:"certification_name,omitempty"`
 	IssuedBy             string      `bson:"issuedBy,omitempty" json:"issued_by,omitempty"`
 	CertificationNumber  string      `bson:"certificationNumber,omitempty" json:"certification_number,omitempty"`
+	// Nomor sertifikat tanpa spasi/tanda baca untuk deteksi duplikat, diisi repository saat simpan
+	CertificationNumberNormalized string `bson:"certificationNumberNormalized,omitempty" json:"-"`
 	ValidUntil           *time.Time  `bson:"validUntil,omitempty" json:"valid_until,omitempty"`
 
 	EventDate            *time.Time  `bson:"eventDate,omitempty" json:"event_date,omitempty"`
@@ -31,7 +33,7 @@ type AchievementDetails struct {
 	Organizer            string      `bson:"organizer,omitempty" json:"organizer,omitempty"`
 	Score                int         `bson:"score,omitempty" json:"score,omitempty"`
 
-	CustomFields         any         `bson:"customFields,omitempty" json:"custom_fields,omitempty"`
+	CustomFields         map[string]interface{} `bson:"customFields,omitempty" json:"custom_fields,omitempty"`
 }
 
 type Period struct {
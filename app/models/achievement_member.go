@@ -0,0 +1,62 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	MemberRoleLeader = "leader"
+	MemberRoleMember = "member"
+
+	MemberStatusInvited  = "invited"
+	MemberStatusAccepted = "accepted"
+	MemberStatusDeclined = "declined"
+
+	MemberVerificationPending  = "pending"
+	MemberVerificationVerified = "verified"
+	MemberVerificationRejected = "rejected"
+)
+
+// AchievementMember - satu mahasiswa dalam tim sebuah prestasi. Owner = pemilik prestasi,
+// verifikasinya mengikuti workflow prestasi (VerificationStatus nil di database).
+type AchievementMember struct {
+	ID                 uuid.UUID  `json:"id"`
+	AchievementRefID   uuid.UUID  `json:"achievement_id"`
+	StudentID          uuid.UUID  `json:"student_id"`
+	StudentNumber      string     `json:"student_number"`
+	StudentName        string     `json:"student_name"`
+	AdvisorID          *uuid.UUID `json:"advisor_id"`
+	Role               string     `json:"role"`
+	Owner              bool       `json:"owner"`
+	Status             string     `json:"status"`
+	VerificationStatus *string    `json:"verification_status"`
+	VerifiedBy         *uuid.UUID `json:"verified_by"`
+	VerifiedAt         *time.Time `json:"verified_at"`
+	RejectionNote      *string    `json:"rejection_note"`
+	InvitedBy          *uuid.UUID `json:"invited_by"`
+	RespondedAt        *time.Time `json:"responded_at"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// InviteMemberRequest - POST /achievements/:id/members
+type InviteMemberRequest struct {
+	StudentID string `json:"student_id"`
+	Role      string `json:"role"`
+}
+
+// UpdateMemberRequest - PUT /achievements/:id/members/:studentId
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// RespondInvitationRequest - POST /achievements/:id/members/respond
+type RespondInvitationRequest struct {
+	Accept bool `json:"accept"`
+}
+
+// RejectMemberRequest - POST /achievements/:id/members/:studentId/reject
+type RejectMemberRequest struct {
+	RejectionNote string `json:"rejection_note"`
+}
@@ -23,6 +23,53 @@ type AchievementReference struct {
 	VerifiedAt         *time.Time `json:"verified_at"`
 	VerifiedBy         *uuid.UUID `json:"verified_by"`
 	RejectionNote      *string    `json:"rejection_note"`
+	Revision           int        `json:"revision"` // bertambah setiap kali diajukan ulang setelah ditolak
+	WorkflowStage      *string    `json:"current_stage"` // stage verifikasi saat status submitted
+	Points             int        `json:"points"`              // poin final, diisi saat verified
+	PointsRuleVersion  *int       `json:"points_rule_version"` // versi aturan poin yang dipakai
 	CreatedAt          time.Time  `json:"created_at"`
 	UpdatedAt          time.Time  `json:"updated_at"`
-}
\ No newline at end of file
+}
+
+// AchievementStatusEvent - satu baris di log transisi status (achievement_status_events)
+type AchievementStatusEvent struct {
+	ID               uuid.UUID  `json:"id"`
+	AchievementRefID uuid.UUID  `json:"achievement_id"`
+	FromStatus       *string    `json:"from_status"`
+	ToStatus         string     `json:"to_status"`
+	ChangedBy        *uuid.UUID `json:"changed_by"`
+	ChangedByName    string     `json:"changed_by_name,omitempty"`
+	Note             *string    `json:"note"`
+	RequestID        string     `json:"request_id,omitempty"`
+	Stage            string     `json:"stage,omitempty"` // stage verifikasi tempat transisi terjadi
+	Backfilled       bool       `json:"backfilled,omitempty"` // direkonstruksi dari data lama, bukan tercatat saat terjadi
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// StatusChange - siapa / kenapa sebuah transisi terjadi, dicatat bersama perubahan status
+type StatusChange struct {
+	ChangedBy uuid.UUID
+	Note      string
+	RequestID string
+	Stage     string
+}
+
+// BulkVerifyRequest - POST /achievements/bulk/verify
+type BulkVerifyRequest struct {
+	IDs  []string `json:"ids"`
+	Note string   `json:"note"`
+}
+
+// BulkRejectItem - catatan penolakan khusus untuk satu achievement
+type BulkRejectItem struct {
+	ID            string `json:"id"`
+	RejectionNote string `json:"rejection_note"`
+}
+
+// BulkRejectRequest - POST /achievements/bulk/reject. RejectionNote dipakai untuk IDs
+// dan untuk item yang tidak punya catatan sendiri.
+type BulkRejectRequest struct {
+	IDs           []string         `json:"ids"`
+	RejectionNote string           `json:"rejection_note"`
+	Items         []BulkRejectItem `json:"items"`
+}
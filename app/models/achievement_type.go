@@ -0,0 +1,65 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Tipe nilai custom field
+const (
+	CustomFieldText   = "text"
+	CustomFieldNumber = "number"
+	CustomFieldDate   = "date"
+	CustomFieldEnum   = "enum"
+	CustomFieldURL    = "url"
+)
+
+func IsCustomFieldType(fieldType string) bool {
+	switch fieldType {
+	case CustomFieldText, CustomFieldNumber, CustomFieldDate, CustomFieldEnum, CustomFieldURL:
+		return true
+	}
+	return false
+}
+
+type AchievementType struct {
+	ID          uuid.UUID               `json:"id"`
+	Code        string                  `json:"code"`
+	NameID      string                  `json:"name_id"`
+	NameEN      string                  `json:"name_en"`
+	Description string                  `json:"description"`
+	BuiltIn     bool                    `json:"built_in"`
+	Fields      []CustomFieldDefinition `json:"custom_fields"`
+	RetiredAt   *time.Time              `json:"retired_at,omitempty"`
+	CreatedAt   time.Time               `json:"created_at"`
+	UpdatedAt   time.Time               `json:"updated_at"`
+}
+
+// CustomFieldDefinition - satu field di AchievementDetails.CustomFields. Field yang retired
+// tidak bisa dipakai di prestasi baru, tapi nilai lama tetap diterima.
+type CustomFieldDefinition struct {
+	Key      string   `json:"key"`
+	LabelID  string   `json:"label_id"`
+	LabelEN  string   `json:"label_en"`
+	Type     string   `json:"type"`
+	Required bool     `json:"required"`
+	Options  []string `json:"options,omitempty"`
+	Retired  bool     `json:"retired,omitempty"`
+}
+
+type CreateAchievementTypeRequest struct {
+	Code        string                  `json:"code"`
+	NameID      string                  `json:"name_id"`
+	NameEN      string                  `json:"name_en"`
+	Description string                  `json:"description"`
+	Fields      []CustomFieldDefinition `json:"custom_fields"`
+}
+
+// UpdateAchievementTypeRequest - custom_fields menggantikan seluruh daftar field aktif
+type UpdateAchievementTypeRequest struct {
+	NameID      *string                  `json:"name_id"`
+	NameEN      *string                  `json:"name_en"`
+	Description *string                  `json:"description"`
+	Fields      *[]CustomFieldDefinition `json:"custom_fields"`
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// AchievementVersion - snapshot isi prestasi setiap kali disimpan (collection achievement_versions)
+type AchievementVersion struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	AchievementID   string             `bson:"achievementId" json:"achievement_mongo_id"`
+	Version         int                `bson:"version" json:"version"`
+	AchievementType string             `bson:"achievementType" json:"achievement_type"`
+	Title           string             `bson:"title" json:"title"`
+	Description     string             `bson:"description" json:"description"`
+	Details         AchievementDetails `bson:"details" json:"details"`
+	Attachments     []Attachment       `bson:"attachments" json:"attachments"`
+	Tags            []string           `bson:"tags" json:"tags"`
+	Points          int                `bson:"points" json:"points"`
+	// Revisi reference saat versi ini disimpan (lihat ResubmitAchievement)
+	Revision  int       `bson:"revision" json:"revision"`
+	SavedBy   uuid.UUID `bson:"savedBy" json:"saved_by"`
+	CreatedAt time.Time `bson:"createdAt" json:"created_at"`
+}
+
+// FieldChange - perubahan satu field antara dua versi. Field list (tags, attachments)
+// diisi Added/Removed, field lain Old/New.
+type FieldChange struct {
+	Field   string      `json:"field"`
+	Old     interface{} `json:"old,omitempty"`
+	New     interface{} `json:"new,omitempty"`
+	Added   interface{} `json:"added,omitempty"`
+	Removed interface{} `json:"removed,omitempty"`
+}
+
+type AchievementDiff struct {
+	FromVersion int           `json:"from_version"`
+	ToVersion   int           `json:"to_version"`
+	Changes     []FieldChange `json:"changes"`
+}
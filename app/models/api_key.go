@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIKeyPrefix - awalan semua API key, memudahkan secret scanning
+const APIKeyPrefix = "uas"
+
+type APIKey struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Name        string     `json:"name" db:"name"`
+	Prefix      string     `json:"prefix" db:"prefix"`
+	KeyHash     string     `json:"-" db:"key_hash"`
+	Permissions []string   `json:"permissions" db:"permissions"`
+	ExpiresAt   *time.Time `json:"expiresAt" db:"expires_at"`
+	LastUsedAt  *time.Time `json:"lastUsedAt" db:"last_used_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	RevokedAt   *time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+func (k *APIKey) IsUsable(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || k.ExpiresAt.After(now)
+}
+
+type CreateAPIKeyRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Permissions []string `json:"permissions" binding:"required"`
+	// Kosong = tidak pernah expired
+	ExpiresInDays *int `json:"expiresInDays,omitempty"`
+	// Hanya admin: buat key untuk user lain (mis. service account)
+	UserID *string `json:"userId,omitempty" binding:"omitempty,uuid"`
+}
+
+type CreateAPIKeyResponse struct {
+	APIKey
+	// Key lengkap hanya ditampilkan sekali saat dibuat
+	Key string `json:"key"`
+}
@@ -9,4 +9,5 @@ type Attachment struct {
 	FileURL    string    `bson:"fileUrl" json:"file_url"`
 	FileType   string    `bson:"fileType" json:"file_type"`
 	UploadedAt time.Time `bson:"uploadedAt" json:"uploaded_at"`
+	SHA256     string    `bson:"sha256,omitempty" json:"sha256,omitempty"` // hash isi file, untuk deteksi duplikat
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package models
+
+import (
+	"github.com/google/uuid"
+)
+
+// DuplicateMatch - prestasi lain yang kemungkinan sama. Untuk mahasiswa, prestasi milik
+// mahasiswa lain hanya berisi Reasons dan TitleSimilarity.
+type DuplicateMatch struct {
+	AchievementID   *uuid.UUID `json:"achievement_id,omitempty"`
+	StudentID       *uuid.UUID `json:"student_id,omitempty"`
+	StudentNumber   string     `json:"student_number,omitempty"`
+	StudentName     string     `json:"student_name,omitempty"`
+	Title           string     `json:"title,omitempty"`
+	Status          string     `json:"status,omitempty"`
+	Own             bool       `json:"own,omitempty"` // milik mahasiswa yang sama
+	Reasons         []string   `json:"reasons"`
+	TitleSimilarity float64    `json:"title_similarity"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ImpersonationActor - klaim "act" (RFC 8693): admin yang sebenarnya memakai token
+type ImpersonationActor struct {
+	Subject         string `json:"sub"`
+	Email           string `json:"email,omitempty"`
+	ImpersonationID string `json:"imp_id"`
+}
+
+type ImpersonationSession struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	AdminID      uuid.UUID  `json:"adminId" db:"admin_id"`
+	TargetUserID uuid.UUID  `json:"targetUserId" db:"target_user_id"`
+	Reason       string     `json:"reason" db:"reason"`
+	TokenJTI     string     `json:"-" db:"token_jti"`
+	IPAddress    string     `json:"ipAddress" db:"ip_address"`
+	UserAgent    string     `json:"userAgent" db:"user_agent"`
+	StartedAt    time.Time  `json:"startedAt" db:"started_at"`
+	ExpiresAt    time.Time  `json:"expiresAt" db:"expires_at"`
+	EndedAt      *time.Time `json:"endedAt" db:"ended_at"`
+	RequestCount int        `json:"requestCount"`
+}
+
+type ImpersonationAuditLog struct {
+	ID           uuid.UUID `json:"id" db:"id"`
+	SessionID    uuid.UUID `json:"sessionId" db:"session_id"`
+	AdminID      uuid.UUID `json:"adminId" db:"admin_id"`
+	TargetUserID uuid.UUID `json:"targetUserId" db:"target_user_id"`
+	Method       string    `json:"method" db:"method"`
+	Path         string    `json:"path" db:"path"`
+	StatusCode   int       `json:"statusCode" db:"status_code"`
+	Blocked      bool      `json:"blocked" db:"blocked"`
+	IPAddress    string    `json:"ipAddress" db:"ip_address"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+type StartImpersonationRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Alasan gagal login yang dicatat di login_attempts
+const (
+	LoginFailureUnknownUser      = "unknown_user"
+	LoginFailureInvalidPassword  = "invalid_password"
+	LoginFailureInvalidTwoFactor = "invalid_two_factor_code"
+	LoginFailureAccountInactive  = "account_inactive"
+	LoginFailureAccountLocked    = "account_locked"
+	LoginFailureIPRateLimited    = "ip_rate_limited"
+)
+
+type LoginAttempt struct {
+	ID            uuid.UUID  `json:"id" db:"id"`
+	UserID        *uuid.UUID `json:"userId" db:"user_id"`
+	Identifier    string     `json:"identifier" db:"identifier"`
+	IPAddress     string     `json:"ipAddress" db:"ip_address"`
+	UserAgent     string     `json:"userAgent" db:"user_agent"`
+	Success       bool       `json:"success" db:"success"`
+	FailureReason *string    `json:"failureReason" db:"failure_reason"`
+	LockoutReason *string    `json:"lockoutReason" db:"lockout_reason"`
+	CreatedAt     time.Time  `json:"createdAt" db:"created_at"`
+}
+
+type AccountLockout struct {
+	UserID         uuid.UUID  `json:"userId" db:"user_id"`
+	FailedAttempts int        `json:"failedAttempts" db:"failed_attempts"`
+	LockoutCount   int        `json:"lockoutCount" db:"lockout_count"`
+	LockedUntil    *time.Time `json:"lockedUntil" db:"locked_until"`
+	LockReason     *string    `json:"lockReason" db:"lock_reason"`
+	LastFailedAt   *time.Time `json:"lastFailedAt" db:"last_failed_at"`
+	UpdatedAt      time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+func (l *AccountLockout) IsLocked(now time.Time) bool {
+	return l != nil && l.LockedUntil != nil && l.LockedUntil.After(now)
+}
+
+type LockedAccount struct {
+	AccountLockout
+	Username string `json:"username" db:"username"`
+	Email    string `json:"email" db:"email"`
+	FullName string `json:"fullName" db:"full_name"`
+}
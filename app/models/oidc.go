@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OIDCLoginState - data yang disimpan antara redirect ke IdP dan callback
+type OIDCLoginState struct {
+	StateHash    string    `json:"-" db:"state_hash"`
+	CodeVerifier string    `json:"-" db:"code_verifier"`
+	Nonce        string    `json:"-" db:"nonce"`
+	ExpiresAt    time.Time `json:"expiresAt" db:"expires_at"`
+	CreatedAt    time.Time `json:"createdAt" db:"created_at"`
+}
+
+// UserIdentity - akun eksternal (issuer + subject) yang terhubung ke user lokal
+type UserIdentity struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	Issuer      string     `json:"issuer" db:"issuer"`
+	Subject     string     `json:"subject" db:"subject"`
+	Email       string     `json:"email" db:"email"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+	LastLoginAt *time.Time `json:"lastLoginAt" db:"last_login_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PasswordResetToken struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	UserID      uuid.UUID  `json:"userId" db:"user_id"`
+	TokenHash   string     `json:"-" db:"token_hash"`
+	RequestedIP string     `json:"requestedIp" db:"requested_ip"`
+	ExpiresAt   time.Time  `json:"expiresAt" db:"expires_at"`
+	UsedAt      *time.Time `json:"usedAt" db:"used_at"`
+	CreatedAt   time.Time  `json:"createdAt" db:"created_at"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" binding:"required"`
+	NewPassword     string `json:"newPassword" binding:"required,min=6"`
+	ConfirmPassword string `json:"confirmPassword" binding:"required"`
+}
@@ -10,4 +10,11 @@ type Permission struct {
 	Resource    string    `json:"resource" db:"resource"`
 	Action      string    `json:"action" db:"action"`
 	Description string    `json:"description" db:"description"`
-}
\ No newline at end of file
+}
+// Permission yang wajib selalu dimiliki role Admin agar admin tidak terkunci dari sistem
+const PermissionUserManage = "user:manage"
+
+type CreatePermissionRequest struct {
+	Name        string `json:"name" binding:"required"` // format resource:action
+	Description string `json:"description"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PointsCondition - syarat pada satu field details (nama JSON, custom field dengan
+// prefix "custom_fields."). Tanpa In/Min/Max cukup field-nya terisi.
+type PointsCondition struct {
+	Field string   `json:"field"`
+	In    []string `json:"in,omitempty"` // tidak case-sensitive
+	Min   *float64 `json:"min,omitempty"`
+	Max   *float64 `json:"max,omitempty"`
+}
+
+// PointsRule - poin ditambahkan jika semua kondisi terpenuhi. Dalam satu Group hanya
+// rule pertama yang cocok yang dipakai (mis. tingkat lomba, juara).
+type PointsRule struct {
+	Name            string            `json:"name"`
+	AchievementType string            `json:"achievement_type,omitempty"` // kosong = semua tipe
+	Group           string            `json:"group,omitempty"`
+	When            []PointsCondition `json:"when,omitempty"`
+	Points          int               `json:"points"`
+}
+
+type PointsRuleSet struct {
+	Version       int          `json:"version"`
+	Rules         []PointsRule `json:"rules"`
+	Note          string       `json:"note"`
+	CreatedBy     *uuid.UUID   `json:"created_by,omitempty"`
+	CreatedByName string       `json:"created_by_name,omitempty"`
+	CreatedAt     *time.Time   `json:"created_at,omitempty"`
+}
+
+type PointsBreakdown struct {
+	Rule   string `json:"rule"`
+	Points int    `json:"points"`
+}
+
+// PointsResult - hasil perhitungan beserta versi aturan dan rule yang dipakai
+type PointsResult struct {
+	Points      int               `json:"points"`
+	RuleVersion int               `json:"rule_version"`
+	Breakdown   []PointsBreakdown `json:"breakdown"`
+}
+
+// UpdatePointsRulesRequest - PUT /points-rules, disimpan sebagai versi baru
+type UpdatePointsRulesRequest struct {
+	Rules []PointsRule `json:"rules"`
+	Note  string       `json:"note"`
+}
+
+type PointsChange struct {
+	AchievementID  uuid.UUID `json:"achievement_id"`
+	OldPoints      int       `json:"old_points"`
+	NewPoints      int       `json:"new_points"`
+	OldRuleVersion *int      `json:"old_rule_version"`
+}
+
+// PointsRecalculation - ringkasan hitung ulang poin prestasi verified
+type PointsRecalculation struct {
+	RuleVersion int            `json:"rule_version"`
+	DryRun      bool           `json:"dry_run"`
+	Checked     int            `json:"checked"`
+	Updated     int            `json:"updated"`
+	Failed      int            `json:"failed"`
+	Changes     []PointsChange `json:"changes"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// RefreshTokenFamily - satu rantai refresh token hasil rotasi dari satu kali login
+type RefreshTokenFamily struct {
+	ID           uuid.UUID  `json:"id" db:"id"`
+	UserID       uuid.UUID  `json:"userId" db:"user_id"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	RevokedAt    *time.Time `json:"revokedAt" db:"revoked_at"`
+	RevokeReason *string    `json:"revokeReason" db:"revoke_reason"`
+}
+
+type RefreshToken struct {
+	JTI       string     `json:"jti" db:"jti"`
+	FamilyID  uuid.UUID  `json:"familyId" db:"family_id"`
+	UserID    uuid.UUID  `json:"userId" db:"user_id"`
+	ParentJTI *string    `json:"parentJti" db:"parent_jti"`
+	IssuedAt  time.Time  `json:"issuedAt" db:"issued_at"`
+	ExpiresAt time.Time  `json:"expiresAt" db:"expires_at"`
+	RotatedAt *time.Time `json:"rotatedAt" db:"rotated_at"`
+}
+
+type RefreshClaims struct {
+	FamilyID string `json:"fid"`
+	jwt.RegisteredClaims
+}
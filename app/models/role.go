@@ -10,10 +10,39 @@ type Role struct {
 	ID          uuid.UUID `json:"id" db:"id"`
 	Name        string    `json:"name" db:"name"` 
 	Description string    `json:"description" db:"description"`
+	// Semua user dengan role ini wajib mengaktifkan 2FA sebelum bisa login
+	RequireTwoFactor bool      `json:"requireTwoFactor" db:"require_two_factor"`
 	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
 }
 
 type RolePermission struct {
 	RoleID       uuid.UUID `json:"roleId" db:"role_id"`           
 	PermissionID uuid.UUID `json:"permissionId" db:"permission_id"` 
-}
\ No newline at end of file
+}
+// Role bawaan dipakai langsung oleh kode (switch role.Name), jadi tidak boleh di-rename / dihapus
+var BuiltInRoles = []string{"Admin", "Mahasiswa", "Dosen Wali"}
+
+func IsBuiltInRole(name string) bool {
+	for _, r := range BuiltInRoles {
+		if r == name {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateRoleRequest struct {
+	Name             string `json:"name" binding:"required,min=3,max=50"`
+	Description      string `json:"description"`
+	RequireTwoFactor bool   `json:"requireTwoFactor"`
+}
+
+type UpdateRoleRequest struct {
+	Name             *string `json:"name,omitempty" binding:"omitempty,min=3,max=50"`
+	Description      *string `json:"description,omitempty"`
+	RequireTwoFactor *bool   `json:"requireTwoFactor,omitempty"`
+}
+
+type RolePermissionRequest struct {
+	PermissionID string `json:"permissionId" binding:"required,uuid"`
+}
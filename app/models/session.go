@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserSession - satu sesi login = satu refresh token family
+type UserSession struct {
+	ID              uuid.UUID `json:"id" db:"id"`
+	UserID          uuid.UUID `json:"userId" db:"user_id"`
+	FamilyID        uuid.UUID `json:"-" db:"family_id"`
+	UserAgent       string    `json:"userAgent" db:"user_agent"`
+	IPAddress       string    `json:"ipAddress" db:"ip_address"`
+	AccessJTI       string    `json:"-" db:"access_jti"`
+	AccessExpiresAt time.Time `json:"-" db:"access_expires_at"`
+	CreatedAt       time.Time `json:"createdAt" db:"created_at"`
+	LastSeenAt      time.Time `json:"lastSeenAt" db:"last_seen_at"`
+	Current         bool      `json:"current"`
+}
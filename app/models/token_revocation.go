@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TokenTypeAccess  = "access"
+	TokenTypeRefresh = "refresh"
+	// TokenTypeSession - seluruh access token sebuah sesi (klaim sid), bukan satu jti
+	TokenTypeSession = "session"
+)
+
+// SessionRevocationKey - key di revoked_tokens untuk me-revoke semua token satu sesi
+func SessionRevocationKey(sessionID string) string {
+	return "session:" + sessionID
+}
+
+type RevokedToken struct {
+	JTI       string    `json:"jti" db:"jti"`
+	UserID    uuid.UUID `json:"userId" db:"user_id"`
+	TokenType string    `json:"tokenType" db:"token_type"`
+	Reason    string    `json:"reason" db:"reason"`
+	ExpiresAt time.Time `json:"expiresAt" db:"expires_at"`
+	RevokedAt time.Time `json:"revokedAt" db:"revoked_at"`
+}
+
+type UserTokenRevocation struct {
+	UserID        uuid.UUID  `json:"userId" db:"user_id"`
+	RevokedBefore time.Time  `json:"revokedBefore" db:"revoked_before"`
+	RevokedBy     *uuid.UUID `json:"revokedBy" db:"revoked_by"`
+	Reason        string     `json:"reason" db:"reason"`
+}
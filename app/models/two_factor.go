@@ -0,0 +1,52 @@
+package models
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// Tujuan challenge token yang dikembalikan Login
+const (
+	TwoFactorPurposeVerify = "2fa_verify" // user sudah punya 2FA, kirim kode ke /auth/2fa/login
+	TwoFactorPurposeSetup  = "2fa_setup"  // role mewajibkan 2FA tapi user belum enroll
+)
+
+type UserTwoFactor struct {
+	UserID       uuid.UUID  `json:"userId" db:"user_id"`
+	Secret       string     `json:"-" db:"secret"`
+	Enabled      bool       `json:"enabled" db:"enabled"`
+	EnabledAt    *time.Time `json:"enabledAt" db:"enabled_at"`
+	LastUsedStep int64      `json:"-" db:"last_used_step"`
+	CreatedAt    time.Time  `json:"createdAt" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updatedAt" db:"updated_at"`
+}
+
+type TwoFactorChallengeClaims struct {
+	Purpose string `json:"purpose"`
+	jwt.RegisteredClaims
+}
+
+type TwoFactorEnrollResponse struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauthUri"`
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type TwoFactorChallengeResponse struct {
+	TwoFactorRequired      bool   `json:"twoFactorRequired"`
+	TwoFactorSetupRequired bool   `json:"twoFactorSetupRequired"`
+	ChallengeToken         string `json:"challengeToken"`
+	ExpiresIn              int    `json:"expiresIn"`
+}
+
+type TwoFactorCodeRequest struct {
+	ChallengeToken string `json:"challengeToken,omitempty"`
+	Code           string `json:"code" binding:"required"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
@@ -58,9 +58,11 @@ type LoginResponse struct {
 }
 
 type JWTClaims struct {
-	UserID      string   `json:"user_id"`
-	Email       string   `json:"email"`
-	RoleID      string   `json:"role_id"`
-	Permissions []string `json:"permissions"`
+	UserID    string `json:"user_id"`
+	Email     string `json:"email"`
+	RoleID    string `json:"role_id"`
+	SessionID string `json:"sid,omitempty"`
+	// Hanya ada di token impersonation: admin yang bertindak atas nama user ini
+	Act *ImpersonationActor `json:"act,omitempty"`
 	jwt.RegisteredClaims
 }
\ No newline at end of file
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// ScopeTypeProgramStudy - cocok dengan students.program_study
+	ScopeTypeProgramStudy = "program_study"
+	// ScopeTypeDepartment - cocok dengan lecturers.department milik dosen wali mahasiswa
+	ScopeTypeDepartment = "department"
+)
+
+var ValidScopeTypes = []string{ScopeTypeProgramStudy, ScopeTypeDepartment}
+
+type UserScope struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	UserID     uuid.UUID  `json:"userId" db:"user_id"`
+	ScopeType  string     `json:"scopeType" db:"scope_type"`
+	ScopeValue string     `json:"scopeValue" db:"scope_value"`
+	CreatedBy  *uuid.UUID `json:"createdBy" db:"created_by"`
+	CreatedAt  time.Time  `json:"createdAt" db:"created_at"`
+}
+
+type CreateUserScopeRequest struct {
+	ScopeType  string `json:"scopeType" binding:"required"`
+	ScopeValue string `json:"scopeValue" binding:"required"`
+}
@@ -0,0 +1,202 @@
+package points
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"UAS/app/models"
+)
+
+func float(v float64) *float64 { return &v }
+
+// DefaultRules - aturan bawaan (versi 0) selama admin belum menyimpan aturan sendiri
+func DefaultRules() []models.PointsRule {
+	return []models.PointsRule{
+		// Kompetisi: tingkat + peringkat / medali
+		{Name: "Competition - international", AchievementType: "competition", Group: "competition_level", Points: 50,
+			When: []models.PointsCondition{{Field: "competition_level", In: []string{"international"}}}},
+		{Name: "Competition - national", AchievementType: "competition", Group: "competition_level", Points: 30,
+			When: []models.PointsCondition{{Field: "competition_level", In: []string{"national"}}}},
+		{Name: "Competition - regional", AchievementType: "competition", Group: "competition_level", Points: 20,
+			When: []models.PointsCondition{{Field: "competition_level", In: []string{"regional", "provincial"}}}},
+		{Name: "Competition - local", AchievementType: "competition", Group: "competition_level", Points: 10},
+		{Name: "Winner (1st place / gold)", AchievementType: "competition", Group: "placement", Points: 20,
+			When: []models.PointsCondition{{Field: "rank", Max: float(1)}}},
+		{Name: "Winner (1st place / gold)", AchievementType: "competition", Group: "placement", Points: 20,
+			When: []models.PointsCondition{{Field: "medal_type", In: []string{"gold"}}}},
+		{Name: "Runner-up (2nd place / silver)", AchievementType: "competition", Group: "placement", Points: 15,
+			When: []models.PointsCondition{{Field: "rank", Max: float(2)}}},
+		{Name: "Runner-up (2nd place / silver)", AchievementType: "competition", Group: "placement", Points: 15,
+			When: []models.PointsCondition{{Field: "medal_type", In: []string{"silver"}}}},
+		{Name: "Third place / bronze", AchievementType: "competition", Group: "placement", Points: 10,
+			When: []models.PointsCondition{{Field: "rank", Max: float(3)}}},
+		{Name: "Third place / bronze", AchievementType: "competition", Group: "placement", Points: 10,
+			When: []models.PointsCondition{{Field: "medal_type", In: []string{"bronze"}}}},
+
+		// Publikasi: jenis + indeksasi (custom field "indexing")
+		{Name: "Journal article", AchievementType: "publication", Group: "publication_type", Points: 30,
+			When: []models.PointsCondition{{Field: "publication_type", In: []string{"journal"}}}},
+		{Name: "Conference paper", AchievementType: "publication", Group: "publication_type", Points: 20,
+			When: []models.PointsCondition{{Field: "publication_type", In: []string{"conference"}}}},
+		{Name: "Other publication", AchievementType: "publication", Group: "publication_type", Points: 15},
+		{Name: "Indexed in Scopus / WoS", AchievementType: "publication", Group: "indexing", Points: 20,
+			When: []models.PointsCondition{{Field: "custom_fields.indexing", In: []string{"scopus", "wos"}}}},
+		{Name: "Indexed in SINTA 1-2", AchievementType: "publication", Group: "indexing", Points: 10,
+			When: []models.PointsCondition{{Field: "custom_fields.indexing", In: []string{"sinta1", "sinta2"}}}},
+
+		// Organisasi
+		{Name: "Organization chair", AchievementType: "organization", Group: "position", Points: 20,
+			When: []models.PointsCondition{{Field: "position", In: []string{"ketua", "chair", "chairman", "president"}}}},
+		{Name: "Organization member", AchievementType: "organization", Group: "position", Points: 10},
+
+		{Name: "Certification", AchievementType: "certification", Points: 15},
+		{Name: "Academic achievement", AchievementType: "academic", Points: 10},
+		{Name: "Other achievement", AchievementType: "other", Points: 5},
+	}
+}
+
+// Validate - cek aturan dari admin sebelum disimpan sebagai versi baru
+func Validate(rules []models.PointsRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("at least one rule is required")
+	}
+
+	detailFields := models.AchievementDetailFields()
+	for i, rule := range rules {
+		if strings.TrimSpace(rule.Name) == "" {
+			return fmt.Errorf("rule %d has no name", i+1)
+		}
+		if rule.Points < 0 {
+			return fmt.Errorf("rule %q has negative points", rule.Name)
+		}
+		for _, cond := range rule.When {
+			root := strings.SplitN(cond.Field, ".", 2)
+			if !isDetailField(detailFields, root[0]) || (root[0] == "custom_fields" && len(root) == 1) {
+				return fmt.Errorf("rule %q uses unknown field %q", rule.Name, cond.Field)
+			}
+			if cond.Min != nil && cond.Max != nil && *cond.Min > *cond.Max {
+				return fmt.Errorf("rule %q has min greater than max for %q", rule.Name, cond.Field)
+			}
+		}
+	}
+	return nil
+}
+
+// Calculate - jumlah poin semua rule yang cocok dengan achievement
+func Calculate(set *models.PointsRuleSet, achievement *models.Achievement) models.PointsResult {
+	result := models.PointsResult{RuleVersion: set.Version, Breakdown: []models.PointsBreakdown{}}
+	details := detailValues(achievement.Details)
+	usedGroups := map[string]bool{}
+
+	for _, rule := range set.Rules {
+		if rule.AchievementType != "" && rule.AchievementType != achievement.AchievementType {
+			continue
+		}
+		if rule.Group != "" && usedGroups[rule.Group] {
+			continue
+		}
+		if !matches(rule.When, details) {
+			continue
+		}
+
+		if rule.Group != "" {
+			usedGroups[rule.Group] = true
+		}
+		result.Points += rule.Points
+		result.Breakdown = append(result.Breakdown, models.PointsBreakdown{Rule: rule.Name, Points: rule.Points})
+	}
+	return result
+}
+
+// detailValues - details sebagai map dengan nama JSON, supaya kondisi bisa menunjuk field apa pun
+func detailValues(details models.AchievementDetails) map[string]interface{} {
+	values := map[string]interface{}{}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return values
+	}
+	_ = json.Unmarshal(raw, &values)
+	return values
+}
+
+func lookup(values map[string]interface{}, path string) (interface{}, bool) {
+	var current interface{} = values
+	for _, part := range strings.Split(path, ".") {
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = m[part]; !ok {
+			return nil, false
+		}
+	}
+	return current, current != nil
+}
+
+func matches(conditions []models.PointsCondition, values map[string]interface{}) bool {
+	for _, cond := range conditions {
+		value, ok := lookup(values, cond.Field)
+		if !ok {
+			return false
+		}
+
+		if len(cond.In) > 0 && !containsFold(cond.In, stringValue(value)) {
+			return false
+		}
+		if cond.Min != nil || cond.Max != nil {
+			number, ok := numberValue(value)
+			if !ok {
+				return false
+			}
+			if cond.Min != nil && number < *cond.Min {
+				return false
+			}
+			if cond.Max != nil && number > *cond.Max {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func stringValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func numberValue(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	}
+	return 0, false
+}
+
+func isDetailField(fields []string, name string) bool {
+	for _, field := range fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(slice []string, item string) bool {
+	for _, s := range slice {
+		if strings.EqualFold(strings.TrimSpace(s), strings.TrimSpace(item)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,120 @@
+package points
+
+import (
+	"reflect"
+	"testing"
+
+	"UAS/app/models"
+)
+
+func TestCalculateDefaultRules(t *testing.T) {
+	set := &models.PointsRuleSet{Version: 0, Rules: DefaultRules()}
+
+	tests := []struct {
+		name            string
+		achievementType string
+		details         models.AchievementDetails
+		wantPoints      int
+		wantRules       []string
+	}{
+		{"international winner by rank", "competition", models.AchievementDetails{CompetitionLevel: "international", Rank: 1},
+			70, []string{"Competition - international", "Winner (1st place / gold)"}},
+		{"national silver medal", "competition", models.AchievementDetails{CompetitionLevel: "National", MedalType: "silver"},
+			45, []string{"Competition - national", "Runner-up (2nd place / silver)"}},
+		{"provincial third place", "competition", models.AchievementDetails{CompetitionLevel: "provincial", Rank: 3},
+			30, []string{"Competition - regional", "Third place / bronze"}},
+		{"local without placement", "competition", models.AchievementDetails{CompetitionLevel: "campus", Rank: 8},
+			10, []string{"Competition - local"}},
+		{"journal indexed in scopus", "publication", models.AchievementDetails{PublicationType: "journal", CustomFields: map[string]interface{}{"indexing": "scopus"}},
+			50, []string{"Journal article", "Indexed in Scopus / WoS"}},
+		{"conference indexed in sinta2", "publication", models.AchievementDetails{PublicationType: "conference", CustomFields: map[string]interface{}{"indexing": "sinta2"}},
+			30, []string{"Conference paper", "Indexed in SINTA 1-2"}},
+		{"book without indexing", "publication", models.AchievementDetails{PublicationType: "book"},
+			15, []string{"Other publication"}},
+		{"organization chair", "organization", models.AchievementDetails{Position: "Ketua"},
+			20, []string{"Organization chair"}},
+		{"organization staff", "organization", models.AchievementDetails{Position: "Staff"},
+			10, []string{"Organization member"}},
+		{"certification", "certification", models.AchievementDetails{}, 15, []string{"Certification"}},
+		{"academic", "academic", models.AchievementDetails{}, 10, []string{"Academic achievement"}},
+		{"other", "other", models.AchievementDetails{}, 5, []string{"Other achievement"}},
+		{"custom type without rules", "community_service", models.AchievementDetails{}, 0, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Calculate(set, &models.Achievement{AchievementType: tt.achievementType, Details: tt.details})
+			if result.Points != tt.wantPoints {
+				t.Fatalf("points = %d, want %d (%+v)", result.Points, tt.wantPoints, result.Breakdown)
+			}
+			var rules []string
+			for _, b := range result.Breakdown {
+				rules = append(rules, b.Rule)
+			}
+			if !reflect.DeepEqual(rules, tt.wantRules) {
+				t.Fatalf("rules = %v, want %v", rules, tt.wantRules)
+			}
+		})
+	}
+}
+
+func TestCalculateConditions(t *testing.T) {
+	set := &models.PointsRuleSet{Version: 3, Rules: []models.PointsRule{
+		{Name: "high score", Points: 10, When: []models.PointsCondition{{Field: "score", Min: float(90)}}},
+		{Name: "mid score", Points: 5, When: []models.PointsCondition{{Field: "score", Min: float(70), Max: float(89)}}},
+		{Name: "team size", Points: 3, When: []models.PointsCondition{{Field: "custom_fields.team_size", Max: float(3)}}},
+		{Name: "level as text", Points: 2, When: []models.PointsCondition{{Field: "custom_fields.level", In: []string{"1"}}}},
+		{Name: "unset field", Points: 100, When: []models.PointsCondition{{Field: "publisher", In: []string{""}}}},
+	}}
+
+	tests := []struct {
+		name       string
+		details    models.AchievementDetails
+		wantPoints int
+	}{
+		{"high score", models.AchievementDetails{Score: 95}, 10},
+		{"mid score", models.AchievementDetails{Score: 75}, 5},
+		{"low score", models.AchievementDetails{Score: 10}, 0},
+		{"numeric custom field", models.AchievementDetails{CustomFields: map[string]interface{}{"team_size": int32(2)}}, 3},
+		{"numeric text custom field", models.AchievementDetails{CustomFields: map[string]interface{}{"team_size": "2"}}, 3},
+		{"non-numeric custom field", models.AchievementDetails{CustomFields: map[string]interface{}{"team_size": "two"}}, 0},
+		{"number matched against In", models.AchievementDetails{CustomFields: map[string]interface{}{"level": 1}}, 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := Calculate(set, &models.Achievement{AchievementType: "other", Details: tt.details})
+			if result.Points != tt.wantPoints {
+				t.Fatalf("points = %d, want %d (%+v)", result.Points, tt.wantPoints, result.Breakdown)
+			}
+			if result.RuleVersion != 3 {
+				t.Fatalf("rule version = %d, want 3", result.RuleVersion)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rules   []models.PointsRule
+		wantErr bool
+	}{
+		{"default rules", DefaultRules(), false},
+		{"no rules", nil, true},
+		{"no name", []models.PointsRule{{Points: 1}}, true},
+		{"negative points", []models.PointsRule{{Name: "x", Points: -1}}, true},
+		{"unknown field", []models.PointsRule{{Name: "x", When: []models.PointsCondition{{Field: "nickname"}}}}, true},
+		{"bare custom_fields", []models.PointsRule{{Name: "x", When: []models.PointsCondition{{Field: "custom_fields"}}}}, true},
+		{"custom field path", []models.PointsRule{{Name: "x", When: []models.PointsCondition{{Field: "custom_fields.indexing"}}}}, false},
+		{"min greater than max", []models.PointsRule{{Name: "x", When: []models.PointsCondition{{Field: "rank", Min: float(3), Max: float(1)}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.rules); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AchievementCommentRepository interface {
+	Create(comment *models.AchievementComment) error
+	GetByID(id uuid.UUID) (*models.AchievementComment, error)
+	GetByAchievement(achievementRefID uuid.UUID) ([]models.AchievementComment, error)
+	Update(comment *models.AchievementComment) error
+	SoftDelete(id uuid.UUID, deletedBy uuid.UUID) error
+}
+
+type achievementCommentRepo struct {
+	DB *sql.DB
+}
+
+func NewAchievementCommentRepository(db *sql.DB) AchievementCommentRepository {
+	return &achievementCommentRepo{DB: db}
+}
+
+const achievementCommentColumns = `c.id, c.achievement_ref_id, c.author_id, COALESCE(u.full_name, ''), COALESCE(r.name, ''),
+	c.body, c.change_requested, COALESCE(c.fields, '{}'), c.created_at, c.updated_at, c.deleted_at`
+
+const achievementCommentFrom = `
+	FROM achievement_comments c
+	LEFT JOIN users u ON u.id = c.author_id
+	LEFT JOIN roles r ON r.id = u.role_id`
+
+func scanAchievementComment(row interface{ Scan(...interface{}) error }, comment *models.AchievementComment) error {
+	var deletedAt sql.NullTime
+	err := row.Scan(
+		&comment.ID, &comment.AchievementRefID, &comment.AuthorID, &comment.AuthorName, &comment.AuthorRole,
+		&comment.Body, &comment.ChangeRequested, pq.Array(&comment.Fields), &comment.CreatedAt, &comment.UpdatedAt, &deletedAt,
+	)
+	if err != nil {
+		return err
+	}
+	if deletedAt.Valid {
+		comment.DeletedAt = &deletedAt.Time
+	}
+	comment.Edited = comment.UpdatedAt.After(comment.CreatedAt)
+	return nil
+}
+
+func (r *achievementCommentRepo) Create(comment *models.AchievementComment) error {
+	comment.ID = uuid.New()
+	comment.CreatedAt = time.Now()
+	comment.UpdatedAt = comment.CreatedAt
+	if comment.Fields == nil {
+		comment.Fields = []string{}
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO achievement_comments (id, achievement_ref_id, author_id, body, change_requested, fields, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, comment.ID, comment.AchievementRefID, comment.AuthorID, comment.Body, comment.ChangeRequested,
+		pq.Array(comment.Fields), comment.CreatedAt, comment.UpdatedAt)
+	return err
+}
+
+// GetByID - komentar yang sudah dihapus dianggap tidak ada
+func (r *achievementCommentRepo) GetByID(id uuid.UUID) (*models.AchievementComment, error) {
+	var comment models.AchievementComment
+	err := scanAchievementComment(r.DB.QueryRow(`
+		SELECT `+achievementCommentColumns+achievementCommentFrom+`
+		WHERE c.id = $1 AND c.deleted_at IS NULL
+	`, id), &comment)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &comment, nil
+}
+
+func (r *achievementCommentRepo) GetByAchievement(achievementRefID uuid.UUID) ([]models.AchievementComment, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+achievementCommentColumns+achievementCommentFrom+`
+		WHERE c.achievement_ref_id = $1 AND c.deleted_at IS NULL
+		ORDER BY c.created_at ASC
+	`, achievementRefID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.AchievementComment
+	for rows.Next() {
+		var comment models.AchievementComment
+		if err := scanAchievementComment(rows, &comment); err != nil {
+			return nil, err
+		}
+		comments = append(comments, comment)
+	}
+	return comments, rows.Err()
+}
+
+func (r *achievementCommentRepo) Update(comment *models.AchievementComment) error {
+	comment.UpdatedAt = time.Now()
+	if comment.Fields == nil {
+		comment.Fields = []string{}
+	}
+
+	_, err := r.DB.Exec(`
+		UPDATE achievement_comments
+		SET body = $1, change_requested = $2, fields = $3, updated_at = $4
+		WHERE id = $5 AND deleted_at IS NULL
+	`, comment.Body, comment.ChangeRequested, pq.Array(comment.Fields), comment.UpdatedAt, comment.ID)
+	return err
+}
+
+// SoftDelete - baris tetap disimpan untuk audit, hanya disembunyikan dari thread
+func (r *achievementCommentRepo) SoftDelete(id uuid.UUID, deletedBy uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE achievement_comments SET deleted_at = NOW(), deleted_by = $1
+		WHERE id = $2 AND deleted_at IS NULL
+	`, deletedBy, id)
+	return err
+}
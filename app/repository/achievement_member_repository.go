@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type AchievementMemberRepository interface {
+	GetByAchievement(achievementRefID uuid.UUID) ([]models.AchievementMember, error)
+	Get(achievementRefID, studentID uuid.UUID) (*models.AchievementMember, error)
+	// GetInvitations - undangan yang belum dijawab oleh mahasiswa ini
+	GetInvitations(studentID uuid.UUID) ([]models.AchievementMember, error)
+	Invite(member *models.AchievementMember) error
+	UpdateRole(achievementRefID, studentID uuid.UUID, role string) error
+	Respond(achievementRefID, studentID uuid.UUID, status string) error
+	Remove(achievementRefID, studentID uuid.UUID) error
+	// SetVerification - verifikasi / tolak keanggotaan satu mahasiswa (bukan pemilik).
+	// Bisa diubah lagi, mis. setelah data anggota diperbaiki.
+	SetVerification(achievementRefID, studentID uuid.UUID, status string, verifiedBy uuid.UUID, note string) error
+}
+
+type achievementMemberRepo struct {
+	DB *sql.DB
+}
+
+func NewAchievementMemberRepository(db *sql.DB) AchievementMemberRepository {
+	return &achievementMemberRepo{DB: db}
+}
+
+const achievementMemberQuery = `
+	SELECT m.id, m.achievement_ref_id, m.student_id, COALESCE(s.student_id, ''), COALESCE(u.full_name, ''),
+	       s.advisor_id, m.role, m.student_id = ar.student_id, m.status, m.verification_status,
+	       m.verified_by, m.verified_at, m.rejection_note, m.invited_by, m.responded_at, m.created_at
+	FROM achievement_members m
+	JOIN achievement_references ar ON ar.id = m.achievement_ref_id
+	LEFT JOIN students s ON s.id = m.student_id
+	LEFT JOIN users u ON u.id = s.user_id`
+
+func scanAchievementMember(row interface{ Scan(...interface{}) error }, m *models.AchievementMember) error {
+	var advisorID, verifiedBy, invitedBy uuid.NullUUID
+	var verificationStatus, rejectionNote sql.NullString
+	var verifiedAt, respondedAt sql.NullTime
+
+	err := row.Scan(
+		&m.ID, &m.AchievementRefID, &m.StudentID, &m.StudentNumber, &m.StudentName,
+		&advisorID, &m.Role, &m.Owner, &m.Status, &verificationStatus,
+		&verifiedBy, &verifiedAt, &rejectionNote, &invitedBy, &respondedAt, &m.CreatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if advisorID.Valid {
+		m.AdvisorID = &advisorID.UUID
+	}
+	if verificationStatus.Valid {
+		m.VerificationStatus = &verificationStatus.String
+	}
+	if verifiedBy.Valid {
+		m.VerifiedBy = &verifiedBy.UUID
+	}
+	if verifiedAt.Valid {
+		m.VerifiedAt = &verifiedAt.Time
+	}
+	if rejectionNote.Valid {
+		m.RejectionNote = &rejectionNote.String
+	}
+	if invitedBy.Valid {
+		m.InvitedBy = &invitedBy.UUID
+	}
+	if respondedAt.Valid {
+		m.RespondedAt = &respondedAt.Time
+	}
+	return nil
+}
+
+func (r *achievementMemberRepo) query(query string, args ...interface{}) ([]models.AchievementMember, error) {
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []models.AchievementMember
+	for rows.Next() {
+		var m models.AchievementMember
+		if err := scanAchievementMember(rows, &m); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+	return members, rows.Err()
+}
+
+// GetByAchievement - pemilik dulu, lalu leader, lalu urut waktu diundang
+func (r *achievementMemberRepo) GetByAchievement(achievementRefID uuid.UUID) ([]models.AchievementMember, error) {
+	return r.query(achievementMemberQuery+`
+		WHERE m.achievement_ref_id = $1
+		ORDER BY m.student_id = ar.student_id DESC, m.role = 'leader' DESC, m.created_at
+	`, achievementRefID)
+}
+
+func (r *achievementMemberRepo) Get(achievementRefID, studentID uuid.UUID) (*models.AchievementMember, error) {
+	var m models.AchievementMember
+	err := scanAchievementMember(r.DB.QueryRow(achievementMemberQuery+`
+		WHERE m.achievement_ref_id = $1 AND m.student_id = $2
+	`, achievementRefID, studentID), &m)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &m, nil
+}
+
+func (r *achievementMemberRepo) GetInvitations(studentID uuid.UUID) ([]models.AchievementMember, error) {
+	return r.query(achievementMemberQuery+`
+		WHERE m.student_id = $1 AND m.status = $2 AND ar.status != $3
+		ORDER BY m.created_at DESC
+	`, studentID, models.MemberStatusInvited, models.AchievementStatusDeleted)
+}
+
+func (r *achievementMemberRepo) Invite(member *models.AchievementMember) error {
+	member.ID = uuid.New()
+	member.CreatedAt = time.Now()
+	member.Status = models.MemberStatusInvited
+	pending := models.MemberVerificationPending
+	member.VerificationStatus = &pending
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if member.Role == models.MemberRoleLeader {
+		if err := demoteLeader(tx, member.AchievementRefID); err != nil {
+			return err
+		}
+	}
+
+	// Mahasiswa yang pernah menolak boleh diundang lagi; anggota aktif tidak diubah
+	result, err := tx.Exec(`
+		INSERT INTO achievement_members (id, achievement_ref_id, student_id, role, status, verification_status, invited_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (achievement_ref_id, student_id) DO UPDATE
+		SET role = EXCLUDED.role, status = EXCLUDED.status, verification_status = EXCLUDED.verification_status,
+		    invited_by = EXCLUDED.invited_by, created_at = EXCLUDED.created_at,
+		    responded_at = NULL, verified_by = NULL, verified_at = NULL, rejection_note = NULL
+		WHERE achievement_members.status = $9
+	`, member.ID, member.AchievementRefID, member.StudentID, member.Role, member.Status,
+		pending, nullUUIDPtr(member.InvitedBy), member.CreatedAt, models.MemberStatusDeclined)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// UpdateRole - hanya ada satu leader per tim, leader lama menjadi member
+func (r *achievementMemberRepo) UpdateRole(achievementRefID, studentID uuid.UUID, role string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if role == models.MemberRoleLeader {
+		if err := demoteLeader(tx, achievementRefID); err != nil {
+			return err
+		}
+	}
+
+	result, err := tx.Exec(`
+		UPDATE achievement_members SET role = $1 WHERE achievement_ref_id = $2 AND student_id = $3
+	`, role, achievementRefID, studentID)
+	if err != nil {
+		return err
+	}
+	if err := expectOneRow(result); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func demoteLeader(tx *sql.Tx, achievementRefID uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE achievement_members SET role = $1 WHERE achievement_ref_id = $2 AND role = $3
+	`, models.MemberRoleMember, achievementRefID, models.MemberRoleLeader)
+	return err
+}
+
+// Respond - terima / tolak undangan, hanya selama masih berstatus invited
+func (r *achievementMemberRepo) Respond(achievementRefID, studentID uuid.UUID, status string) error {
+	result, err := r.DB.Exec(`
+		UPDATE achievement_members SET status = $1, responded_at = NOW()
+		WHERE achievement_ref_id = $2 AND student_id = $3 AND status = $4
+	`, status, achievementRefID, studentID, models.MemberStatusInvited)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(result)
+}
+
+// Remove - pemilik prestasi tidak bisa dikeluarkan dari timnya sendiri
+func (r *achievementMemberRepo) Remove(achievementRefID, studentID uuid.UUID) error {
+	result, err := r.DB.Exec(`
+		DELETE FROM achievement_members m
+		USING achievement_references ar
+		WHERE ar.id = m.achievement_ref_id AND m.achievement_ref_id = $1 AND m.student_id = $2
+		  AND m.student_id != ar.student_id
+	`, achievementRefID, studentID)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(result)
+}
+
+func (r *achievementMemberRepo) SetVerification(achievementRefID, studentID uuid.UUID, status string, verifiedBy uuid.UUID, note string) error {
+	result, err := r.DB.Exec(`
+		UPDATE achievement_members
+		SET verification_status = $1, verified_by = $2, verified_at = NOW(), rejection_note = $3
+		WHERE achievement_ref_id = $4 AND student_id = $5 AND status = $6 AND verification_status IS NOT NULL
+	`, status, verifiedBy, nullString(note), achievementRefID, studentID, models.MemberStatusAccepted)
+	if err != nil {
+		return err
+	}
+	return expectOneRow(result)
+}
+
+func expectOneRow(result sql.Result) error {
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("achievement member not found or already changed")
+	}
+	return nil
+}
+
+func nullUUIDPtr(id *uuid.UUID) interface{} {
+	if id == nil {
+		return nil
+	}
+	return nullUUID(*id)
+}
@@ -2,31 +2,54 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"time"
 
 	"UAS/app/models"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// ErrStatusChanged - UPDATE transisi tidak mengenai baris apa pun: status / stage / revisi
+// sudah berubah oleh request lain sejak dibaca
+var ErrStatusChanged = errors.New("achievement status changed concurrently")
+
 type AchievementReferenceRepository interface {
 	// CRUD operations
-	CreateReference(ref *models.AchievementReference) error
+	CreateReference(ref *models.AchievementReference, change models.StatusChange) error
 	GetReferenceByID(id uuid.UUID) (*models.AchievementReference, error)
 	GetReferenceByMongoID(mongoID string) (*models.AchievementReference, error)
 	UpdateReference(ref *models.AchievementReference) error
+	// TouchEditable - hanya updated_at, dan hanya selama masih draft / rejected
+	TouchEditable(id uuid.UUID) error
 	DeleteReference(id uuid.UUID) error
-	SoftDelete(id uuid.UUID) error
+	SoftDelete(id uuid.UUID, change models.StatusChange) error
 	
 	// Status management (sesuai SRS)
-	SubmitForVerification(id uuid.UUID) error
-	VerifyAchievement(id uuid.UUID, verifiedBy uuid.UUID) error
-	RejectAchievement(id uuid.UUID, verifiedBy uuid.UUID, rejectionNote string) error
+	// Setiap transisi status ditulis ke achievement_status_events dalam transaksi yang sama
+	SubmitForVerification(id uuid.UUID, firstStage string, change models.StatusChange) error
+	// AdvanceStage - stage saat ini disetujui, lanjut ke stage berikutnya (status tetap submitted)
+	AdvanceStage(id uuid.UUID, currentStage, nextStage string, change models.StatusChange) error
+	// VerifyAchievement - poin final & versi aturannya disimpan bersama status verified.
+	// currentStage (seperti AdvanceStage) jadi guard supaya keputusan stage lain tidak tertimpa.
+	VerifyAchievement(id uuid.UUID, currentStage string, award models.PointsResult, change models.StatusChange) error
+	// RejectAchievement / ResubmitAchievement juga mengembalikan verifikasi anggota tim ke pending
+	RejectAchievement(id uuid.UUID, currentStage string, change models.StatusChange) error
+	// ResubmitAchievement - maxRevisions ikut jadi guard di UPDATE supaya batas tidak terlewati
+	// oleh dua request bersamaan
+	ResubmitAchievement(id uuid.UUID, firstStage string, maxRevisions int, change models.StatusChange) error
+	GetStatusEvents(id uuid.UUID) ([]models.AchievementStatusEvent, error)
+
+	// Poin final, bisa dihitung ulang dengan aturan baru
+	GetVerifiedReferences() ([]models.AchievementReference, error)
+	UpdatePoints(id uuid.UUID, award models.PointsResult) error
 	
 	// Query operations
 	GetReferencesByStudentID(studentID uuid.UUID, status string) ([]models.AchievementReference, error)
 	GetReferencesByAdvisor(advisorID uuid.UUID, status string) ([]models.AchievementReference, error)
+	GetReferencesByStudentIDs(studentIDs []uuid.UUID, status string) ([]models.AchievementReference, error)
 	GetAllReferences(status string, limit, offset int) ([]models.AchievementReference, int, error)
 	CheckOwnership(achievementID, studentID uuid.UUID) (bool, error)
 }
@@ -39,8 +62,10 @@ func NewAchievementReferenceRepository(db *sql.DB) AchievementReferenceRepositor
 	return &achievementReferenceRepo{DB: db}
 }
 
-func (r *achievementReferenceRepo) CreateReference(ref *models.AchievementReference) error {
-	ref.ID = uuid.New()
+func (r *achievementReferenceRepo) CreateReference(ref *models.AchievementReference, change models.StatusChange) error {
+	if ref.ID == uuid.Nil {
+		ref.ID = uuid.New()
+	}
 	ref.CreatedAt = time.Now()
 	ref.UpdatedAt = time.Now()
 	
@@ -57,7 +82,13 @@ func (r *achievementReferenceRepo) CreateReference(ref *models.AchievementRefere
 		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 	`
 	
-	_, err := r.DB.Exec(query,
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(query,
 		ref.ID,
 		ref.StudentID,
 		ref.MongoAchievementID,
@@ -69,8 +100,24 @@ func (r *achievementReferenceRepo) CreateReference(ref *models.AchievementRefere
 		ref.CreatedAt,
 		ref.UpdatedAt,
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := insertStatusEvent(tx, ref.ID, "", ref.Status, change); err != nil {
+		return err
+	}
+
+	// Pemilik otomatis menjadi leader tim
+	_, err = tx.Exec(`
+		INSERT INTO achievement_members (id, achievement_ref_id, student_id, role, status, responded_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $6)
+	`, uuid.New(), ref.ID, ref.StudentID, models.MemberRoleLeader, models.MemberStatusAccepted, ref.CreatedAt)
+	if err != nil {
+		return err
+	}
 	
-	return err
+	return tx.Commit()
 }
 
 func (r *achievementReferenceRepo) GetReferenceByID(id uuid.UUID) (*models.AchievementReference, error) {
@@ -78,12 +125,14 @@ func (r *achievementReferenceRepo) GetReferenceByID(id uuid.UUID) (*models.Achie
 	var submittedAt, verifiedAt sql.NullTime
 	var verifiedBy sql.NullString
 	var rejectionNote sql.NullString
+	var workflowStage sql.NullString
+	var pointsRuleVersion sql.NullInt64
 	
 	// TAMBAH FILTER: status != 'deleted'
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       created_at, updated_at, revision, workflow_stage, COALESCE(points, 0), points_rule_version
 		FROM achievement_references
 		WHERE id = $1 AND status != $2
 	`
@@ -99,6 +148,10 @@ func (r *achievementReferenceRepo) GetReferenceByID(id uuid.UUID) (*models.Achie
 		&rejectionNote,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
+		&ref.Revision,
+		&workflowStage,
+		&ref.Points,
+		&pointsRuleVersion,
 	)
 	
 	if err != nil {
@@ -122,6 +175,13 @@ func (r *achievementReferenceRepo) GetReferenceByID(id uuid.UUID) (*models.Achie
 	if rejectionNote.Valid {
 		ref.RejectionNote = &rejectionNote.String
 	}
+	if workflowStage.Valid {
+		ref.WorkflowStage = &workflowStage.String
+	}
+	if pointsRuleVersion.Valid {
+		version := int(pointsRuleVersion.Int64)
+		ref.PointsRuleVersion = &version
+	}
 	
 	return &ref, nil
 }
@@ -131,12 +191,13 @@ func (r *achievementReferenceRepo) GetReferenceByMongoID(mongoID string) (*model
 	var submittedAt, verifiedAt sql.NullTime
 	var verifiedBy sql.NullString
 	var rejectionNote sql.NullString
+	var workflowStage sql.NullString
 	
 	// TAMBAH FILTER: status != 'deleted'
 	query := `
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       created_at, updated_at, revision, workflow_stage
 		FROM achievement_references
 		WHERE mongo_achievement_id = $1 AND status != $2
 	`
@@ -152,6 +213,8 @@ func (r *achievementReferenceRepo) GetReferenceByMongoID(mongoID string) (*model
 		&rejectionNote,
 		&ref.CreatedAt,
 		&ref.UpdatedAt,
+		&ref.Revision,
+		&workflowStage,
 	)
 	
 	if err != nil {
@@ -174,6 +237,9 @@ func (r *achievementReferenceRepo) GetReferenceByMongoID(mongoID string) (*model
 	if rejectionNote.Valid {
 		ref.RejectionNote = &rejectionNote.String
 	}
+	if workflowStage.Valid {
+		ref.WorkflowStage = &workflowStage.String
+	}
 	
 	return &ref, nil
 }
@@ -201,6 +267,29 @@ func (r *achievementReferenceRepo) UpdateReference(ref *models.AchievementRefere
 	return err
 }
 
+// TouchEditable - menandai isi prestasi diubah tanpa menulis ulang kolom status dari
+// salinan yang sudah dibaca; ErrStatusChanged kalau sudah tidak bisa diedit lagi
+func (r *achievementReferenceRepo) TouchEditable(id uuid.UUID) error {
+	query := `
+		UPDATE achievement_references
+		SET updated_at = NOW()
+		WHERE id = $1 AND status IN ($2, $3)
+	`
+
+	result, err := r.DB.Exec(query, id, models.AchievementStatusDraft, models.AchievementStatusRejected)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w, expected: %s or %s", ErrStatusChanged, models.AchievementStatusDraft, models.AchievementStatusRejected)
+	}
+	return nil
+}
+
 func (r *achievementReferenceRepo) DeleteReference(id uuid.UUID) error {
 	query := `DELETE FROM achievement_references WHERE id = $1`
 	
@@ -222,7 +311,7 @@ func (r *achievementReferenceRepo) DeleteReference(id uuid.UUID) error {
 }
 
 // achievement_reference_repository.go - Tambahkan method
-func (r *achievementReferenceRepo) SoftDelete(id uuid.UUID) error {
+func (r *achievementReferenceRepo) SoftDelete(id uuid.UUID, change models.StatusChange) error {
 	// Cek apakah achievement ada
 	ref, err := r.GetReferenceByID(id)
 	if err != nil {
@@ -235,27 +324,19 @@ func (r *achievementReferenceRepo) SoftDelete(id uuid.UUID) error {
 		UPDATE achievement_references 
 		SET status = $1, 
 			updated_at = NOW()
-		WHERE id = $2
+		WHERE id = $2 AND status = $3
 	`
 	
-	result, err := r.DB.Exec(query, models.AchievementStatusDeleted, id)
+	err = r.transition(id, ref.Status, models.AchievementStatusDeleted, change, query,
+		models.AchievementStatusDeleted, id, ref.Status)
 	if err != nil {
 		return fmt.Errorf("failed to soft delete achievement: %w", err)
 	}
 	
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return err
-	}
-	
-	if rowsAffected == 0 {
-		return errors.New("achievement reference not found")
-	}
-	
 	return nil
 }
 
-func (r *achievementReferenceRepo) SubmitForVerification(id uuid.UUID) error {
+func (r *achievementReferenceRepo) SubmitForVerification(id uuid.UUID, firstStage string, change models.StatusChange) error {
 	// Cek status saat ini
 	ref, err := r.GetReferenceByID(id)
 	if err != nil {
@@ -273,22 +354,40 @@ func (r *achievementReferenceRepo) SubmitForVerification(id uuid.UUID) error {
 	now := time.Now()
 	query := `
 		UPDATE achievement_references 
-		SET status = $1, submitted_at = $2, updated_at = $3
-		WHERE id = $4
+		SET status = $1, submitted_at = $2, updated_at = $3, workflow_stage = $4
+		WHERE id = $5 AND status = $6
 	`
 	
-	_, err = r.DB.Exec(query, 
+	return r.transition(id, ref.Status, models.AchievementStatusSubmitted, change, query,
 		models.AchievementStatusSubmitted,
 		now,
 		now,
+		nullString(firstStage),
 		id,
+		ref.Status,
+	)
+}
+
+// AdvanceStage - currentStage adalah nilai workflow_stage yang tersimpan ("" untuk NULL),
+// dipakai sebagai guard supaya dua reviewer tidak menyetujui stage yang sama dua kali
+func (r *achievementReferenceRepo) AdvanceStage(id uuid.UUID, currentStage, nextStage string, change models.StatusChange) error {
+	query := `
+		UPDATE achievement_references 
+		SET workflow_stage = $1, updated_at = $2
+		WHERE id = $3 AND status = $4 AND COALESCE(workflow_stage, '') = $5
+	`
+
+	return r.transition(id, models.AchievementStatusSubmitted, models.AchievementStatusSubmitted, change, query,
+		nextStage,
+		time.Now(),
+		id,
+		models.AchievementStatusSubmitted,
+		currentStage,
 	)
-	
-	return err
 }
 
 // FR-007: Verify Prestasi
-func (r *achievementReferenceRepo) VerifyAchievement(id uuid.UUID, verifiedBy uuid.UUID) error {
+func (r *achievementReferenceRepo) VerifyAchievement(id uuid.UUID, currentStage string, award models.PointsResult, change models.StatusChange) error {
 	// Cek status saat ini
 	ref, err := r.GetReferenceByID(id)
 	if err != nil {
@@ -303,26 +402,35 @@ func (r *achievementReferenceRepo) VerifyAchievement(id uuid.UUID, verifiedBy uu
 		return fmt.Errorf("cannot verify achievement with status: %s", ref.Status)
 	}
 	
+	breakdown, err := json.Marshal(award.Breakdown)
+	if err != nil {
+		return err
+	}
+
 	now := time.Now()
 	query := `
 		UPDATE achievement_references 
-		SET status = $1, verified_at = $2, verified_by = $3, updated_at = $4
-		WHERE id = $5
+		SET status = $1, verified_at = $2, verified_by = $3, updated_at = $4, workflow_stage = NULL,
+		    points = $7, points_rule_version = $8, points_breakdown = $9, points_calculated_at = $2
+		WHERE id = $5 AND status = $6 AND COALESCE(workflow_stage, '') = $10
 	`
 	
-	_, err = r.DB.Exec(query, 
+	return r.transition(id, ref.Status, models.AchievementStatusVerified, change, query,
 		models.AchievementStatusVerified,
 		now,
-		verifiedBy,
+		change.ChangedBy,
 		now,
 		id,
+		ref.Status,
+		award.Points,
+		award.RuleVersion,
+		breakdown,
+		currentStage,
 	)
-	
-	return err
 }
 
 // FR-008: Reject Prestasi
-func (r *achievementReferenceRepo) RejectAchievement(id uuid.UUID, verifiedBy uuid.UUID, rejectionNote string) error {
+func (r *achievementReferenceRepo) RejectAchievement(id uuid.UUID, currentStage string, change models.StatusChange) error {
 	// Cek status saat ini
 	ref, err := r.GetReferenceByID(id)
 	if err != nil {
@@ -341,28 +449,223 @@ func (r *achievementReferenceRepo) RejectAchievement(id uuid.UUID, verifiedBy uu
 	query := `
 		UPDATE achievement_references 
 		SET status = $1, verified_at = $2, verified_by = $3, 
-		    rejection_note = $4, updated_at = $5
-		WHERE id = $6
+		    rejection_note = $4, updated_at = $5, workflow_stage = NULL
+		WHERE id = $6 AND status = $7 AND COALESCE(workflow_stage, '') = $8
 	`
 	
-	_, err = r.DB.Exec(query, 
+	return r.transitionWith(id, ref.Status, models.AchievementStatusRejected, change, resetMemberVerification, query,
 		models.AchievementStatusRejected,
 		now,
-		verifiedBy,
-		rejectionNote,
+		change.ChangedBy,
+		change.Note,
 		now,
 		id,
+		ref.Status,
+		currentStage,
 	)
-	
+}
+
+// ResubmitAchievement - prestasi yang ditolak diajukan ulang sebagai revisi baru.
+// Catatan penolakan sebelumnya tetap tersimpan di achievement_status_events.
+func (r *achievementReferenceRepo) ResubmitAchievement(id uuid.UUID, firstStage string, maxRevisions int, change models.StatusChange) error {
+	ref, err := r.GetReferenceByID(id)
+	if err != nil {
+		return err
+	}
+	if ref == nil {
+		return errors.New("achievement not found")
+	}
+
+	if ref.Status != models.AchievementStatusRejected {
+		return fmt.Errorf("cannot resubmit achievement with status: %s", ref.Status)
+	}
+
+	now := time.Now()
+	query := `
+		UPDATE achievement_references 
+		SET status = $1, submitted_at = $2, verified_at = NULL, verified_by = NULL,
+		    rejection_note = NULL, revision = revision + 1, updated_at = $3, workflow_stage = $4
+		WHERE id = $5 AND status = $6 AND revision < $7
+	`
+
+	return r.transitionWith(id, ref.Status, models.AchievementStatusSubmitted, change, resetMemberVerification, query,
+		models.AchievementStatusSubmitted,
+		now,
+		now,
+		nullString(firstStage),
+		id,
+		ref.Status,
+		maxRevisions,
+	)
+}
+
+// GetVerifiedReferences - semua prestasi verified beserta poin & versi aturannya saat ini
+func (r *achievementReferenceRepo) GetVerifiedReferences() ([]models.AchievementReference, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, student_id, mongo_achievement_id, status, COALESCE(points, 0), points_rule_version
+		FROM achievement_references
+		WHERE status = $1
+		ORDER BY verified_at
+	`, models.AchievementStatusVerified)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var refs []models.AchievementReference
+	for rows.Next() {
+		var ref models.AchievementReference
+		var pointsRuleVersion sql.NullInt64
+		if err := rows.Scan(&ref.ID, &ref.StudentID, &ref.MongoAchievementID, &ref.Status, &ref.Points, &pointsRuleVersion); err != nil {
+			return nil, err
+		}
+		if pointsRuleVersion.Valid {
+			version := int(pointsRuleVersion.Int64)
+			ref.PointsRuleVersion = &version
+		}
+		refs = append(refs, ref)
+	}
+	return refs, rows.Err()
+}
+
+// UpdatePoints - ganti poin prestasi verified dengan hasil perhitungan ulang
+func (r *achievementReferenceRepo) UpdatePoints(id uuid.UUID, award models.PointsResult) error {
+	breakdown, err := json.Marshal(award.Breakdown)
+	if err != nil {
+		return err
+	}
+
+	_, err = r.DB.Exec(`
+		UPDATE achievement_references
+		SET points = $1, points_rule_version = $2, points_breakdown = $3, points_calculated_at = NOW()
+		WHERE id = $4 AND status = $5
+	`, award.Points, award.RuleVersion, breakdown, id, models.AchievementStatusVerified)
 	return err
 }
 
+// transition - jalankan UPDATE status (yang wajib memfilter status lama) dan catat event-nya
+// dalam satu transaksi. Jika status sudah berubah oleh request lain, tidak ada yang ditulis.
+func (r *achievementReferenceRepo) transition(id uuid.UUID, from, to string, change models.StatusChange, query string, args ...interface{}) error {
+	return r.transitionWith(id, from, to, change, nil, query, args...)
+}
+
+// transitionWith - seperti transition, dengan penulisan tambahan (extra) di transaksi yang sama
+func (r *achievementReferenceRepo) transitionWith(id uuid.UUID, from, to string, change models.StatusChange, extra func(tx *sql.Tx, id uuid.UUID) error, query string, args ...interface{}) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(query, args...)
+	if err != nil {
+		return err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("%w, expected: %s", ErrStatusChanged, from)
+	}
+
+	if extra != nil {
+		if err := extra(tx, id); err != nil {
+			return err
+		}
+	}
+
+	if err := insertStatusEvent(tx, id, from, to, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// resetMemberVerification - setelah ditolak / diajukan ulang isi prestasi bisa berubah, jadi
+// verifikasi anggota tim (bukan pemilik, verification_status-nya NULL) harus diulang
+func resetMemberVerification(tx *sql.Tx, id uuid.UUID) error {
+	_, err := tx.Exec(`
+		UPDATE achievement_members
+		SET verification_status = $1, verified_by = NULL, verified_at = NULL, rejection_note = NULL
+		WHERE achievement_ref_id = $2 AND verification_status IS NOT NULL
+	`, models.MemberVerificationPending, id)
+	return err
+}
+
+func insertStatusEvent(tx *sql.Tx, refID uuid.UUID, from, to string, change models.StatusChange) error {
+	_, err := tx.Exec(`
+		INSERT INTO achievement_status_events (id, achievement_ref_id, from_status, to_status, changed_by, note, request_id, stage, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
+	`, uuid.New(), refID, nullString(from), to, nullUUID(change.ChangedBy), nullString(change.Note),
+		nullString(change.RequestID), nullString(change.Stage))
+	return err
+}
+
+// GetStatusEvents - riwayat transisi status, urut dari yang paling lama
+func (r *achievementReferenceRepo) GetStatusEvents(id uuid.UUID) ([]models.AchievementStatusEvent, error) {
+	rows, err := r.DB.Query(`
+		SELECT e.id, e.achievement_ref_id, e.from_status, e.to_status, e.changed_by,
+		       COALESCE(u.full_name, ''), e.note, COALESCE(e.request_id, ''), COALESCE(e.stage, ''), e.backfilled, e.created_at
+		FROM achievement_status_events e
+		LEFT JOIN users u ON u.id = e.changed_by
+		WHERE e.achievement_ref_id = $1
+		ORDER BY e.created_at ASC
+	`, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var events []models.AchievementStatusEvent
+	for rows.Next() {
+		var e models.AchievementStatusEvent
+		var fromStatus, changedBy, note sql.NullString
+
+		if err := rows.Scan(
+			&e.ID,
+			&e.AchievementRefID,
+			&fromStatus,
+			&e.ToStatus,
+			&changedBy,
+			&e.ChangedByName,
+			&note,
+			&e.RequestID,
+			&e.Stage,
+			&e.Backfilled,
+			&e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if fromStatus.Valid {
+			e.FromStatus = &fromStatus.String
+		}
+		if changedBy.Valid {
+			parsedUUID, _ := uuid.Parse(changedBy.String)
+			e.ChangedBy = &parsedUUID
+		}
+		if note.Valid {
+			e.Note = &note.String
+		}
+
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
 func (r *achievementReferenceRepo) GetReferencesByStudentID(studentID uuid.UUID, status string) ([]models.AchievementReference, error) {
 	var whereClause string
 	var args []interface{}
 	
 	args = append(args, studentID, models.AchievementStatusDeleted)
-	whereClause = "WHERE student_id = $1 AND status != $2"
+	// Termasuk prestasi tim yang keanggotaannya sudah diterima
+	whereClause = `
+		WHERE (student_id = $1 OR id IN (
+			SELECT achievement_ref_id FROM achievement_members WHERE student_id = $1 AND status = 'accepted'
+		)) AND status != $2
+	`
 	
 	if status != "" {
 		whereClause += " AND status = $3"
@@ -372,7 +675,7 @@ func (r *achievementReferenceRepo) GetReferencesByStudentID(studentID uuid.UUID,
 	query := fmt.Sprintf(`
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       created_at, updated_at, revision, workflow_stage
 		FROM achievement_references
 		%s
 		ORDER BY created_at DESC
@@ -390,6 +693,7 @@ func (r *achievementReferenceRepo) GetReferencesByStudentID(studentID uuid.UUID,
 		var submittedAt, verifiedAt sql.NullTime
 		var verifiedBy sql.NullString
 		var rejectionNote sql.NullString
+		var workflowStage sql.NullString
 		
 		err := rows.Scan(
 			&ref.ID,
@@ -402,6 +706,8 @@ func (r *achievementReferenceRepo) GetReferencesByStudentID(studentID uuid.UUID,
 			&rejectionNote,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
+			&ref.Revision,
+			&workflowStage,
 		)
 		
 		if err != nil {
@@ -421,6 +727,9 @@ func (r *achievementReferenceRepo) GetReferencesByStudentID(studentID uuid.UUID,
 		if rejectionNote.Valid {
 			ref.RejectionNote = &rejectionNote.String
 		}
+		if workflowStage.Valid {
+			ref.WorkflowStage = &workflowStage.String
+		}
 		
 		references = append(references, ref)
 	}
@@ -433,10 +742,15 @@ func (r *achievementReferenceRepo) GetReferencesByAdvisor(advisorID uuid.UUID, s
 	var args []interface{}
 	
 	args = append(args, advisorID, models.AchievementStatusDeleted)
+	// Termasuk prestasi tim di mana mahasiswa bimbingan menjadi anggota
 	whereClause = `
-		WHERE student_id IN (
+		WHERE (student_id IN (
 			SELECT id FROM students WHERE advisor_id = $1
-		) AND status != $2
+		) OR id IN (
+			SELECT m.achievement_ref_id FROM achievement_members m
+			JOIN students s ON s.id = m.student_id
+			WHERE s.advisor_id = $1 AND m.status = 'accepted'
+		)) AND status != $2
 	`
 	
 	if status != "" {
@@ -447,7 +761,7 @@ func (r *achievementReferenceRepo) GetReferencesByAdvisor(advisorID uuid.UUID, s
 	query := fmt.Sprintf(`
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       created_at, updated_at, revision, workflow_stage
 		FROM achievement_references
 		%s
 		ORDER BY created_at DESC
@@ -465,6 +779,7 @@ func (r *achievementReferenceRepo) GetReferencesByAdvisor(advisorID uuid.UUID, s
 		var submittedAt, verifiedAt sql.NullTime
 		var verifiedBy sql.NullString
 		var rejectionNote sql.NullString
+		var workflowStage sql.NullString
 		
 		err := rows.Scan(
 			&ref.ID,
@@ -477,6 +792,8 @@ func (r *achievementReferenceRepo) GetReferencesByAdvisor(advisorID uuid.UUID, s
 			&rejectionNote,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
+			&ref.Revision,
+			&workflowStage,
 		)
 		
 		if err != nil {
@@ -496,6 +813,9 @@ func (r *achievementReferenceRepo) GetReferencesByAdvisor(advisorID uuid.UUID, s
 		if rejectionNote.Valid {
 			ref.RejectionNote = &rejectionNote.String
 		}
+		if workflowStage.Valid {
+			ref.WorkflowStage = &workflowStage.String
+		}
 		
 		references = append(references, ref)
 	}
@@ -503,6 +823,83 @@ func (r *achievementReferenceRepo) GetReferencesByAdvisor(advisorID uuid.UUID, s
 	return references, nil
 }
 
+// GetReferencesByStudentIDs - dipakai untuk scope prodi / departemen (lihat user_scopes)
+func (r *achievementReferenceRepo) GetReferencesByStudentIDs(studentIDs []uuid.UUID, status string) ([]models.AchievementReference, error) {
+	if len(studentIDs) == 0 {
+		return []models.AchievementReference{}, nil
+	}
+
+	args := []interface{}{pq.Array(studentIDs), models.AchievementStatusDeleted}
+	whereClause := "WHERE student_id = ANY($1) AND status != $2"
+	if status != "" {
+		whereClause += " AND status = $3"
+		args = append(args, status)
+	}
+
+	query := fmt.Sprintf(`
+		SELECT id, student_id, mongo_achievement_id, status, 
+		       submitted_at, verified_at, verified_by, rejection_note,
+		       created_at, updated_at, revision, workflow_stage
+		FROM achievement_references
+		%s
+		ORDER BY created_at DESC
+	`, whereClause)
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var references []models.AchievementReference
+	for rows.Next() {
+		var ref models.AchievementReference
+		var submittedAt, verifiedAt sql.NullTime
+		var verifiedBy sql.NullString
+		var rejectionNote sql.NullString
+		var workflowStage sql.NullString
+
+		err := rows.Scan(
+			&ref.ID,
+			&ref.StudentID,
+			&ref.MongoAchievementID,
+			&ref.Status,
+			&submittedAt,
+			&verifiedAt,
+			&verifiedBy,
+			&rejectionNote,
+			&ref.CreatedAt,
+			&ref.UpdatedAt,
+			&ref.Revision,
+			&workflowStage,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		if submittedAt.Valid {
+			ref.SubmittedAt = &submittedAt.Time
+		}
+		if verifiedAt.Valid {
+			ref.VerifiedAt = &verifiedAt.Time
+		}
+		if verifiedBy.Valid {
+			parsedUUID, _ := uuid.Parse(verifiedBy.String)
+			ref.VerifiedBy = &parsedUUID
+		}
+		if rejectionNote.Valid {
+			ref.RejectionNote = &rejectionNote.String
+		}
+		if workflowStage.Valid {
+			ref.WorkflowStage = &workflowStage.String
+		}
+
+		references = append(references, ref)
+	}
+
+	return references, nil
+}
+
 func (r *achievementReferenceRepo) GetAllReferences(status string, limit, offset int) ([]models.AchievementReference, int, error) {
 	var whereClause string
 	var args []interface{}
@@ -529,7 +926,7 @@ func (r *achievementReferenceRepo) GetAllReferences(status string, limit, offset
 	query := fmt.Sprintf(`
 		SELECT id, student_id, mongo_achievement_id, status, 
 		       submitted_at, verified_at, verified_by, rejection_note,
-		       created_at, updated_at
+		       created_at, updated_at, revision, workflow_stage
 		FROM achievement_references
 		%s
 		ORDER BY created_at DESC
@@ -550,6 +947,7 @@ func (r *achievementReferenceRepo) GetAllReferences(status string, limit, offset
 		var submittedAt, verifiedAt sql.NullTime
 		var verifiedBy sql.NullString
 		var rejectionNote sql.NullString
+		var workflowStage sql.NullString
 		
 		err := rows.Scan(
 			&ref.ID,
@@ -562,6 +960,8 @@ func (r *achievementReferenceRepo) GetAllReferences(status string, limit, offset
 			&rejectionNote,
 			&ref.CreatedAt,
 			&ref.UpdatedAt,
+			&ref.Revision,
+			&workflowStage,
 		)
 		
 		if err != nil {
@@ -581,6 +981,9 @@ func (r *achievementReferenceRepo) GetAllReferences(status string, limit, offset
 		if rejectionNote.Valid {
 			ref.RejectionNote = &rejectionNote.String
 		}
+		if workflowStage.Valid {
+			ref.WorkflowStage = &workflowStage.String
+		}
 		
 		references = append(references, ref)
 	}
@@ -602,4 +1005,14 @@ func (r *achievementReferenceRepo) CheckOwnership(achievementID, studentID uuid.
 	}
 	
 	return count > 0, nil
-}
\ No newline at end of file
+}
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullUUID(id uuid.UUID) interface{} {
+	if id == uuid.Nil {
+		return nil
+	}
+	return id
+}
@@ -3,9 +3,11 @@ package repository
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strings"
 	"time"
 
+	"UAS/app/duplicate"
 	"UAS/app/models"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -19,10 +21,15 @@ type AchievementRepository interface {
 	GetAchievementByID(ctx context.Context, id string) (*models.Achievement, error)
 	UpdateAchievement(ctx context.Context, id string, achievement *models.Achievement) error
 	DeleteAchievement(ctx context.Context, id string) error
+	// UpdatePoints - hanya poin, tanpa mengubah updatedAt (bukan perubahan oleh mahasiswa)
+	UpdatePoints(ctx context.Context, id string, points int) error
 	
 	// Query operations
 	FindAchievements(ctx context.Context, studentIDs []string, achievementType, search string, page, limit int, sortBy, sortOrder string) ([]models.Achievement, int64, error)
 	GetAchievementsByIDs(ctx context.Context, ids []string) ([]models.Achievement, error)
+	// FindDuplicateCandidates - prestasi lain (semua mahasiswa) dengan nomor sertifikat (ternormalisasi), hash
+	// lampiran, atau tanggal event yang sama. Kemiripan judul dicek oleh pemanggil.
+	FindDuplicateCandidates(ctx context.Context, excludeID primitive.ObjectID, certificationNumber string, hashes []string, eventDate *time.Time) ([]models.Achievement, error)
 	
 	// Attachment operations
 	AddAttachment(ctx context.Context, achievementID string, attachment models.Attachment) error
@@ -41,6 +48,7 @@ func (r *achievementRepo) CreateAchievement(ctx context.Context, achievement *mo
 	achievement.ID = primitive.NewObjectID()
 	achievement.CreatedAt = time.Now()
 	achievement.UpdatedAt = time.Now()
+	achievement.Details.CertificationNumberNormalized = duplicate.NormalizeNumber(achievement.Details.CertificationNumber)
 	
 	result, err := r.Collection.InsertOne(ctx, achievement)
 	if err != nil {
@@ -75,6 +83,7 @@ func (r *achievementRepo) UpdateAchievement(ctx context.Context, id string, achi
 	}
 	
 	achievement.UpdatedAt = time.Now()
+	achievement.Details.CertificationNumberNormalized = duplicate.NormalizeNumber(achievement.Details.CertificationNumber)
 	update := bson.M{
 		"$set": bson.M{
 			"title":           achievement.Title,
@@ -99,6 +108,22 @@ func (r *achievementRepo) UpdateAchievement(ctx context.Context, id string, achi
 	return nil
 }
 
+func (r *achievementRepo) UpdatePoints(ctx context.Context, id string, points int) error {
+	objectID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid achievement ID: %w", err)
+	}
+
+	result, err := r.Collection.UpdateOne(ctx, bson.M{"_id": objectID}, bson.M{"$set": bson.M{"points": points}})
+	if err != nil {
+		return fmt.Errorf("failed to update points: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("achievement not found")
+	}
+	return nil
+}
+
 func (r *achievementRepo) DeleteAchievement(ctx context.Context, id string) error {
 	objectID, err := primitive.ObjectIDFromHex(id)
 	if err != nil {
@@ -224,6 +249,77 @@ func (r *achievementRepo) GetAchievementsByIDs(ctx context.Context, ids []string
 	return achievements, nil
 }
 
+// maxDateCandidates - kandidat yang hanya sama tanggal event-nya dibatasi, supaya tanggal yang
+// ramai (mis. wisuda) tidak memuat seluruh koleksi. Kecocokan persis tidak dibatasi.
+const maxDateCandidates = 200
+
+func (r *achievementRepo) FindDuplicateCandidates(ctx context.Context, excludeID primitive.ObjectID, certificationNumber string, hashes []string, eventDate *time.Time) ([]models.Achievement, error) {
+	// 1. Kecocokan persis: nomor sertifikat atau hash lampiran
+	var exactConditions bson.A
+	if number := duplicate.NormalizeNumber(certificationNumber); number != "" {
+		exactConditions = append(exactConditions, bson.M{"details.certificationNumberNormalized": number})
+		// Dokumen lama yang belum punya nomor ternormalisasi
+		exactConditions = append(exactConditions, bson.M{
+			"details.certificationNumberNormalized": bson.M{"$exists": false},
+			"details.certificationNumber": bson.M{
+				"$regex":   "^\\s*" + regexp.QuoteMeta(strings.TrimSpace(certificationNumber)) + "\\s*$",
+				"$options": "i",
+			},
+		})
+	}
+	if len(hashes) > 0 {
+		exactConditions = append(exactConditions, bson.M{"attachments.sha256": bson.M{"$in": hashes}})
+	}
+
+	candidates := []models.Achievement{}
+	seen := bson.A{excludeID}
+	if len(exactConditions) > 0 {
+		exact, err := r.findCandidates(ctx, bson.M{
+			"_id": bson.M{"$ne": excludeID},
+			"$or": exactConditions,
+		})
+		if err != nil {
+			return nil, err
+		}
+		for _, achievement := range exact {
+			seen = append(seen, achievement.ID)
+		}
+		candidates = append(candidates, exact...)
+	}
+
+	// 2. Tanggal event yang sama (kemiripan judul dicek pemanggil), tanpa yang sudah ditemukan
+	if eventDate != nil {
+		start := eventDate.UTC().Truncate(24 * time.Hour)
+		sameDate, err := r.findCandidates(ctx, bson.M{
+			"_id": bson.M{"$nin": seen},
+			"details.eventDate": bson.M{
+				"$gte": start,
+				"$lt":  start.Add(24 * time.Hour),
+			},
+		}, options.Find().SetLimit(maxDateCandidates))
+		if err != nil {
+			return nil, err
+		}
+		candidates = append(candidates, sameDate...)
+	}
+
+	return candidates, nil
+}
+
+func (r *achievementRepo) findCandidates(ctx context.Context, filter bson.M, opts ...*options.FindOptions) ([]models.Achievement, error) {
+	cursor, err := r.Collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find duplicate candidates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var achievements []models.Achievement
+	if err = cursor.All(ctx, &achievements); err != nil {
+		return nil, fmt.Errorf("failed to decode achievements: %w", err)
+	}
+	return achievements, nil
+}
+
 func (r *achievementRepo) AddAttachment(ctx context.Context, achievementID string, attachment models.Attachment) error {
 	objectID, err := primitive.ObjectIDFromHex(achievementID)
 	if err != nil {
@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type AchievementTypeRepository interface {
+	List(includeRetired bool) ([]models.AchievementType, error)
+	GetByCode(code string) (*models.AchievementType, error)
+	Create(achievementType *models.AchievementType) error
+	Update(achievementType *models.AchievementType) error
+	SetRetired(code string, retired bool) error
+}
+
+type achievementTypeRepo struct {
+	DB *sql.DB
+}
+
+func NewAchievementTypeRepository(db *sql.DB) AchievementTypeRepository {
+	return &achievementTypeRepo{DB: db}
+}
+
+const achievementTypeColumns = `id, code, name_id, name_en, COALESCE(description, ''), built_in, retired_at, created_at, updated_at`
+
+func scanAchievementType(row interface{ Scan(...interface{}) error }, t *models.AchievementType) error {
+	var retiredAt sql.NullTime
+	err := row.Scan(&t.ID, &t.Code, &t.NameID, &t.NameEN, &t.Description, &t.BuiltIn, &retiredAt, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+	if retiredAt.Valid {
+		t.RetiredAt = &retiredAt.Time
+	}
+	return nil
+}
+
+func (r *achievementTypeRepo) List(includeRetired bool) ([]models.AchievementType, error) {
+	query := `SELECT ` + achievementTypeColumns + ` FROM achievement_types`
+	if !includeRetired {
+		query += ` WHERE retired_at IS NULL`
+	}
+	query += ` ORDER BY built_in DESC, code`
+
+	rows, err := r.DB.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var types []models.AchievementType
+	for rows.Next() {
+		var t models.AchievementType
+		if err := scanAchievementType(rows, &t); err != nil {
+			return nil, err
+		}
+		types = append(types, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	fields, err := r.getFields()
+	if err != nil {
+		return nil, err
+	}
+	for i := range types {
+		types[i].Fields = fields[types[i].ID]
+		if types[i].Fields == nil {
+			types[i].Fields = []models.CustomFieldDefinition{}
+		}
+	}
+	return types, nil
+}
+
+func (r *achievementTypeRepo) GetByCode(code string) (*models.AchievementType, error) {
+	var t models.AchievementType
+	err := scanAchievementType(r.DB.QueryRow(`
+		SELECT `+achievementTypeColumns+` FROM achievement_types WHERE code = $1
+	`, code), &t)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	fields, err := r.getFields(t.ID)
+	if err != nil {
+		return nil, err
+	}
+	t.Fields = fields[t.ID]
+	if t.Fields == nil {
+		t.Fields = []models.CustomFieldDefinition{}
+	}
+	return &t, nil
+}
+
+// getFields - definisi field per type_id (semua tipe jika typeIDs kosong), termasuk yang retired
+func (r *achievementTypeRepo) getFields(typeIDs ...uuid.UUID) (map[uuid.UUID][]models.CustomFieldDefinition, error) {
+	query := `
+		SELECT type_id, key, label_id, label_en, field_type, required, COALESCE(options, '{}'), retired
+		FROM achievement_type_fields`
+	var args []interface{}
+	if len(typeIDs) > 0 {
+		ids := make([]string, len(typeIDs))
+		for i, id := range typeIDs {
+			ids[i] = id.String()
+		}
+		query += ` WHERE type_id = ANY($1::uuid[])`
+		args = append(args, pq.Array(ids))
+	}
+	query += ` ORDER BY retired, sort_order, key`
+
+	rows, err := r.DB.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	fields := map[uuid.UUID][]models.CustomFieldDefinition{}
+	for rows.Next() {
+		var typeID uuid.UUID
+		var field models.CustomFieldDefinition
+		if err := rows.Scan(&typeID, &field.Key, &field.LabelID, &field.LabelEN, &field.Type,
+			&field.Required, pq.Array(&field.Options), &field.Retired); err != nil {
+			return nil, err
+		}
+		fields[typeID] = append(fields[typeID], field)
+	}
+	return fields, rows.Err()
+}
+
+func (r *achievementTypeRepo) Create(t *models.AchievementType) error {
+	t.ID = uuid.New()
+	t.CreatedAt = time.Now()
+	t.UpdatedAt = t.CreatedAt
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		INSERT INTO achievement_types (id, code, name_id, name_en, description, built_in, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, false, $6, $7)
+	`, t.ID, t.Code, t.NameID, t.NameEN, t.Description, t.CreatedAt, t.UpdatedAt)
+	if err != nil {
+		return err
+	}
+
+	if err := saveTypeFields(tx, t.ID, t.Fields); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Update - simpan nama/deskripsi dan daftar field aktif. Field yang tidak ada lagi
+// di daftar ditandai retired, bukan dihapus.
+func (r *achievementTypeRepo) Update(t *models.AchievementType) error {
+	t.UpdatedAt = time.Now()
+
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`
+		UPDATE achievement_types SET name_id = $1, name_en = $2, description = $3, updated_at = $4
+		WHERE id = $5
+	`, t.NameID, t.NameEN, t.Description, t.UpdatedAt, t.ID)
+	if err != nil {
+		return err
+	}
+
+	var active []models.CustomFieldDefinition
+	keys := []string{}
+	for _, field := range t.Fields {
+		if !field.Retired {
+			active = append(active, field)
+			keys = append(keys, field.Key)
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE achievement_type_fields SET retired = true
+		WHERE type_id = $1 AND NOT (key = ANY($2))
+	`, t.ID, pq.Array(keys))
+	if err != nil {
+		return err
+	}
+
+	if err := saveTypeFields(tx, t.ID, active); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func saveTypeFields(tx *sql.Tx, typeID uuid.UUID, fields []models.CustomFieldDefinition) error {
+	for i, field := range fields {
+		if field.Options == nil {
+			field.Options = []string{}
+		}
+		_, err := tx.Exec(`
+			INSERT INTO achievement_type_fields (id, type_id, key, label_id, label_en, field_type, required, options, sort_order, retired)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, false)
+			ON CONFLICT (type_id, key) DO UPDATE SET
+				label_id = EXCLUDED.label_id,
+				label_en = EXCLUDED.label_en,
+				field_type = EXCLUDED.field_type,
+				required = EXCLUDED.required,
+				options = EXCLUDED.options,
+				sort_order = EXCLUDED.sort_order,
+				retired = false
+		`, uuid.New(), typeID, field.Key, field.LabelID, field.LabelEN, field.Type, field.Required,
+			pq.Array(field.Options), i)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *achievementTypeRepo) SetRetired(code string, retired bool) error {
+	query := `UPDATE achievement_types SET retired_at = NULL, updated_at = NOW() WHERE code = $1`
+	if retired {
+		query = `UPDATE achievement_types SET retired_at = NOW(), updated_at = NOW() WHERE code = $1 AND retired_at IS NULL`
+	}
+	result, err := r.DB.Exec(query, code)
+	if err != nil {
+		return err
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AchievementVersionRepository interface {
+	// SaveVersion - simpan snapshot achievement sebagai versi berikutnya
+	SaveVersion(ctx context.Context, achievement *models.Achievement, revision int, savedBy uuid.UUID) (*models.AchievementVersion, error)
+	CountVersions(ctx context.Context, achievementID string) (int64, error)
+	ListVersions(ctx context.Context, achievementID string) ([]models.AchievementVersion, error)
+	GetVersion(ctx context.Context, achievementID string, version int) (*models.AchievementVersion, error)
+}
+
+// maxVersionAttempts - nomor versi dihitung ulang kalau kalah balapan dengan simpan lain
+const maxVersionAttempts = 5
+
+type achievementVersionRepo struct {
+	Collection *mongo.Collection
+
+	indexMu sync.Mutex
+	indexed bool
+}
+
+func NewAchievementVersionRepository(collection *mongo.Collection) AchievementVersionRepository {
+	return &achievementVersionRepo{Collection: collection}
+}
+
+// ensureIndex - unique index (achievementId, version) supaya dua simpan bersamaan tidak
+// mendapat nomor versi yang sama; dibuat sekali per proses
+func (r *achievementVersionRepo) ensureIndex(ctx context.Context) error {
+	r.indexMu.Lock()
+	defer r.indexMu.Unlock()
+
+	if r.indexed {
+		return nil
+	}
+	_, err := r.Collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "achievementId", Value: 1}, {Key: "version", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create achievement version index: %w", err)
+	}
+	r.indexed = true
+	return nil
+}
+
+func (r *achievementVersionRepo) SaveVersion(ctx context.Context, achievement *models.Achievement, revision int, savedBy uuid.UUID) (*models.AchievementVersion, error) {
+	if err := r.ensureIndex(ctx); err != nil {
+		return nil, err
+	}
+
+	achievementID := achievement.ID.Hex()
+
+	for attempt := 0; attempt < maxVersionAttempts; attempt++ {
+		latest := 0
+		var last models.AchievementVersion
+		err := r.Collection.FindOne(ctx,
+			bson.M{"achievementId": achievementID},
+			options.FindOne().SetSort(bson.D{{Key: "version", Value: -1}}),
+		).Decode(&last)
+		if err != nil && err != mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("failed to find latest version: %w", err)
+		}
+		if err == nil {
+			latest = last.Version
+		}
+
+		version := &models.AchievementVersion{
+			AchievementID:   achievementID,
+			Version:         latest + 1,
+			AchievementType: achievement.AchievementType,
+			Title:           achievement.Title,
+			Description:     achievement.Description,
+			Details:         achievement.Details,
+			Attachments:     achievement.Attachments,
+			Tags:            achievement.Tags,
+			Points:          achievement.Points,
+			Revision:        revision,
+			SavedBy:         savedBy,
+			CreatedAt:       time.Now(),
+		}
+
+		_, err = r.Collection.InsertOne(ctx, version)
+		if err == nil {
+			return version, nil
+		}
+		// Nomor versi sudah dipakai simpan lain, hitung ulang
+		if mongo.IsDuplicateKeyError(err) {
+			continue
+		}
+		return nil, fmt.Errorf("failed to insert achievement version: %w", err)
+	}
+
+	return nil, fmt.Errorf("failed to insert achievement version: version number still taken after %d attempts", maxVersionAttempts)
+}
+
+func (r *achievementVersionRepo) CountVersions(ctx context.Context, achievementID string) (int64, error) {
+	count, err := r.Collection.CountDocuments(ctx, bson.M{"achievementId": achievementID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count achievement versions: %w", err)
+	}
+	return count, nil
+}
+
+func (r *achievementVersionRepo) ListVersions(ctx context.Context, achievementID string) ([]models.AchievementVersion, error) {
+	cursor, err := r.Collection.Find(ctx,
+		bson.M{"achievementId": achievementID},
+		options.Find().SetSort(bson.D{{Key: "version", Value: 1}}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find achievement versions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var versions []models.AchievementVersion
+	if err = cursor.All(ctx, &versions); err != nil {
+		return nil, fmt.Errorf("failed to decode achievement versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+func (r *achievementVersionRepo) GetVersion(ctx context.Context, achievementID string, version int) (*models.AchievementVersion, error) {
+	var v models.AchievementVersion
+	err := r.Collection.FindOne(ctx, bson.M{"achievementId": achievementID, "version": version}).Decode(&v)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find achievement version: %w", err)
+	}
+	return &v, nil
+}
@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	GetByID(id uuid.UUID) (*models.APIKey, error)
+	GetByPrefix(prefix string) (*models.APIKey, error)
+	GetByUserID(userID uuid.UUID) ([]models.APIKey, error)
+	Revoke(id uuid.UUID) (bool, error)
+	TouchLastUsed(id uuid.UUID) error
+}
+
+type apiKeyRepo struct {
+	DB *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) APIKeyRepository {
+	return &apiKeyRepo{DB: db}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, key_hash, permissions, expires_at, last_used_at, created_at, revoked_at`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }, k *models.APIKey) error {
+	var expiresAt, lastUsedAt, revokedAt sql.NullTime
+	var permissions pq.StringArray
+
+	if err := row.Scan(
+		&k.ID, &k.UserID, &k.Name, &k.Prefix, &k.KeyHash, &permissions,
+		&expiresAt, &lastUsedAt, &k.CreatedAt, &revokedAt,
+	); err != nil {
+		return err
+	}
+
+	k.Permissions = []string(permissions)
+	if k.Permissions == nil {
+		k.Permissions = []string{}
+	}
+	if expiresAt.Valid {
+		k.ExpiresAt = &expiresAt.Time
+	}
+	if lastUsedAt.Valid {
+		k.LastUsedAt = &lastUsedAt.Time
+	}
+	if revokedAt.Valid {
+		k.RevokedAt = &revokedAt.Time
+	}
+	return nil
+}
+
+func (r *apiKeyRepo) Create(key *models.APIKey) error {
+	if key.ID == uuid.Nil {
+		key.ID = uuid.New()
+	}
+	key.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(`
+		INSERT INTO api_keys (id, user_id, name, prefix, key_hash, permissions, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, key.ID, key.UserID, key.Name, key.Prefix, key.KeyHash, pq.Array(key.Permissions), key.ExpiresAt, key.CreatedAt)
+	return err
+}
+
+func (r *apiKeyRepo) GetByID(id uuid.UUID) (*models.APIKey, error) {
+	var k models.APIKey
+	err := scanAPIKey(r.DB.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id=$1`, id), &k)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepo) GetByPrefix(prefix string) (*models.APIKey, error) {
+	var k models.APIKey
+	err := scanAPIKey(r.DB.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE prefix=$1`, prefix), &k)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &k, nil
+}
+
+func (r *apiKeyRepo) GetByUserID(userID uuid.UUID) ([]models.APIKey, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+apiKeyColumns+`
+		FROM api_keys
+		WHERE user_id=$1
+		ORDER BY created_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		var k models.APIKey
+		if err := scanAPIKey(rows, &k); err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+
+	return keys, rows.Err()
+}
+
+func (r *apiKeyRepo) Revoke(id uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE api_keys SET revoked_at = NOW()
+		WHERE id = $1 AND revoked_at IS NULL
+	`, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+// TouchLastUsed - cukup akurat per menit, supaya tidak menulis ke database di setiap request
+func (r *apiKeyRepo) TouchLastUsed(id uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE api_keys SET last_used_at = NOW()
+		WHERE id = $1 AND (last_used_at IS NULL OR last_used_at < NOW() - INTERVAL '1 minute')
+	`, id)
+	return err
+}
@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type ImpersonationRepository interface {
+	Create(session *models.ImpersonationSession) error
+	End(id uuid.UUID) error
+	GetByID(id uuid.UUID) (*models.ImpersonationSession, error)
+	List(limit int) ([]models.ImpersonationSession, error)
+	RecordAudit(entry *models.ImpersonationAuditLog) error
+	GetAuditBySession(sessionID uuid.UUID) ([]models.ImpersonationAuditLog, error)
+}
+
+type impersonationRepo struct {
+	DB *sql.DB
+}
+
+func NewImpersonationRepository(db *sql.DB) ImpersonationRepository {
+	return &impersonationRepo{DB: db}
+}
+
+const impersonationColumns = `s.id, s.admin_id, s.target_user_id, s.reason, s.token_jti,
+	COALESCE(s.ip_address, ''), COALESCE(s.user_agent, ''), s.started_at, s.expires_at, s.ended_at,
+	(SELECT COUNT(*) FROM impersonation_audit_logs a WHERE a.session_id = s.id)`
+
+func scanImpersonation(row interface{ Scan(...interface{}) error }, s *models.ImpersonationSession) error {
+	var endedAt sql.NullTime
+	err := row.Scan(
+		&s.ID, &s.AdminID, &s.TargetUserID, &s.Reason, &s.TokenJTI,
+		&s.IPAddress, &s.UserAgent, &s.StartedAt, &s.ExpiresAt, &endedAt, &s.RequestCount,
+	)
+	if err != nil {
+		return err
+	}
+	if endedAt.Valid {
+		s.EndedAt = &endedAt.Time
+	}
+	return nil
+}
+
+func (r *impersonationRepo) Create(session *models.ImpersonationSession) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	session.StartedAt = time.Now()
+
+	_, err := r.DB.Exec(`
+		INSERT INTO impersonation_sessions (id, admin_id, target_user_id, reason, token_jti, ip_address, user_agent, started_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, session.ID, session.AdminID, session.TargetUserID, session.Reason, session.TokenJTI,
+		session.IPAddress, session.UserAgent, session.StartedAt, session.ExpiresAt)
+	return err
+}
+
+func (r *impersonationRepo) End(id uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE impersonation_sessions SET ended_at = NOW()
+		WHERE id = $1 AND ended_at IS NULL
+	`, id)
+	return err
+}
+
+func (r *impersonationRepo) GetByID(id uuid.UUID) (*models.ImpersonationSession, error) {
+	var s models.ImpersonationSession
+	err := scanImpersonation(r.DB.QueryRow(`
+		SELECT `+impersonationColumns+`
+		FROM impersonation_sessions s
+		WHERE s.id = $1
+	`, id), &s)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *impersonationRepo) List(limit int) ([]models.ImpersonationSession, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+impersonationColumns+`
+		FROM impersonation_sessions s
+		ORDER BY s.started_at DESC
+		LIMIT $1
+	`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.ImpersonationSession
+	for rows.Next() {
+		var s models.ImpersonationSession
+		if err := scanImpersonation(rows, &s); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+	return sessions, rows.Err()
+}
+
+func (r *impersonationRepo) RecordAudit(entry *models.ImpersonationAuditLog) error {
+	if entry.ID == uuid.Nil {
+		entry.ID = uuid.New()
+	}
+	entry.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(`
+		INSERT INTO impersonation_audit_logs (id, session_id, admin_id, target_user_id, method, path, status_code, blocked, ip_address, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+	`, entry.ID, entry.SessionID, entry.AdminID, entry.TargetUserID, entry.Method, entry.Path,
+		entry.StatusCode, entry.Blocked, entry.IPAddress, entry.CreatedAt)
+	return err
+}
+
+func (r *impersonationRepo) GetAuditBySession(sessionID uuid.UUID) ([]models.ImpersonationAuditLog, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, session_id, admin_id, target_user_id, method, path, status_code, blocked,
+			COALESCE(ip_address, ''), created_at
+		FROM impersonation_audit_logs
+		WHERE session_id = $1
+		ORDER BY created_at ASC
+	`, sessionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var entries []models.ImpersonationAuditLog
+	for rows.Next() {
+		var e models.ImpersonationAuditLog
+		if err := rows.Scan(
+			&e.ID, &e.SessionID, &e.AdminID, &e.TargetUserID, &e.Method, &e.Path,
+			&e.StatusCode, &e.Blocked, &e.IPAddress, &e.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
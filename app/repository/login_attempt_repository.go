@@ -0,0 +1,198 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type LoginAttemptRepository interface {
+	RecordAttempt(attempt *models.LoginAttempt) error
+	CountRecentFailuresByIP(ip string, since time.Time) (int, error)
+
+	GetLockout(userID uuid.UUID) (*models.AccountLockout, error)
+	RegisterFailure(userID uuid.UUID) (*models.AccountLockout, error)
+	Lock(userID uuid.UUID, until time.Time, reason string) error
+	ResetFailures(userID uuid.UUID) error
+	Unlock(userID uuid.UUID) (bool, error)
+	GetLockedAccounts(page, limit int) ([]models.LockedAccount, int, error)
+}
+
+type loginAttemptRepo struct {
+	DB *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &loginAttemptRepo{DB: db}
+}
+
+func (r *loginAttemptRepo) RecordAttempt(attempt *models.LoginAttempt) error {
+	if attempt.ID == uuid.Nil {
+		attempt.ID = uuid.New()
+	}
+	attempt.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(`
+		INSERT INTO login_attempts (id, user_id, identifier, ip_address, user_agent, success, failure_reason, lockout_reason, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, attempt.ID, attempt.UserID, attempt.Identifier, attempt.IPAddress, attempt.UserAgent,
+		attempt.Success, attempt.FailureReason, attempt.LockoutReason, attempt.CreatedAt)
+	return err
+}
+
+func (r *loginAttemptRepo) CountRecentFailuresByIP(ip string, since time.Time) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT COUNT(*) FROM login_attempts
+		WHERE ip_address=$1 AND success=false AND created_at > $2
+	`, ip, since).Scan(&count)
+	return count, err
+}
+
+func (r *loginAttemptRepo) GetLockout(userID uuid.UUID) (*models.AccountLockout, error) {
+	var l models.AccountLockout
+	var lockedUntil, lastFailedAt sql.NullTime
+	var lockReason sql.NullString
+
+	err := r.DB.QueryRow(`
+		SELECT user_id, failed_attempts, lockout_count, locked_until, lock_reason, last_failed_at, updated_at
+		FROM account_lockouts
+		WHERE user_id=$1
+	`, userID).Scan(&l.UserID, &l.FailedAttempts, &l.LockoutCount, &lockedUntil, &lockReason, &lastFailedAt, &l.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	scanLockoutNullables(&l, lockedUntil, lastFailedAt, lockReason)
+	return &l, nil
+}
+
+// RegisterFailure - tambah counter gagal secara atomik dan kembalikan status terbaru
+func (r *loginAttemptRepo) RegisterFailure(userID uuid.UUID) (*models.AccountLockout, error) {
+	var l models.AccountLockout
+	var lockedUntil, lastFailedAt sql.NullTime
+	var lockReason sql.NullString
+
+	err := r.DB.QueryRow(`
+		INSERT INTO account_lockouts (user_id, failed_attempts, last_failed_at, updated_at)
+		VALUES ($1, 1, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET failed_attempts = account_lockouts.failed_attempts + 1,
+		    last_failed_at = NOW(),
+		    updated_at = NOW()
+		RETURNING user_id, failed_attempts, lockout_count, locked_until, lock_reason, last_failed_at, updated_at
+	`, userID).Scan(&l.UserID, &l.FailedAttempts, &l.LockoutCount, &lockedUntil, &lockReason, &lastFailedAt, &l.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	scanLockoutNullables(&l, lockedUntil, lastFailedAt, lockReason)
+	return &l, nil
+}
+
+// Lock - counter gagal direset, lockout_count naik untuk menghitung backoff berikutnya
+func (r *loginAttemptRepo) Lock(userID uuid.UUID, until time.Time, reason string) error {
+	_, err := r.DB.Exec(`
+		UPDATE account_lockouts
+		SET locked_until = $1,
+		    lock_reason = $2,
+		    failed_attempts = 0,
+		    lockout_count = lockout_count + 1,
+		    updated_at = NOW()
+		WHERE user_id = $3
+	`, until, reason, userID)
+	return err
+}
+
+// ResetFailures - dipanggil setelah login berhasil
+func (r *loginAttemptRepo) ResetFailures(userID uuid.UUID) error {
+	_, err := r.DB.Exec(`DELETE FROM account_lockouts WHERE user_id = $1`, userID)
+	return err
+}
+
+// Unlock - buka kunci oleh admin, false jika akun tidak sedang dikunci
+func (r *loginAttemptRepo) Unlock(userID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		DELETE FROM account_lockouts
+		WHERE user_id = $1 AND locked_until > NOW()
+	`, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *loginAttemptRepo) GetLockedAccounts(page, limit int) ([]models.LockedAccount, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+	offset := (page - 1) * limit
+
+	rows, err := r.DB.Query(`
+		SELECT l.user_id, l.failed_attempts, l.lockout_count, l.locked_until, l.lock_reason,
+		       l.last_failed_at, l.updated_at, u.username, u.email, u.full_name
+		FROM account_lockouts l
+		JOIN users u ON u.id = l.user_id
+		WHERE l.locked_until > NOW()
+		ORDER BY l.locked_until DESC
+		LIMIT $1 OFFSET $2
+	`, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	accounts := []models.LockedAccount{}
+	for rows.Next() {
+		var a models.LockedAccount
+		var lockedUntil, lastFailedAt sql.NullTime
+		var lockReason sql.NullString
+
+		if err := rows.Scan(
+			&a.UserID, &a.FailedAttempts, &a.LockoutCount, &lockedUntil, &lockReason,
+			&lastFailedAt, &a.UpdatedAt, &a.Username, &a.Email, &a.FullName,
+		); err != nil {
+			return nil, 0, err
+		}
+
+		scanLockoutNullables(&a.AccountLockout, lockedUntil, lastFailedAt, lockReason)
+		accounts = append(accounts, a)
+	}
+
+	var total int
+	err = r.DB.QueryRow(`
+		SELECT COUNT(*) FROM account_lockouts WHERE locked_until > NOW()
+	`).Scan(&total)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return accounts, total, nil
+}
+
+func scanLockoutNullables(l *models.AccountLockout, lockedUntil, lastFailedAt sql.NullTime, lockReason sql.NullString) {
+	if lockedUntil.Valid {
+		l.LockedUntil = &lockedUntil.Time
+	}
+	if lastFailedAt.Valid {
+		l.LastFailedAt = &lastFailedAt.Time
+	}
+	if lockReason.Valid {
+		l.LockReason = &lockReason.String
+	}
+}
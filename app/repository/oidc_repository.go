@@ -0,0 +1,111 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type OIDCRepository interface {
+	SaveState(state *models.OIDCLoginState) error
+	ConsumeState(stateHash string) (*models.OIDCLoginState, error)
+
+	GetIdentity(issuer, subject string) (*models.UserIdentity, error)
+	CreateIdentity(identity *models.UserIdentity) error
+	TouchIdentity(id uuid.UUID) error
+}
+
+type oidcRepo struct {
+	DB *sql.DB
+}
+
+func NewOIDCRepository(db *sql.DB) OIDCRepository {
+	return &oidcRepo{DB: db}
+}
+
+func (r *oidcRepo) SaveState(state *models.OIDCLoginState) error {
+	state.CreatedAt = time.Now()
+
+	// Sekalian bersihkan state lama yang tidak pernah kembali dari IdP
+	if _, err := r.DB.Exec(`DELETE FROM oidc_login_states WHERE expires_at < NOW()`); err != nil {
+		return err
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO oidc_login_states (state_hash, code_verifier, nonce, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5)
+	`, state.StateHash, state.CodeVerifier, state.Nonce, state.ExpiresAt, state.CreatedAt)
+	return err
+}
+
+// ConsumeState - ambil dan hapus state dalam satu query supaya callback tidak bisa diputar ulang
+func (r *oidcRepo) ConsumeState(stateHash string) (*models.OIDCLoginState, error) {
+	var s models.OIDCLoginState
+	err := r.DB.QueryRow(`
+		DELETE FROM oidc_login_states
+		WHERE state_hash = $1
+		RETURNING state_hash, code_verifier, nonce, expires_at, created_at
+	`, stateHash).Scan(&s.StateHash, &s.CodeVerifier, &s.Nonce, &s.ExpiresAt, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if time.Now().After(s.ExpiresAt) {
+		return nil, nil
+	}
+
+	return &s, nil
+}
+
+func (r *oidcRepo) GetIdentity(issuer, subject string) (*models.UserIdentity, error) {
+	var i models.UserIdentity
+	var email sql.NullString
+	var lastLoginAt sql.NullTime
+
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, issuer, subject, email, created_at, last_login_at
+		FROM user_identities
+		WHERE issuer=$1 AND subject=$2
+	`, issuer, subject).Scan(&i.ID, &i.UserID, &i.Issuer, &i.Subject, &email, &i.CreatedAt, &lastLoginAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if email.Valid {
+		i.Email = email.String
+	}
+	if lastLoginAt.Valid {
+		i.LastLoginAt = &lastLoginAt.Time
+	}
+
+	return &i, nil
+}
+
+func (r *oidcRepo) CreateIdentity(identity *models.UserIdentity) error {
+	if identity.ID == uuid.Nil {
+		identity.ID = uuid.New()
+	}
+	now := time.Now()
+	identity.CreatedAt = now
+	identity.LastLoginAt = &now
+
+	_, err := r.DB.Exec(`
+		INSERT INTO user_identities (id, user_id, issuer, subject, email, created_at, last_login_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`, identity.ID, identity.UserID, identity.Issuer, identity.Subject, identity.Email, identity.CreatedAt, identity.LastLoginAt)
+	return err
+}
+
+func (r *oidcRepo) TouchIdentity(id uuid.UUID) error {
+	_, err := r.DB.Exec(`UPDATE user_identities SET last_login_at = NOW() WHERE id = $1`, id)
+	return err
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type PasswordResetRepository interface {
+	Create(token *models.PasswordResetToken) error
+	GetValidByHash(tokenHash string) (*models.PasswordResetToken, error)
+	MarkUsed(id uuid.UUID) (bool, error)
+	InvalidateAllForUser(userID uuid.UUID) error
+}
+
+type passwordResetRepo struct {
+	DB *sql.DB
+}
+
+func NewPasswordResetRepository(db *sql.DB) PasswordResetRepository {
+	return &passwordResetRepo{DB: db}
+}
+
+func (r *passwordResetRepo) Create(token *models.PasswordResetToken) error {
+	if token.ID == uuid.Nil {
+		token.ID = uuid.New()
+	}
+	token.CreatedAt = time.Now()
+
+	_, err := r.DB.Exec(`
+		INSERT INTO password_reset_tokens (id, user_id, token_hash, requested_ip, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, token.ID, token.UserID, token.TokenHash, token.RequestedIP, token.ExpiresAt, token.CreatedAt)
+	return err
+}
+
+// GetValidByHash - hanya token yang belum dipakai dan belum expired
+func (r *passwordResetRepo) GetValidByHash(tokenHash string) (*models.PasswordResetToken, error) {
+	var t models.PasswordResetToken
+	var requestedIP sql.NullString
+
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, token_hash, requested_ip, expires_at, created_at
+		FROM password_reset_tokens
+		WHERE token_hash=$1 AND used_at IS NULL AND expires_at > NOW()
+	`, tokenHash).Scan(&t.ID, &t.UserID, &t.TokenHash, &requestedIP, &t.ExpiresAt, &t.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if requestedIP.Valid {
+		t.RequestedIP = requestedIP.String
+	}
+
+	return &t, nil
+}
+
+// MarkUsed - false jika token sudah dipakai oleh request lain
+func (r *passwordResetRepo) MarkUsed(id uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE id = $1 AND used_at IS NULL AND expires_at > NOW()
+	`, id)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *passwordResetRepo) InvalidateAllForUser(userID uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE password_reset_tokens
+		SET used_at = NOW()
+		WHERE user_id = $1 AND used_at IS NULL
+	`, userID)
+	return err
+}
@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type PermissionRepository interface {
+	GetAll() ([]models.Permission, error)
+	GetByID(id uuid.UUID) (*models.Permission, error)
+	GetByName(name string) (*models.Permission, error)
+	Create(permission models.Permission) (uuid.UUID, error)
+}
+
+type permissionRepo struct {
+	DB *sql.DB
+}
+
+func NewPermissionRepository(db *sql.DB) PermissionRepository {
+	return &permissionRepo{DB: db}
+}
+
+func (r *permissionRepo) GetAll() ([]models.Permission, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions
+		ORDER BY resource, action
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var permissions []models.Permission
+	for rows.Next() {
+		var p models.Permission
+		if err := rows.Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description); err != nil {
+			return nil, err
+		}
+		permissions = append(permissions, p)
+	}
+	return permissions, nil
+}
+
+func (r *permissionRepo) GetByID(id uuid.UUID) (*models.Permission, error) {
+	var p models.Permission
+	err := r.DB.QueryRow(`
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions WHERE id=$1
+	`, id).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *permissionRepo) GetByName(name string) (*models.Permission, error) {
+	var p models.Permission
+	err := r.DB.QueryRow(`
+		SELECT id, name, resource, action, COALESCE(description, '')
+		FROM permissions WHERE name=$1
+	`, name).Scan(&p.ID, &p.Name, &p.Resource, &p.Action, &p.Description)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &p, nil
+}
+
+func (r *permissionRepo) Create(permission models.Permission) (uuid.UUID, error) {
+	if permission.ID == uuid.Nil {
+		permission.ID = uuid.New()
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO permissions (id, name, resource, action, description)
+		VALUES ($1, $2, $3, $4, $5)
+	`, permission.ID, permission.Name, permission.Resource, permission.Action, permission.Description)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return permission.ID, nil
+}
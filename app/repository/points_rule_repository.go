@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type PointsRuleRepository interface {
+	GetLatest() (*models.PointsRuleSet, error)
+	GetByVersion(version int) (*models.PointsRuleSet, error)
+	List() ([]models.PointsRuleSet, error)
+	Create(set *models.PointsRuleSet) error
+}
+
+type pointsRuleRepo struct {
+	DB *sql.DB
+}
+
+func NewPointsRuleRepository(db *sql.DB) PointsRuleRepository {
+	return &pointsRuleRepo{DB: db}
+}
+
+const pointsRuleSetQuery = `
+	SELECT p.version, p.rules, COALESCE(p.note, ''), p.created_by, COALESCE(u.full_name, ''), p.created_at
+	FROM points_rule_sets p
+	LEFT JOIN users u ON u.id = p.created_by`
+
+func scanPointsRuleSet(row interface{ Scan(...interface{}) error }, set *models.PointsRuleSet, withRules bool) error {
+	var rules []byte
+	var createdBy uuid.NullUUID
+	var createdAt sql.NullTime
+	if err := row.Scan(&set.Version, &rules, &set.Note, &createdBy, &set.CreatedByName, &createdAt); err != nil {
+		return err
+	}
+	if createdBy.Valid {
+		set.CreatedBy = &createdBy.UUID
+	}
+	if createdAt.Valid {
+		set.CreatedAt = &createdAt.Time
+	}
+	if withRules {
+		return json.Unmarshal(rules, &set.Rules)
+	}
+	return nil
+}
+
+func (r *pointsRuleRepo) GetLatest() (*models.PointsRuleSet, error) {
+	var set models.PointsRuleSet
+	err := scanPointsRuleSet(r.DB.QueryRow(pointsRuleSetQuery+` ORDER BY p.version DESC LIMIT 1`), &set, true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &set, nil
+}
+
+func (r *pointsRuleRepo) GetByVersion(version int) (*models.PointsRuleSet, error) {
+	var set models.PointsRuleSet
+	err := scanPointsRuleSet(r.DB.QueryRow(pointsRuleSetQuery+` WHERE p.version = $1`, version), &set, true)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &set, nil
+}
+
+// List - semua versi tanpa isi aturannya, terbaru dulu
+func (r *pointsRuleRepo) List() ([]models.PointsRuleSet, error) {
+	rows, err := r.DB.Query(pointsRuleSetQuery + ` ORDER BY p.version DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sets []models.PointsRuleSet
+	for rows.Next() {
+		var set models.PointsRuleSet
+		if err := scanPointsRuleSet(rows, &set, false); err != nil {
+			return nil, err
+		}
+		sets = append(sets, set)
+	}
+	return sets, rows.Err()
+}
+
+// Create - simpan sebagai versi berikutnya; set.Version dan CreatedAt diisi dari database
+func (r *pointsRuleRepo) Create(set *models.PointsRuleSet) error {
+	rules, err := json.Marshal(set.Rules)
+	if err != nil {
+		return err
+	}
+
+	var createdBy interface{}
+	if set.CreatedBy != nil {
+		createdBy = *set.CreatedBy
+	}
+
+	var createdAt sql.NullTime
+	err = r.DB.QueryRow(`
+		INSERT INTO points_rule_sets (version, rules, note, created_by, created_at)
+		SELECT COALESCE(MAX(version), 0) + 1, $1, $2, $3, NOW() FROM points_rule_sets
+		RETURNING version, created_at
+	`, rules, nullString(set.Note), createdBy).Scan(&set.Version, &createdAt)
+	if err != nil {
+		return err
+	}
+	set.CreatedAt = &createdAt.Time
+	return nil
+}
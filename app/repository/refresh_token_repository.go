@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+// ErrRefreshTokenReused - refresh token sudah pernah dirotasi lalu dipakai lagi
+var ErrRefreshTokenReused = errors.New("refresh token already rotated")
+
+type RefreshTokenRepository interface {
+	CreateFamily(userID uuid.UUID) (*models.RefreshTokenFamily, error)
+	GetFamily(id uuid.UUID) (*models.RefreshTokenFamily, error)
+	RevokeFamily(id uuid.UUID, reason string) error
+	RevokeAllFamiliesForUser(userID uuid.UUID, reason string) error
+
+	StoreToken(token *models.RefreshToken) error
+	GetToken(jti string) (*models.RefreshToken, error)
+	Rotate(oldJTI string, newToken *models.RefreshToken) error
+}
+
+type refreshTokenRepo struct {
+	DB *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &refreshTokenRepo{DB: db}
+}
+
+func (r *refreshTokenRepo) CreateFamily(userID uuid.UUID) (*models.RefreshTokenFamily, error) {
+	family := &models.RefreshTokenFamily{
+		ID:        uuid.New(),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO refresh_token_families (id, user_id, created_at)
+		VALUES ($1, $2, $3)
+	`, family.ID, family.UserID, family.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return family, nil
+}
+
+func (r *refreshTokenRepo) GetFamily(id uuid.UUID) (*models.RefreshTokenFamily, error) {
+	var f models.RefreshTokenFamily
+	var revokedAt sql.NullTime
+	var revokeReason sql.NullString
+
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, created_at, revoked_at, revoke_reason
+		FROM refresh_token_families
+		WHERE id=$1
+	`, id).Scan(&f.ID, &f.UserID, &f.CreatedAt, &revokedAt, &revokeReason)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if revokedAt.Valid {
+		f.RevokedAt = &revokedAt.Time
+	}
+	if revokeReason.Valid {
+		f.RevokeReason = &revokeReason.String
+	}
+
+	return &f, nil
+}
+
+func (r *refreshTokenRepo) RevokeFamily(id uuid.UUID, reason string) error {
+	_, err := r.DB.Exec(`
+		UPDATE refresh_token_families
+		SET revoked_at = NOW(), revoke_reason = $1
+		WHERE id = $2 AND revoked_at IS NULL
+	`, reason, id)
+	return err
+}
+
+func (r *refreshTokenRepo) RevokeAllFamiliesForUser(userID uuid.UUID, reason string) error {
+	_, err := r.DB.Exec(`
+		UPDATE refresh_token_families
+		SET revoked_at = NOW(), revoke_reason = $1
+		WHERE user_id = $2 AND revoked_at IS NULL
+	`, reason, userID)
+	return err
+}
+
+func (r *refreshTokenRepo) StoreToken(token *models.RefreshToken) error {
+	_, err := r.DB.Exec(`
+		INSERT INTO refresh_tokens (jti, family_id, user_id, parent_jti, issued_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, token.JTI, token.FamilyID, token.UserID, token.ParentJTI, token.IssuedAt, token.ExpiresAt)
+	return err
+}
+
+func (r *refreshTokenRepo) GetToken(jti string) (*models.RefreshToken, error) {
+	var t models.RefreshToken
+	var parentJTI sql.NullString
+	var rotatedAt sql.NullTime
+
+	err := r.DB.QueryRow(`
+		SELECT jti, family_id, user_id, parent_jti, issued_at, expires_at, rotated_at
+		FROM refresh_tokens
+		WHERE jti=$1
+	`, jti).Scan(&t.JTI, &t.FamilyID, &t.UserID, &parentJTI, &t.IssuedAt, &t.ExpiresAt, &rotatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if parentJTI.Valid {
+		t.ParentJTI = &parentJTI.String
+	}
+	if rotatedAt.Valid {
+		t.RotatedAt = &rotatedAt.Time
+	}
+
+	return &t, nil
+}
+
+// Rotate - tandai token lama sebagai rotated dan simpan token baru dalam satu transaksi.
+// Mengembalikan ErrRefreshTokenReused jika token lama sudah dirotasi sebelumnya.
+func (r *refreshTokenRepo) Rotate(oldJTI string, newToken *models.RefreshToken) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE refresh_tokens
+		SET rotated_at = NOW()
+		WHERE jti = $1 AND rotated_at IS NULL
+	`, oldJTI)
+	if err != nil {
+		return fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO refresh_tokens (jti, family_id, user_id, parent_jti, issued_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+	`, newToken.JTI, newToken.FamilyID, newToken.UserID, newToken.ParentJTI, newToken.IssuedAt, newToken.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to store refresh token: %w", err)
+	}
+
+	return tx.Commit()
+}
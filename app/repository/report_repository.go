@@ -2,6 +2,7 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"UAS/app/models"
@@ -10,6 +11,7 @@ import (
 	"github.com/google/uuid"
 	"github.com/lib/pq"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type ReportRepository interface {
@@ -24,6 +26,11 @@ type ReportRepository interface {
 
 type reportRepo struct{}
 
+// creditedMemberCondition - prestasi tim dihitung untuk pemilik, dan untuk anggota lain
+// setelah menerima undangan dan diverifikasi dosen walinya
+const creditedMemberCondition = `am.status = 'accepted'
+	AND (am.student_id = ar.student_id OR am.verification_status = 'verified')`
+
 func NewReportRepository() ReportRepository {
 	return &reportRepo{}
 }
@@ -67,6 +74,21 @@ func (r *reportRepo) GetStatistics(
 			}
 		}
 
+	case "scoped":
+		// actorID = user id; mahasiswa bimbingan + mahasiswa dalam user_scopes
+		rows, err := database.PgDB.QueryContext(ctx, scopedStudentsQuery, actorID)
+		if err != nil {
+			return nil, err
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var id uuid.UUID
+			if err := rows.Scan(&id); err == nil {
+				studentIDs = append(studentIDs, id)
+			}
+		}
+
 	case "all":
 		rows, err := database.PgDB.QueryContext(
 			ctx,
@@ -90,22 +112,23 @@ func (r *reportRepo) GetStatistics(
 	}
 
 	query := `
-		SELECT TO_CHAR(verified_at, 'YYYY-MM') AS period, COUNT(*)
-		FROM achievement_references
-		WHERE status = 'verified'
-		AND student_id = ANY($1)
+		SELECT TO_CHAR(ar.verified_at, 'YYYY-MM') AS period, COUNT(DISTINCT ar.id)
+		FROM achievement_references ar
+		JOIN achievement_members am ON am.achievement_ref_id = ar.id AND ` + creditedMemberCondition + `
+		WHERE ar.status = 'verified'
+		AND am.student_id = ANY($1)
 	`
 	args := []interface{}{pq.Array(studentIDs)}
 	param := 2
 
 	if startDate != nil {
-		query += " AND verified_at >= $" + string(rune('0'+param))
+		query += " AND ar.verified_at >= $" + string(rune('0'+param))
 		args = append(args, *startDate)
 		param++
 	}
 
 	if endDate != nil {
-		query += " AND verified_at <= $" + string(rune('0'+param))
+		query += " AND ar.verified_at <= $" + string(rune('0'+param))
 		args = append(args, *endDate)
 		param++
 	}
@@ -125,17 +148,19 @@ func (r *reportRepo) GetStatistics(
 		}
 	}
 
-	if scope == "all" || scope == "lecturer" {
+	if scope == "all" || scope == "lecturer" || scope == "scoped" {
 		topQuery := `
 			SELECT 
 				s.id,
 				u.full_name,
 				COUNT(ar.id),
-				COUNT(ar.id) * 10
+				COALESCE(SUM(ar.points), 0)
 			FROM students s
 			JOIN users u ON u.id = s.user_id
-			JOIN achievement_references ar ON ar.student_id = s.id
+			JOIN achievement_members am ON am.student_id = s.id
+			JOIN achievement_references ar ON ar.id = am.achievement_ref_id
 			WHERE ar.status = 'verified'
+			AND ` + creditedMemberCondition + `
 		`
 
 		var params []interface{}
@@ -143,10 +168,14 @@ func (r *reportRepo) GetStatistics(
 			topQuery += " AND s.advisor_id = $1"
 			params = append(params, actorID)
 		}
+		if scope == "scoped" {
+			topQuery += " AND s.id = ANY($1)"
+			params = append(params, pq.Array(studentIDs))
+		}
 
 		topQuery += `
 			GROUP BY s.id, u.full_name
-			ORDER BY COUNT(ar.id) DESC
+			ORDER BY COALESCE(SUM(ar.points), 0) DESC, COUNT(ar.id) DESC
 			LIMIT 10
 		`
 
@@ -167,21 +196,18 @@ func (r *reportRepo) GetStatistics(
 		}
 	}
 
-	var studentIDStrings []string
-	for _, id := range studentIDs {
-		studentIDStrings = append(studentIDStrings, id.String())
+	// Statistik MongoDB hanya untuk prestasi terverifikasi yang dikreditkan ke mahasiswa dalam scope,
+	// sama seperti perhitungan per periode di atas
+	mongoIDs, err := creditedMongoIDs(ctx, studentIDs, startDate, endDate)
+	if err != nil {
+		return nil, err
 	}
-
-	typePipeline := bson.A{
-		bson.D{{Key: "$match", Value: bson.D{
-			{Key: "studentId", Value: bson.D{{Key: "$in", Value: studentIDStrings}}},
-		}}},
-		bson.D{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$achievementType"},
-			{Key: "total", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
+	if len(mongoIDs) == 0 {
+		return stats, nil
 	}
 
+	typePipeline := achievementTypePipeline(mongoIDs)
+
 	cur, err := database.MongoDB.Collection("achievements").Aggregate(ctx, typePipeline)
 	if err == nil {
 		defer cur.Close(ctx)
@@ -199,16 +225,7 @@ func (r *reportRepo) GetStatistics(
 		}
 	}
 
-	compPipeline := bson.A{
-		bson.D{{Key: "$match", Value: bson.D{
-			{Key: "achievementType", Value: "competition"},
-			{Key: "studentId", Value: bson.D{{Key: "$in", Value: studentIDStrings}}},
-		}}},
-		bson.D{{Key: "$group", Value: bson.D{
-			{Key: "_id", Value: "$details.competitionLevel"},
-			{Key: "total", Value: bson.D{{Key: "$sum", Value: 1}}},
-		}}},
-	}
+	compPipeline := competitionLevelPipeline(mongoIDs)
 
 	compCur, err := database.MongoDB.Collection("achievements").Aggregate(ctx, compPipeline)
 	if err == nil {
@@ -229,3 +246,68 @@ func (r *reportRepo) GetStatistics(
 
 	return stats, nil
 }
+
+// creditedMongoIDs - id dokumen MongoDB prestasi terverifikasi dengan salah satu studentIDs
+// sebagai anggota yang dikreditkan, difilter tanggal verifikasi
+func creditedMongoIDs(ctx context.Context, studentIDs []uuid.UUID, startDate, endDate *time.Time) ([]primitive.ObjectID, error) {
+	query := `
+		SELECT DISTINCT ar.mongo_achievement_id
+		FROM achievement_references ar
+		JOIN achievement_members am ON am.achievement_ref_id = ar.id AND ` + creditedMemberCondition + `
+		WHERE ar.status = 'verified'
+		AND am.student_id = ANY($1)
+	`
+	args := []interface{}{pq.Array(studentIDs)}
+
+	if startDate != nil {
+		args = append(args, *startDate)
+		query += fmt.Sprintf(" AND ar.verified_at >= $%d", len(args))
+	}
+	if endDate != nil {
+		args = append(args, *endDate)
+		query += fmt.Sprintf(" AND ar.verified_at <= $%d", len(args))
+	}
+
+	rows, err := database.PgDB.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []primitive.ObjectID
+	for rows.Next() {
+		var hex string
+		if err := rows.Scan(&hex); err != nil {
+			return nil, err
+		}
+		if id, err := primitive.ObjectIDFromHex(hex); err == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids, rows.Err()
+}
+
+func achievementTypePipeline(mongoIDs []primitive.ObjectID) bson.A {
+	return bson.A{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$in", Value: mongoIDs}}},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$achievementType"},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+}
+
+func competitionLevelPipeline(mongoIDs []primitive.ObjectID) bson.A {
+	return bson.A{
+		bson.D{{Key: "$match", Value: bson.D{
+			{Key: "_id", Value: bson.D{{Key: "$in", Value: mongoIDs}}},
+			{Key: "achievementType", Value: "competition"},
+		}}},
+		bson.D{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$details.competitionLevel"},
+			{Key: "total", Value: bson.D{{Key: "$sum", Value: 1}}},
+		}}},
+	}
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"reflect"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestReportPipelinesMatchCreditedAchievementIDs(t *testing.T) {
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID()}
+
+	tests := []struct {
+		name      string
+		pipeline  bson.A
+		wantMatch bson.D
+	}{
+		{
+			name:      "achievement type",
+			pipeline:  achievementTypePipeline(ids),
+			wantMatch: bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}}},
+		},
+		{
+			name:     "competition level",
+			pipeline: competitionLevelPipeline(ids),
+			wantMatch: bson.D{
+				{Key: "_id", Value: bson.D{{Key: "$in", Value: ids}}},
+				{Key: "achievementType", Value: "competition"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, ok := tt.pipeline[0].(bson.D)
+			if !ok || len(stage) != 1 || stage[0].Key != "$match" {
+				t.Fatalf("first stage = %v, want $match", tt.pipeline[0])
+			}
+			// Tidak boleh lagi memfilter studentId: anggota tim tidak tercatat di dokumen
+			if !reflect.DeepEqual(stage[0].Value, tt.wantMatch) {
+				t.Fatalf("$match = %v, want %v", stage[0].Value, tt.wantMatch)
+			}
+		})
+	}
+}
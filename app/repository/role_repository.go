@@ -9,6 +9,9 @@ import (
 	"github.com/google/uuid"
 )
 
+// ErrRolePermissionNotFound - permission tidak terpasang di role
+var ErrRolePermissionNotFound = errors.New("permission not found for this role")
+
 type RoleRepository interface {
 	GetByID(id uuid.UUID) (*models.Role, error)
 	GetByName(name string) (*models.Role, error)
@@ -18,6 +21,11 @@ type RoleRepository interface {
 	GetPermissionNamesByRoleID(roleID uuid.UUID) ([]string, error)
 	AssignPermission(roleID, permissionID uuid.UUID) error
 	RemovePermission(roleID, permissionID uuid.UUID) error
+	Create(role models.Role) (uuid.UUID, error)
+	Update(role models.Role) error
+	Delete(id uuid.UUID) error
+	CountUsers(roleID uuid.UUID) (int, error)
+	CountRolesWithPermission(permissionName string) (int, error)
 }
 
 type roleRepo struct {
@@ -31,10 +39,10 @@ func NewRoleRepository(db *sql.DB) RoleRepository {
 func (r *roleRepo) GetByID(id uuid.UUID) (*models.Role, error) {
 	var role models.Role
 	err := r.DB.QueryRow(`
-		SELECT id, name, description, created_at
+		SELECT id, name, description, require_two_factor, created_at
 		FROM roles
 		WHERE id=$1
-	`, id).Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt)
+	`, id).Scan(&role.ID, &role.Name, &role.Description, &role.RequireTwoFactor, &role.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -47,10 +55,10 @@ func (r *roleRepo) GetByID(id uuid.UUID) (*models.Role, error) {
 func (r *roleRepo) GetByName(name string) (*models.Role, error) {
 	var role models.Role
 	err := r.DB.QueryRow(`
-		SELECT id, name, description, created_at
+		SELECT id, name, description, require_two_factor, created_at
 		FROM roles
 		WHERE name=$1
-	`, name).Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt)
+	`, name).Scan(&role.ID, &role.Name, &role.Description, &role.RequireTwoFactor, &role.CreatedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
@@ -70,7 +78,7 @@ func (r *roleRepo) GetAll(page, limit int) ([]models.Role, int, error) {
 	offset := (page - 1) * limit
 
 	rows, err := r.DB.Query(`
-		SELECT id, name, description, created_at
+		SELECT id, name, description, require_two_factor, created_at
 		FROM roles
 		ORDER BY name
 		LIMIT $1 OFFSET $2
@@ -83,7 +91,7 @@ func (r *roleRepo) GetAll(page, limit int) ([]models.Role, int, error) {
 	var roles []models.Role
 	for rows.Next() {
 		var role models.Role
-		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.CreatedAt); err != nil {
+		if err := rows.Scan(&role.ID, &role.Name, &role.Description, &role.RequireTwoFactor, &role.CreatedAt); err != nil {
 			return nil, 0, err
 		}
 		roles = append(roles, role)
@@ -218,8 +226,96 @@ func (r *roleRepo) RemovePermission(roleID, permissionID uuid.UUID) error {
 	}
 	
 	if rowsAffected == 0 {
-		return fmt.Errorf("permission not found for this role")
+		return ErrRolePermissionNotFound
 	}
 	
 	return nil
 }
+
+func (r *roleRepo) Create(role models.Role) (uuid.UUID, error) {
+	if role.ID == uuid.Nil {
+		role.ID = uuid.New()
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO roles (id, name, description, require_two_factor, created_at)
+		VALUES ($1, $2, $3, $4, NOW())
+	`, role.ID, role.Name, role.Description, role.RequireTwoFactor)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return role.ID, nil
+}
+
+func (r *roleRepo) Update(role models.Role) error {
+	result, err := r.DB.Exec(`
+		UPDATE roles
+		SET name=$1, description=$2, require_two_factor=$3
+		WHERE id=$4
+	`, role.Name, role.Description, role.RequireTwoFactor, role.ID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("role not found")
+	}
+	return nil
+}
+
+// Delete - hapus role beserta mapping permission-nya. Pengecekan user yang masih
+// memakai role dilakukan di service, FK users.role_id tetap jadi pengaman terakhir.
+func (r *roleRepo) Delete(id uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM role_permissions WHERE role_id=$1`, id); err != nil {
+		return fmt.Errorf("error removing role permissions: %w", err)
+	}
+
+	result, err := tx.Exec(`DELETE FROM roles WHERE id=$1`, id)
+	if err != nil {
+		return fmt.Errorf("error deleting role: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return errors.New("role not found")
+	}
+
+	return tx.Commit()
+}
+
+// CountUsers - jumlah user (aktif maupun non-aktif) yang memakai role ini
+func (r *roleRepo) CountUsers(roleID uuid.UUID) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`SELECT COUNT(*) FROM users WHERE role_id=$1`, roleID).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *roleRepo) CountRolesWithPermission(permissionName string) (int, error) {
+	var count int
+	err := r.DB.QueryRow(`
+		SELECT COUNT(DISTINCT rp.role_id)
+		FROM role_permissions rp
+		JOIN permissions p ON p.id = rp.permission_id
+		WHERE p.name = $1
+	`, permissionName).Scan(&count)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
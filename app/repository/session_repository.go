@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+type SessionRepository interface {
+	Create(session *models.UserSession) error
+	Touch(id uuid.UUID, ip, userAgent, accessJTI string, accessExpiresAt time.Time) error
+	GetByID(id uuid.UUID) (*models.UserSession, error)
+	GetByFamilyID(familyID uuid.UUID) (*models.UserSession, error)
+	GetActiveByUserID(userID uuid.UUID) ([]models.UserSession, error)
+}
+
+type sessionRepo struct {
+	DB *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) SessionRepository {
+	return &sessionRepo{DB: db}
+}
+
+const sessionColumns = `s.id, s.user_id, s.family_id, COALESCE(s.user_agent, ''), COALESCE(s.ip_address, ''),
+	COALESCE(s.access_jti, ''), COALESCE(s.access_expires_at, s.created_at), s.created_at, s.last_seen_at`
+
+func scanSession(row interface{ Scan(...interface{}) error }, s *models.UserSession) error {
+	return row.Scan(
+		&s.ID, &s.UserID, &s.FamilyID, &s.UserAgent, &s.IPAddress,
+		&s.AccessJTI, &s.AccessExpiresAt, &s.CreatedAt, &s.LastSeenAt,
+	)
+}
+
+func (r *sessionRepo) Create(session *models.UserSession) error {
+	if session.ID == uuid.Nil {
+		session.ID = uuid.New()
+	}
+	now := time.Now()
+	session.CreatedAt = now
+	session.LastSeenAt = now
+
+	_, err := r.DB.Exec(`
+		INSERT INTO user_sessions (id, user_id, family_id, user_agent, ip_address, access_jti, access_expires_at, created_at, last_seen_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`, session.ID, session.UserID, session.FamilyID, session.UserAgent, session.IPAddress,
+		session.AccessJTI, session.AccessExpiresAt, session.CreatedAt, session.LastSeenAt)
+	return err
+}
+
+// Touch - dipanggil setiap refresh: catat access token terbaru dan lokasi terakhir
+func (r *sessionRepo) Touch(id uuid.UUID, ip, userAgent, accessJTI string, accessExpiresAt time.Time) error {
+	_, err := r.DB.Exec(`
+		UPDATE user_sessions
+		SET ip_address = $1, user_agent = $2, access_jti = $3, access_expires_at = $4, last_seen_at = NOW()
+		WHERE id = $5
+	`, ip, userAgent, accessJTI, accessExpiresAt, id)
+	return err
+}
+
+func (r *sessionRepo) GetByID(id uuid.UUID) (*models.UserSession, error) {
+	var s models.UserSession
+	err := scanSession(r.DB.QueryRow(`
+		SELECT `+sessionColumns+`
+		FROM user_sessions s
+		WHERE s.id=$1
+	`, id), &s)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r *sessionRepo) GetByFamilyID(familyID uuid.UUID) (*models.UserSession, error) {
+	var s models.UserSession
+	err := scanSession(r.DB.QueryRow(`
+		SELECT `+sessionColumns+`
+		FROM user_sessions s
+		WHERE s.family_id=$1
+	`, familyID), &s)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
+// GetActiveByUserID - sesi aktif = family belum di-revoke dan masih punya refresh token yang bisa dipakai
+func (r *sessionRepo) GetActiveByUserID(userID uuid.UUID) ([]models.UserSession, error) {
+	rows, err := r.DB.Query(`
+		SELECT `+sessionColumns+`
+		FROM user_sessions s
+		JOIN refresh_token_families f ON f.id = s.family_id
+		WHERE s.user_id = $1
+		  AND f.revoked_at IS NULL
+		  AND EXISTS (
+			SELECT 1 FROM refresh_tokens t
+			WHERE t.family_id = s.family_id AND t.rotated_at IS NULL AND t.expires_at > NOW()
+		  )
+		ORDER BY s.last_seen_at DESC
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sessions := []models.UserSession{}
+	for rows.Next() {
+		var s models.UserSession
+		if err := scanSession(rows, &s); err != nil {
+			return nil, err
+		}
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rows.Err()
+}
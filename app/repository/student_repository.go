@@ -11,6 +11,7 @@ import (
 type StudentRepository interface {
 	GetByUserID(userID uuid.UUID) (*models.Student, error)
 	GetByID(id uuid.UUID) (*models.Student, error) 
+	GetByStudentID(studentID string) (*models.Student, error)
 	Create(student models.Student) (uuid.UUID, error)
 	GetAll() ([]models.Student, error)
 	GetAllByAdvisorID(advisorID string) ([]models.Student, error)
@@ -57,6 +58,22 @@ func (r *studentRepo) GetByID(id uuid.UUID) (*models.Student, error) {
 	return &s, nil
 }
 
+// GetByStudentID - cari berdasarkan NIM
+func (r *studentRepo) GetByStudentID(studentID string) (*models.Student, error) {
+	var s models.Student
+	err := r.DB.QueryRow(`
+		SELECT id, user_id, student_id, program_study, academic_year, advisor_id, created_at
+		FROM students WHERE student_id=$1
+	`, studentID).Scan(&s.ID, &s.UserID, &s.StudentID, &s.ProgramStudy, &s.AcademicYear, &s.AdvisorID, &s.CreatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &s, nil
+}
+
 func (r *studentRepo) Create(student models.Student) (uuid.UUID, error) {
 	if student.ID == uuid.Nil {
 		student.ID = uuid.New()
@@ -0,0 +1,230 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"sync"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+// negativeCacheTTL - berapa lama hasil "tidak di-revoke" disimpan di memory
+// sebelum dicek ulang ke database (supaya revoke dari instance lain tetap terbaca).
+const negativeCacheTTL = 30 * time.Second
+
+type TokenRevocationRepository interface {
+	RevokeToken(token *models.RevokedToken) error
+	// RevokeSession - tolak semua access token dengan klaim sid ini, selama umur access token
+	RevokeSession(sessionID, userID uuid.UUID, reason string, ttl time.Duration) error
+	IsRevoked(jti string) (bool, error)
+	RevokeAllForUser(revocation *models.UserTokenRevocation) error
+	GetUserRevokedBefore(userID uuid.UUID) (*time.Time, error)
+	DeleteExpired() (int64, error)
+
+	// IsTokenRevoked dipakai utils.ValidateToken / utils.ValidateRefreshToken
+	IsTokenRevoked(jti, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+}
+
+type cachedUserCutoff struct {
+	cutoff   *time.Time
+	loadedAt time.Time
+}
+
+type tokenRevocationRepo struct {
+	DB *sql.DB
+
+	mu          sync.RWMutex
+	revoked     map[string]time.Time // jti -> expires_at
+	notRevoked  map[string]time.Time // jti -> waktu dicek
+	userCutoffs map[uuid.UUID]cachedUserCutoff
+}
+
+func NewTokenRevocationRepository(db *sql.DB) TokenRevocationRepository {
+	return &tokenRevocationRepo{
+		DB:          db,
+		revoked:     make(map[string]time.Time),
+		notRevoked:  make(map[string]time.Time),
+		userCutoffs: make(map[uuid.UUID]cachedUserCutoff),
+	}
+}
+
+func (r *tokenRevocationRepo) RevokeToken(token *models.RevokedToken) error {
+	if token.JTI == "" {
+		return errors.New("token has no jti")
+	}
+	if token.RevokedAt.IsZero() {
+		token.RevokedAt = time.Now()
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO revoked_tokens (jti, user_id, token_type, reason, expires_at, revoked_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (jti) DO NOTHING
+	`, token.JTI, token.UserID, token.TokenType, token.Reason, token.ExpiresAt, token.RevokedAt)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.revoked[token.JTI] = token.ExpiresAt
+	delete(r.notRevoked, token.JTI)
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *tokenRevocationRepo) RevokeSession(sessionID, userID uuid.UUID, reason string, ttl time.Duration) error {
+	return r.RevokeToken(&models.RevokedToken{
+		JTI:       models.SessionRevocationKey(sessionID.String()),
+		UserID:    userID,
+		TokenType: models.TokenTypeSession,
+		Reason:    reason,
+		ExpiresAt: time.Now().Add(ttl),
+	})
+}
+
+func (r *tokenRevocationRepo) IsRevoked(jti string) (bool, error) {
+	now := time.Now()
+
+	r.mu.RLock()
+	expiresAt, revoked := r.revoked[jti]
+	checkedAt, checked := r.notRevoked[jti]
+	r.mu.RUnlock()
+
+	if revoked {
+		if now.After(expiresAt) {
+			r.mu.Lock()
+			delete(r.revoked, jti)
+			r.mu.Unlock()
+		}
+		return true, nil
+	}
+	if checked && now.Sub(checkedAt) < negativeCacheTTL {
+		return false, nil
+	}
+
+	err := r.DB.QueryRow(`
+		SELECT expires_at FROM revoked_tokens WHERE jti=$1
+	`, jti).Scan(&expiresAt)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		r.revoked[jti] = expiresAt
+		delete(r.notRevoked, jti)
+		return true, nil
+	}
+	r.notRevoked[jti] = now
+	return false, nil
+}
+
+func (r *tokenRevocationRepo) RevokeAllForUser(revocation *models.UserTokenRevocation) error {
+	if revocation.RevokedBefore.IsZero() {
+		revocation.RevokedBefore = time.Now()
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO user_token_revocations (user_id, revoked_before, revoked_by, reason)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id) DO UPDATE
+		SET revoked_before = EXCLUDED.revoked_before,
+		    revoked_by = EXCLUDED.revoked_by,
+		    reason = EXCLUDED.reason
+	`, revocation.UserID, revocation.RevokedBefore, revocation.RevokedBy, revocation.Reason)
+	if err != nil {
+		return err
+	}
+
+	cutoff := revocation.RevokedBefore
+	r.mu.Lock()
+	r.userCutoffs[revocation.UserID] = cachedUserCutoff{cutoff: &cutoff, loadedAt: time.Now()}
+	r.mu.Unlock()
+
+	return nil
+}
+
+func (r *tokenRevocationRepo) GetUserRevokedBefore(userID uuid.UUID) (*time.Time, error) {
+	r.mu.RLock()
+	cached, ok := r.userCutoffs[userID]
+	r.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < negativeCacheTTL {
+		return cached.cutoff, nil
+	}
+
+	var revokedBefore time.Time
+	err := r.DB.QueryRow(`
+		SELECT revoked_before FROM user_token_revocations WHERE user_id=$1
+	`, userID).Scan(&revokedBefore)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	var cutoff *time.Time
+	if err == nil {
+		cutoff = &revokedBefore
+	}
+
+	r.mu.Lock()
+	r.userCutoffs[userID] = cachedUserCutoff{cutoff: cutoff, loadedAt: time.Now()}
+	r.mu.Unlock()
+
+	return cutoff, nil
+}
+
+func (r *tokenRevocationRepo) DeleteExpired() (int64, error) {
+	result, err := r.DB.Exec(`DELETE FROM revoked_tokens WHERE expires_at < NOW()`)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	r.mu.Lock()
+	for jti, expiresAt := range r.revoked {
+		if now.After(expiresAt) {
+			delete(r.revoked, jti)
+		}
+	}
+	for jti, checkedAt := range r.notRevoked {
+		if now.Sub(checkedAt) >= negativeCacheTTL {
+			delete(r.notRevoked, jti)
+		}
+	}
+	r.mu.Unlock()
+
+	return result.RowsAffected()
+}
+
+func (r *tokenRevocationRepo) IsTokenRevoked(jti, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	if jti != "" {
+		revoked, err := r.IsRevoked(jti)
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	// Sesi yang di-revoke menolak semua access token-nya, bukan hanya yang terakhir diterbitkan
+	if sessionID != "" {
+		revoked, err := r.IsRevoked(models.SessionRevocationKey(sessionID))
+		if err != nil || revoked {
+			return revoked, err
+		}
+	}
+
+	cutoff, err := r.GetUserRevokedBefore(userID)
+	if err != nil {
+		return false, err
+	}
+
+	// iat di JWT hanya presisi detik, jadi dibandingkan per detik: token yang terbit
+	// pada detik yang sama dengan cutoff (mis. login ulang setelah ganti password) tetap valid
+	if cutoff != nil && issuedAt.Unix() < cutoff.Unix() {
+		return true, nil
+	}
+
+	return false, nil
+}
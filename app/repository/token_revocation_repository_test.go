@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+// cachedRepo - semua key sudah ada di cache, jadi tidak ada query ke database
+func cachedRepo(revoked, notRevoked []string, userID uuid.UUID, cutoff *time.Time) *tokenRevocationRepo {
+	now := time.Now()
+	r := &tokenRevocationRepo{
+		revoked:     map[string]time.Time{},
+		notRevoked:  map[string]time.Time{},
+		userCutoffs: map[uuid.UUID]cachedUserCutoff{userID: {cutoff: cutoff, loadedAt: now}},
+	}
+	for _, key := range revoked {
+		r.revoked[key] = now.Add(time.Hour)
+	}
+	for _, key := range notRevoked {
+		r.notRevoked[key] = now
+	}
+	return r
+}
+
+func TestIsTokenRevokedBySession(t *testing.T) {
+	userID := uuid.New()
+	revokedSession := uuid.NewString()
+	otherSession := uuid.NewString()
+	now := time.Now()
+
+	r := cachedRepo(
+		[]string{models.SessionRevocationKey(revokedSession)},
+		[]string{"old-jti", "new-jti", "other-jti", models.SessionRevocationKey(otherSession)},
+		userID, nil,
+	)
+
+	tests := []struct {
+		name      string
+		jti       string
+		sessionID string
+		want      bool
+	}{
+		// Token lama sebelum refresh merotasi access_jti tetap ditolak
+		{"older token of revoked session", "old-jti", revokedSession, true},
+		{"latest token of revoked session", "new-jti", revokedSession, true},
+		{"token of another session", "other-jti", otherSession, false},
+		{"token without session", "other-jti", "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.IsTokenRevoked(tt.jti, tt.sessionID, userID, now)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("revoked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsTokenRevokedByJTIAndUserCutoff(t *testing.T) {
+	userID := uuid.New()
+	cutoff := time.Now().Add(-time.Minute)
+
+	r := cachedRepo([]string{"revoked-jti"}, []string{"fresh-jti"}, userID, &cutoff)
+
+	tests := []struct {
+		name     string
+		jti      string
+		issuedAt time.Time
+		want     bool
+	}{
+		{"revoked jti", "revoked-jti", time.Now(), true},
+		{"issued before cutoff", "fresh-jti", cutoff.Add(-time.Hour), true},
+		{"issued after cutoff", "fresh-jti", time.Now(), false},
+		{"issued in the cutoff second", "fresh-jti", cutoff.Truncate(time.Second), false},
+		{"issued the second before cutoff", "fresh-jti", cutoff.Truncate(time.Second).Add(-time.Second), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := r.IsTokenRevoked(tt.jti, "", userID, tt.issuedAt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("revoked = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
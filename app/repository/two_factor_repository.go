@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+// ErrTwoFactorAlreadyEnabled - enrollment ulang harus disable dulu
+var ErrTwoFactorAlreadyEnabled = errors.New("two-factor authentication already enabled")
+
+type TwoFactorRepository interface {
+	Get(userID uuid.UUID) (*models.UserTwoFactor, error)
+	SaveEnrollment(userID uuid.UUID, secret string, recoveryCodeHashes []string) error
+	Enable(userID uuid.UUID) error
+	Disable(userID uuid.UUID) error
+
+	MarkStepUsed(userID uuid.UUID, step int64) (bool, error)
+	UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error)
+}
+
+type twoFactorRepo struct {
+	DB *sql.DB
+}
+
+func NewTwoFactorRepository(db *sql.DB) TwoFactorRepository {
+	return &twoFactorRepo{DB: db}
+}
+
+func (r *twoFactorRepo) Get(userID uuid.UUID) (*models.UserTwoFactor, error) {
+	var t models.UserTwoFactor
+	var enabledAt sql.NullTime
+
+	err := r.DB.QueryRow(`
+		SELECT user_id, secret, enabled, enabled_at, last_used_step, created_at, updated_at
+		FROM user_two_factor
+		WHERE user_id=$1
+	`, userID).Scan(&t.UserID, &t.Secret, &t.Enabled, &enabledAt, &t.LastUsedStep, &t.CreatedAt, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if enabledAt.Valid {
+		t.EnabledAt = &enabledAt.Time
+	}
+
+	return &t, nil
+}
+
+// SaveEnrollment - simpan secret baru (belum aktif) dan ganti semua recovery code
+func (r *twoFactorRepo) SaveEnrollment(userID uuid.UUID, secret string, recoveryCodeHashes []string) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO user_two_factor (user_id, secret, enabled, last_used_step, created_at, updated_at)
+		VALUES ($1, $2, false, 0, NOW(), NOW())
+		ON CONFLICT (user_id) DO UPDATE
+		SET secret = EXCLUDED.secret, last_used_step = 0, updated_at = NOW()
+		WHERE user_two_factor.enabled = false
+	`, userID, secret)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rowsAffected == 0 {
+		return ErrTwoFactorAlreadyEnabled
+	}
+
+	if _, err := tx.Exec(`DELETE FROM two_factor_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, hash := range recoveryCodeHashes {
+		_, err := tx.Exec(`
+			INSERT INTO two_factor_recovery_codes (id, user_id, code_hash, created_at)
+			VALUES ($1, $2, $3, $4)
+		`, uuid.New(), userID, hash, now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *twoFactorRepo) Enable(userID uuid.UUID) error {
+	_, err := r.DB.Exec(`
+		UPDATE user_two_factor
+		SET enabled = true, enabled_at = NOW(), updated_at = NOW()
+		WHERE user_id = $1
+	`, userID)
+	return err
+}
+
+func (r *twoFactorRepo) Disable(userID uuid.UUID) error {
+	tx, err := r.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`DELETE FROM two_factor_recovery_codes WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM user_two_factor WHERE user_id=$1`, userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// MarkStepUsed - false jika kode dengan time step ini (atau yang lebih baru) sudah pernah dipakai
+func (r *twoFactorRepo) MarkStepUsed(userID uuid.UUID, step int64) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE user_two_factor
+		SET last_used_step = $1, updated_at = NOW()
+		WHERE user_id = $2 AND last_used_step < $1
+	`, step, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
+
+func (r *twoFactorRepo) UseRecoveryCode(userID uuid.UUID, codeHash string) (bool, error) {
+	result, err := r.DB.Exec(`
+		UPDATE two_factor_recovery_codes
+		SET used_at = NOW()
+		WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+	`, userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rowsAffected > 0, nil
+}
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"database/sql"
+
+	"UAS/app/models"
+	"github.com/google/uuid"
+)
+
+// scopedStudentsQuery - id mahasiswa yang terlihat oleh user $1: mahasiswa bimbingannya
+// ditambah mahasiswa yang cocok dengan salah satu user_scopes miliknya
+const scopedStudentsQuery = `
+	SELECT s.id
+	FROM students s
+	LEFT JOIN lecturers l ON l.id = s.advisor_id
+	WHERE l.user_id = $1
+	   OR EXISTS (
+		SELECT 1 FROM user_scopes us
+		WHERE us.user_id = $1
+		  AND ((us.scope_type = 'program_study' AND us.scope_value = s.program_study)
+		    OR (us.scope_type = 'department' AND us.scope_value = l.department))
+	   )
+`
+
+type UserScopeRepository interface {
+	GetByUserID(userID uuid.UUID) ([]models.UserScope, error)
+	Create(scope models.UserScope) (uuid.UUID, error)
+	Delete(id, userID uuid.UUID) (bool, error)
+	GetScopedStudentIDs(userID uuid.UUID) ([]uuid.UUID, error)
+}
+
+type userScopeRepo struct {
+	DB *sql.DB
+}
+
+func NewUserScopeRepository(db *sql.DB) UserScopeRepository {
+	return &userScopeRepo{DB: db}
+}
+
+func (r *userScopeRepo) GetByUserID(userID uuid.UUID) ([]models.UserScope, error) {
+	rows, err := r.DB.Query(`
+		SELECT id, user_id, scope_type, scope_value, created_by, created_at
+		FROM user_scopes
+		WHERE user_id=$1
+		ORDER BY scope_type, scope_value
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var scopes []models.UserScope
+	for rows.Next() {
+		var s models.UserScope
+		if err := rows.Scan(&s.ID, &s.UserID, &s.ScopeType, &s.ScopeValue, &s.CreatedBy, &s.CreatedAt); err != nil {
+			return nil, err
+		}
+		scopes = append(scopes, s)
+	}
+	return scopes, nil
+}
+
+func (r *userScopeRepo) Create(scope models.UserScope) (uuid.UUID, error) {
+	if scope.ID == uuid.Nil {
+		scope.ID = uuid.New()
+	}
+
+	_, err := r.DB.Exec(`
+		INSERT INTO user_scopes (id, user_id, scope_type, scope_value, created_by, created_at)
+		VALUES ($1, $2, $3, $4, $5, NOW())
+	`, scope.ID, scope.UserID, scope.ScopeType, scope.ScopeValue, scope.CreatedBy)
+	if err != nil {
+		return uuid.Nil, err
+	}
+	return scope.ID, nil
+}
+
+func (r *userScopeRepo) Delete(id, userID uuid.UUID) (bool, error) {
+	result, err := r.DB.Exec(`DELETE FROM user_scopes WHERE id=$1 AND user_id=$2`, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+func (r *userScopeRepo) GetScopedStudentIDs(userID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.DB.Query(scopedStudentsQuery, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []uuid.UUID
+	for rows.Next() {
+		var id uuid.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
@@ -0,0 +1,146 @@
+package schema
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"UAS/app/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// huruf kecil, angka dan underscore, contoh: community_service
+var KeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+// ValidateDefinitions - cek definisi custom field dari admin
+func ValidateDefinitions(fields []models.CustomFieldDefinition) error {
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if !KeyPattern.MatchString(field.Key) {
+			return fmt.Errorf("invalid custom field key %q (lowercase letters, digits and underscore)", field.Key)
+		}
+		if seen[field.Key] {
+			return fmt.Errorf("duplicate custom field key %q", field.Key)
+		}
+		seen[field.Key] = true
+
+		if strings.TrimSpace(field.LabelID) == "" || strings.TrimSpace(field.LabelEN) == "" {
+			return fmt.Errorf("custom field %q needs both label_id and label_en", field.Key)
+		}
+		if !models.IsCustomFieldType(field.Type) {
+			return fmt.Errorf("custom field %q has invalid type %q (text, number, date, enum, url)", field.Key, field.Type)
+		}
+		if field.Type == models.CustomFieldEnum && len(field.Options) == 0 {
+			return fmt.Errorf("enum custom field %q needs options", field.Key)
+		}
+		if field.Type != models.CustomFieldEnum && len(field.Options) > 0 {
+			return fmt.Errorf("only enum custom fields can have options (%q)", field.Key)
+		}
+	}
+	return nil
+}
+
+// ValidateCustomFields - nilai custom_fields terhadap definisi tipe. Nilai bisa berasal
+// dari JSON request (string / float64) maupun dokumen MongoDB (int32, DateTime, ...).
+func ValidateCustomFields(definitions []models.CustomFieldDefinition, values map[string]interface{}, isNew bool) []FieldError {
+	var errs []FieldError
+	fieldError := func(key, code, message string) {
+		errs = append(errs, FieldError{Field: "details.custom_fields." + key, Code: code, Message: message})
+	}
+
+	defined := map[string]models.CustomFieldDefinition{}
+	for _, def := range definitions {
+		defined[def.Key] = def
+		if def.Required && !def.Retired && isEmptyValue(values[def.Key]) {
+			fieldError(def.Key, CodeRequired, fmt.Sprintf("%s is required", def.LabelEN))
+		}
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := values[key]
+		def, ok := defined[key]
+		if !ok {
+			fieldError(key, CodeForbidden, "unknown custom field for this achievement type")
+			continue
+		}
+		if def.Retired && isNew {
+			fieldError(key, CodeForbidden, fmt.Sprintf("%s is no longer used", def.LabelEN))
+			continue
+		}
+		if isEmptyValue(value) {
+			continue
+		}
+		if message := checkValue(def, value); message != "" {
+			fieldError(key, CodeInvalid, message)
+		}
+	}
+	return errs
+}
+
+func isEmptyValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	str, ok := value.(string)
+	return ok && strings.TrimSpace(str) == ""
+}
+
+func checkValue(def models.CustomFieldDefinition, value interface{}) string {
+	switch def.Type {
+	case models.CustomFieldText:
+		if _, ok := value.(string); !ok {
+			return fmt.Sprintf("%s must be text", def.LabelEN)
+		}
+	case models.CustomFieldNumber:
+		switch value.(type) {
+		case float64, float32, int, int32, int64:
+		default:
+			return fmt.Sprintf("%s must be a number", def.LabelEN)
+		}
+	case models.CustomFieldDate:
+		switch v := value.(type) {
+		case time.Time, primitive.DateTime:
+		case string:
+			if _, err := time.Parse("2006-01-02", v); err != nil {
+				if _, err := time.Parse(time.RFC3339, v); err != nil {
+					return fmt.Sprintf("%s must be a date (YYYY-MM-DD)", def.LabelEN)
+				}
+			}
+		default:
+			return fmt.Sprintf("%s must be a date (YYYY-MM-DD)", def.LabelEN)
+		}
+	case models.CustomFieldEnum:
+		str, ok := value.(string)
+		if !ok || !containsString(def.Options, str) {
+			return fmt.Sprintf("%s must be one of: %s", def.LabelEN, strings.Join(def.Options, ", "))
+		}
+	case models.CustomFieldURL:
+		str, ok := value.(string)
+		if !ok {
+			return fmt.Sprintf("%s must be a URL", def.LabelEN)
+		}
+		u, err := url.Parse(str)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Sprintf("%s must be an http(s) URL", def.LabelEN)
+		}
+	}
+	return ""
+}
+
+func containsString(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"UAS/app/models"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestValidateDefinitions(t *testing.T) {
+	field := func(key, fieldType string, options ...string) models.CustomFieldDefinition {
+		return models.CustomFieldDefinition{Key: key, LabelID: "Label", LabelEN: "Label", Type: fieldType, Options: options}
+	}
+
+	tests := []struct {
+		name    string
+		fields  []models.CustomFieldDefinition
+		wantErr bool
+	}{
+		{"valid", []models.CustomFieldDefinition{field("community_service", models.CustomFieldText), field("indexing", models.CustomFieldEnum, "scopus", "wos")}, false},
+		{"no fields", nil, false},
+		{"uppercase key", []models.CustomFieldDefinition{field("Indexing", models.CustomFieldText)}, true},
+		{"key starting with digit", []models.CustomFieldDefinition{field("1st", models.CustomFieldText)}, true},
+		{"duplicate key", []models.CustomFieldDefinition{field("a", models.CustomFieldText), field("a", models.CustomFieldNumber)}, true},
+		{"missing label", []models.CustomFieldDefinition{{Key: "a", LabelID: "A", Type: models.CustomFieldText}}, true},
+		{"unknown type", []models.CustomFieldDefinition{field("a", "boolean")}, true},
+		{"enum without options", []models.CustomFieldDefinition{field("a", models.CustomFieldEnum)}, true},
+		{"options on text", []models.CustomFieldDefinition{field("a", models.CustomFieldText, "x")}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDefinitions(tt.fields); (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateDefinitions() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateCustomFields(t *testing.T) {
+	definitions := []models.CustomFieldDefinition{
+		{Key: "indexing", LabelEN: "Indexing", Type: models.CustomFieldEnum, Options: []string{"scopus", "wos", "sinta1", "sinta2"}},
+		{Key: "team_size", LabelEN: "Team size", Type: models.CustomFieldNumber, Required: true},
+		{Key: "held_on", LabelEN: "Held on", Type: models.CustomFieldDate},
+		{Key: "link", LabelEN: "Link", Type: models.CustomFieldURL},
+		{Key: "note", LabelEN: "Note", Type: models.CustomFieldText},
+		{Key: "old", LabelEN: "Old", Type: models.CustomFieldText, Required: true, Retired: true},
+	}
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		isNew  bool
+		want   map[string]string // field -> code
+	}{
+		{"valid request values", map[string]interface{}{"indexing": "scopus", "team_size": float64(3), "held_on": "2024-05-01", "link": "https://example.com", "note": "x"}, true, map[string]string{}},
+		{"valid mongo values", map[string]interface{}{"team_size": int32(3), "held_on": primitive.NewDateTimeFromTime(time.Now())}, false, map[string]string{}},
+		{"rfc3339 date", map[string]interface{}{"team_size": float64(1), "held_on": "2024-05-01T00:00:00Z"}, true, map[string]string{}},
+		{"missing required", map[string]interface{}{}, true, map[string]string{"details.custom_fields.team_size": CodeRequired}},
+		{"blank required", map[string]interface{}{"team_size": " "}, true, map[string]string{"details.custom_fields.team_size": CodeRequired}},
+		{"enum outside options", map[string]interface{}{"team_size": float64(1), "indexing": "sinta3"}, true, map[string]string{"details.custom_fields.indexing": CodeInvalid}},
+		{"number as text", map[string]interface{}{"team_size": "3"}, true, map[string]string{"details.custom_fields.team_size": CodeInvalid}},
+		{"bad date", map[string]interface{}{"team_size": float64(1), "held_on": "01/05/2024"}, true, map[string]string{"details.custom_fields.held_on": CodeInvalid}},
+		{"non-http url", map[string]interface{}{"team_size": float64(1), "link": "ftp://example.com"}, true, map[string]string{"details.custom_fields.link": CodeInvalid}},
+		{"text as number", map[string]interface{}{"team_size": float64(1), "note": float64(1)}, true, map[string]string{"details.custom_fields.note": CodeInvalid}},
+		{"unknown key", map[string]interface{}{"team_size": float64(1), "extra": "x"}, true, map[string]string{"details.custom_fields.extra": CodeForbidden}},
+		{"retired key on new achievement", map[string]interface{}{"team_size": float64(1), "old": "x"}, true, map[string]string{"details.custom_fields.old": CodeForbidden}},
+		{"retired key on existing achievement", map[string]interface{}{"team_size": float64(1), "old": "x"}, false, map[string]string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(ValidateCustomFields(definitions, tt.values, tt.isNew))
+			if len(got) != len(tt.want) {
+				t.Fatalf("errors = %v, want %v", got, tt.want)
+			}
+			for field, code := range tt.want {
+				if got[field] != code {
+					t.Fatalf("errors = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package schema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"UAS/app/models"
+)
+
+// MergeDetails - terapkan details parsial dari request update ke details yang tersimpan.
+// Field yang tidak dikirim tetap; null atau "" mengosongkan field; period dan
+// custom_fields diganti seluruhnya. Field yang tidak dikenal diabaikan, sama seperti create.
+func MergeDetails(current models.AchievementDetails, patch map[string]interface{}) (models.AchievementDetails, []FieldError) {
+	// custom_fields tidak ikut round-trip JSON supaya tipe nilai dari MongoDB tetap utuh
+	customFields := current.CustomFields
+	current.CustomFields = nil
+
+	raw, err := json.Marshal(current)
+	if err != nil {
+		return current, []FieldError{{Field: "details", Code: CodeInvalid, Message: err.Error()}}
+	}
+	merged := map[string]interface{}{}
+	if err := json.Unmarshal(raw, &merged); err != nil {
+		return current, []FieldError{{Field: "details", Code: CodeInvalid, Message: err.Error()}}
+	}
+
+	for key, value := range patch {
+		if key == "custom_fields" {
+			continue
+		}
+		if value == nil || value == "" {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = value
+	}
+
+	if value, ok := patch["custom_fields"]; ok {
+		switch v := value.(type) {
+		case nil:
+			customFields = nil
+		case map[string]interface{}:
+			customFields = v
+		default:
+			return current, []FieldError{{Field: "details.custom_fields", Code: CodeInvalid, Message: "custom_fields must be an object"}}
+		}
+	}
+
+	raw, err = json.Marshal(merged)
+	if err != nil {
+		return current, []FieldError{{Field: "details", Code: CodeInvalid, Message: err.Error()}}
+	}
+	var result models.AchievementDetails
+	if err := json.Unmarshal(raw, &result); err != nil {
+		return current, []FieldError{decodeError(err)}
+	}
+	result.CustomFields = customFields
+	return result, nil
+}
+
+// decodeError - error json.Unmarshal sebagai FieldError dengan nama field-nya jika diketahui
+func decodeError(err error) FieldError {
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) && typeErr.Field != "" {
+		return FieldError{
+			Field:   "details." + typeErr.Field,
+			Code:    CodeInvalid,
+			Message: fmt.Sprintf("%s must be of type %s", typeErr.Field, typeErr.Type),
+		}
+	}
+	return FieldError{Field: "details", Code: CodeInvalid, Message: fmt.Sprintf("invalid details: %v", err)}
+}
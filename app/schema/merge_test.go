@@ -0,0 +1,158 @@
+package schema
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"UAS/app/models"
+)
+
+func TestMergeDetails(t *testing.T) {
+	date := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	current := models.AchievementDetails{
+		CompetitionName:  "Gemastik",
+		CompetitionLevel: "national",
+		Rank:             2,
+		MedalType:        "silver",
+		EventDate:        &date,
+		Location:         "Jakarta",
+		Organizer:        "Puspresnas",
+		Score:            80,
+		CustomFields:     map[string]interface{}{"team_size": int32(3)},
+	}
+
+	tests := []struct {
+		name    string
+		patch   map[string]interface{}
+		want    func(d *models.AchievementDetails)
+		wantErr string
+	}{
+		{
+			name:  "empty patch keeps everything",
+			patch: map[string]interface{}{},
+			want:  func(d *models.AchievementDetails) {},
+		},
+		{
+			name:  "sets only the sent fields",
+			patch: map[string]interface{}{"rank": float64(1), "medal_type": "gold"},
+			want: func(d *models.AchievementDetails) {
+				d.Rank = 1
+				d.MedalType = "gold"
+			},
+		},
+		{
+			name:  "null and empty string clear fields",
+			patch: map[string]interface{}{"location": "", "event_date": nil, "score": nil},
+			want: func(d *models.AchievementDetails) {
+				d.Location = ""
+				d.EventDate = nil
+				d.Score = 0
+			},
+		},
+		{
+			name:  "adds fields that were not set",
+			patch: map[string]interface{}{"authors": []interface{}{"A", "B"}, "period": map[string]interface{}{"start": "2024-01-01T00:00:00Z"}},
+			want: func(d *models.AchievementDetails) {
+				d.Authors = []string{"A", "B"}
+				d.Period = &models.Period{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+			},
+		},
+		{
+			name:  "custom_fields replaced as a whole",
+			patch: map[string]interface{}{"custom_fields": map[string]interface{}{"mentor": "Budi"}},
+			want: func(d *models.AchievementDetails) {
+				d.CustomFields = map[string]interface{}{"mentor": "Budi"}
+			},
+		},
+		{
+			name:  "custom_fields null clears them",
+			patch: map[string]interface{}{"custom_fields": nil},
+			want: func(d *models.AchievementDetails) {
+				d.CustomFields = nil
+			},
+		},
+		{
+			name:  "unknown field ignored",
+			patch: map[string]interface{}{"nickname": "x"},
+			want:  func(d *models.AchievementDetails) {},
+		},
+		{name: "wrong type", patch: map[string]interface{}{"rank": "first"}, wantErr: "details.rank"},
+		{name: "custom_fields not an object", patch: map[string]interface{}{"custom_fields": "x"}, wantErr: "details.custom_fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, errs := MergeDetails(current, tt.patch)
+			if tt.wantErr != "" {
+				if len(errs) != 1 || errs[0].Field != tt.wantErr {
+					t.Fatalf("errors = %+v, want one error on %s", errs, tt.wantErr)
+				}
+				return
+			}
+			if len(errs) > 0 {
+				t.Fatalf("unexpected errors: %+v", errs)
+			}
+
+			want := current
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got  %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestMergeDetailsCoversEveryField(t *testing.T) {
+	// Setiap field AchievementDetails harus bisa di-set dan dikosongkan lewat update
+	present := presentFields(models.AchievementDetails{})
+	if len(present) != 0 {
+		t.Fatalf("zero details reports present fields: %v", present)
+	}
+
+	date := "2024-05-01T00:00:00Z"
+	patch := map[string]interface{}{
+		"competition_name": "a", "competition_level": "national", "rank": float64(1), "medal_type": "gold",
+		"period": map[string]interface{}{"start": date}, "event_date": date, "location": "a", "organizer": "a",
+		"score": float64(1), "publication_type": "journal", "publication_title": "a",
+		"authors": []interface{}{"a"}, "publisher": "a", "issn": "2049-3630",
+		"organization_name": "a", "position": "a", "certification_name": "a", "issued_by": "a",
+		"certification_number": "a", "valid_until": date, "custom_fields": map[string]interface{}{"a": "b"},
+	}
+
+	set, errs := MergeDetails(models.AchievementDetails{}, patch)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	for field := range jsonFields() {
+		if !presentFields(set)[field] {
+			t.Errorf("%s was not set by the patch", field)
+		}
+	}
+
+	clear := map[string]interface{}{}
+	for field := range jsonFields() {
+		clear[field] = nil
+	}
+	cleared, errs := MergeDetails(set, clear)
+	if len(errs) > 0 {
+		t.Fatalf("unexpected errors: %+v", errs)
+	}
+	if present := presentFields(cleared); len(present) != 0 {
+		t.Fatalf("fields not cleared: %v", present)
+	}
+}
+
+// jsonFields - semua nama JSON field AchievementDetails
+func jsonFields() map[string]bool {
+	fields := map[string]bool{}
+	t := reflect.TypeOf(models.AchievementDetails{})
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name != "" && name != "-" {
+			fields[name] = true
+		}
+	}
+	return fields
+}
@@ -0,0 +1,200 @@
+package schema
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"UAS/app/models"
+)
+
+// FieldError - satu kesalahan validasi pada field details
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	CodeRequired  = "required"
+	CodeForbidden = "forbidden"
+	CodeInvalid   = "invalid"
+)
+
+// Schema - field AchievementDetails (nama JSON) yang wajib, boleh dan dilarang untuk satu tipe.
+// Field yang tidak disebut di mana pun dianggap boleh.
+type Schema struct {
+	Required  []string `json:"required"`
+	Optional  []string `json:"optional"`
+	Forbidden []string `json:"forbidden"`
+}
+
+// Registry - tipe prestasi -> Schema
+type Registry map[string]Schema
+
+var (
+	competitionFields   = []string{"competition_name", "competition_level", "rank", "medal_type"}
+	publicationFields   = []string{"publication_type", "publication_title", "authors", "publisher", "issn"}
+	organizationFields  = []string{"organization_name", "position"}
+	certificationFields = []string{"certification_name", "issued_by", "certification_number", "valid_until"}
+)
+
+func join(groups ...[]string) []string {
+	var fields []string
+	for _, group := range groups {
+		fields = append(fields, group...)
+	}
+	return fields
+}
+
+// DefaultRegistry - tipe prestasi bawaan
+func DefaultRegistry() Registry {
+	return Registry{
+		"academic": {
+			Optional:  []string{"rank", "score", "period", "event_date", "location", "organizer", "custom_fields"},
+			Forbidden: join(publicationFields, organizationFields, certificationFields),
+		},
+		"competition": {
+			Required:  []string{"competition_name", "competition_level"},
+			Optional:  []string{"rank", "medal_type", "score", "event_date", "location", "organizer", "custom_fields"},
+			Forbidden: join(publicationFields, organizationFields, certificationFields),
+		},
+		"organization": {
+			Required:  []string{"organization_name", "position", "period"},
+			Optional:  []string{"location", "custom_fields"},
+			Forbidden: join(competitionFields, publicationFields, certificationFields),
+		},
+		"publication": {
+			Required:  []string{"publication_title", "publication_type", "authors"},
+			Optional:  []string{"publisher", "issn", "event_date", "custom_fields"},
+			Forbidden: join(competitionFields, organizationFields, certificationFields),
+		},
+		"certification": {
+			Required:  []string{"certification_name", "issued_by"},
+			Optional:  []string{"certification_number", "valid_until", "event_date", "score", "custom_fields"},
+			Forbidden: join(competitionFields, publicationFields, organizationFields),
+		},
+		"other": {},
+	}
+}
+
+// CustomTypeSchema - schema untuk tipe buatan admin: field khusus tipe bawaan
+// tidak dipakai, data tambahan lewat custom_fields
+func CustomTypeSchema() Schema {
+	return Schema{
+		Optional:  []string{"period", "event_date", "location", "organizer", "score", "custom_fields"},
+		Forbidden: join(competitionFields, publicationFields, organizationFields, certificationFields),
+	}
+}
+
+// For - schema tipe bawaan, atau CustomTypeSchema untuk tipe buatan admin
+func (r Registry) For(achievementType string) Schema {
+	if schema, ok := r[achievementType]; ok {
+		return schema
+	}
+	return CustomTypeSchema()
+}
+
+// Validate - cek details terhadap schema tipe-nya, format field yang diisi dan
+// custom field sesuai definisi tipe. isNew untuk prestasi baru (custom field
+// yang retired tidak boleh dipakai lagi). Hasil kosong berarti valid.
+func (r Registry) Validate(achievementType *models.AchievementType, details models.AchievementDetails, isNew bool) []FieldError {
+	schema := r.For(achievementType.Code)
+	present := presentFields(details)
+	errs := []FieldError{}
+
+	for _, field := range schema.Required {
+		if !present[field] {
+			errs = append(errs, FieldError{
+				Field:   "details." + field,
+				Code:    CodeRequired,
+				Message: fmt.Sprintf("%s is required for %s achievements", field, achievementType.Code),
+			})
+		}
+	}
+	for _, field := range schema.Forbidden {
+		if present[field] {
+			errs = append(errs, FieldError{
+				Field:   "details." + field,
+				Code:    CodeForbidden,
+				Message: fmt.Sprintf("%s is not allowed for %s achievements", field, achievementType.Code),
+			})
+		}
+	}
+
+	errs = append(errs, validateFormats(details)...)
+	return append(errs, ValidateCustomFields(achievementType.Fields, details.CustomFields, isNew)...)
+}
+
+// presentFields - field details yang terisi (bukan zero value), dengan nama JSON
+func presentFields(details models.AchievementDetails) map[string]bool {
+	present := map[string]bool{}
+	v := reflect.ValueOf(details)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		if !v.Field(i).IsZero() {
+			present[name] = true
+		}
+	}
+	return present
+}
+
+func validateFormats(details models.AchievementDetails) []FieldError {
+	var errs []FieldError
+	invalid := func(field, message string) {
+		errs = append(errs, FieldError{Field: "details." + field, Code: CodeInvalid, Message: message})
+	}
+
+	if details.Rank < 0 {
+		invalid("rank", "rank must be greater than 0")
+	}
+	if details.Score < 0 {
+		invalid("score", "score must not be negative")
+	}
+	if details.ISSN != "" && !ValidISSN(details.ISSN) {
+		invalid("issn", "issn must be in the form NNNN-NNNC with a valid check digit")
+	}
+	for _, author := range details.Authors {
+		if strings.TrimSpace(author) == "" {
+			invalid("authors", "authors must not contain empty names")
+			break
+		}
+	}
+	if details.Period != nil {
+		switch {
+		case details.Period.Start.IsZero():
+			invalid("period.start", "period start is required")
+		case !details.Period.End.IsZero() && details.Period.End.Before(details.Period.Start):
+			invalid("period.end", "period end must not be before period start")
+		}
+	}
+
+	return errs
+}
+
+// ValidISSN - format NNNN-NNNC, check digit mod 11 (X = 10)
+func ValidISSN(issn string) bool {
+	if len(issn) != 9 || issn[4] != '-' {
+		return false
+	}
+	digits := issn[:4] + issn[5:]
+
+	sum := 0
+	for i := 0; i < 7; i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return false
+		}
+		sum += int(digits[i]-'0') * (8 - i)
+	}
+
+	check := (11 - sum%11) % 11
+	last := digits[7]
+	if check == 10 {
+		return last == 'X' || last == 'x'
+	}
+	return last == byte('0'+check)
+}
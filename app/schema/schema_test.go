@@ -0,0 +1,87 @@
+package schema
+
+import (
+	"testing"
+	"time"
+
+	"UAS/app/models"
+)
+
+func errorFields(errs []FieldError) map[string]string {
+	fields := map[string]string{}
+	for _, err := range errs {
+		fields[err.Field] = err.Code
+	}
+	return fields
+}
+
+func TestValidatePerType(t *testing.T) {
+	registry := DefaultRegistry()
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		achievementType string
+		details         models.AchievementDetails
+		want            map[string]string // field -> code, kosong = valid
+	}{
+		{"academic empty", "academic", models.AchievementDetails{}, map[string]string{}},
+		{"academic with publication field", "academic", models.AchievementDetails{Publisher: "IEEE"}, map[string]string{"details.publisher": CodeForbidden}},
+		{"competition valid", "competition", models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Rank: 1}, map[string]string{}},
+		{"competition missing required", "competition", models.AchievementDetails{Rank: 1}, map[string]string{
+			"details.competition_name":  CodeRequired,
+			"details.competition_level": CodeRequired,
+		}},
+		{"competition with organization field", "competition", models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Position: "Ketua"}, map[string]string{"details.position": CodeForbidden}},
+		{"organization valid", "organization", models.AchievementDetails{OrganizationName: "BEM", Position: "Ketua", Period: &models.Period{Start: start}}, map[string]string{}},
+		{"organization missing period", "organization", models.AchievementDetails{OrganizationName: "BEM", Position: "Ketua"}, map[string]string{"details.period": CodeRequired}},
+		{"organization period without start", "organization", models.AchievementDetails{OrganizationName: "BEM", Position: "Ketua", Period: &models.Period{End: start}}, map[string]string{"details.period.start": CodeInvalid}},
+		{"organization period end before start", "organization", models.AchievementDetails{OrganizationName: "BEM", Position: "Ketua", Period: &models.Period{Start: start, End: start.AddDate(0, 0, -1)}}, map[string]string{"details.period.end": CodeInvalid}},
+		{"publication valid", "publication", models.AchievementDetails{PublicationTitle: "Paper", PublicationType: "journal", Authors: []string{"A"}, ISSN: "2049-3630"}, map[string]string{}},
+		{"publication invalid issn", "publication", models.AchievementDetails{PublicationTitle: "Paper", PublicationType: "journal", Authors: []string{"A"}, ISSN: "2049-3631"}, map[string]string{"details.issn": CodeInvalid}},
+		{"publication blank author", "publication", models.AchievementDetails{PublicationTitle: "Paper", PublicationType: "journal", Authors: []string{"A", " "}}, map[string]string{"details.authors": CodeInvalid}},
+		{"certification valid", "certification", models.AchievementDetails{CertificationName: "CCNA", IssuedBy: "Cisco", CertificationNumber: "ABC-123"}, map[string]string{}},
+		{"certification with rank", "certification", models.AchievementDetails{CertificationName: "CCNA", IssuedBy: "Cisco", Rank: 1}, map[string]string{"details.rank": CodeForbidden}},
+		{"negative score", "other", models.AchievementDetails{Score: -1}, map[string]string{"details.score": CodeInvalid}},
+		{"negative rank", "academic", models.AchievementDetails{Rank: -1}, map[string]string{"details.rank": CodeInvalid}},
+		{"other allows anything", "other", models.AchievementDetails{CompetitionName: "X", Publisher: "Y"}, map[string]string{}},
+		{"custom type forbids built-in fields", "community_service", models.AchievementDetails{CompetitionName: "X", Location: "Malang"}, map[string]string{"details.competition_name": CodeForbidden}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errorFields(registry.Validate(&models.AchievementType{Code: tt.achievementType}, tt.details, true))
+			if len(got) != len(tt.want) {
+				t.Fatalf("errors = %v, want %v", got, tt.want)
+			}
+			for field, code := range tt.want {
+				if got[field] != code {
+					t.Fatalf("errors = %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestValidISSN(t *testing.T) {
+	tests := []struct {
+		issn string
+		want bool
+	}{
+		{"2049-3630", true},
+		{"0317-8471", true},
+		{"1050-124X", true},
+		{"1050-124x", true},
+		{"2049-3631", false},
+		{"20493630", false},
+		{"2049-363", false},
+		{"A049-3630", false},
+		{"", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidISSN(tt.issn); got != tt.want {
+			t.Errorf("ValidISSN(%q) = %v, want %v", tt.issn, got, tt.want)
+		}
+	}
+}
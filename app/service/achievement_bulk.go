@@ -0,0 +1,181 @@
+package service
+
+import (
+	"strings"
+
+	"UAS/app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// maxBulkItems - batas jumlah achievement per request bulk
+const maxBulkItems = 100
+
+// bulkResult - ringkasan hasil per item; setiap item diproses dalam transaksinya sendiri
+// sehingga kegagalan satu item tidak mempengaruhi item lain
+func bulkResult(c *fiber.Ctx, results []fiber.Map) error {
+	succeeded := 0
+	for _, result := range results {
+		if result["success"] == true {
+			succeeded++
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": succeeded == len(results),
+		"data": fiber.Map{
+			"total":     len(results),
+			"succeeded": succeeded,
+			"failed":    len(results) - succeeded,
+			"results":   results,
+		},
+	})
+}
+
+func bulkFailure(id string, failure *actionFailure) fiber.Map {
+	result := fiber.Map{
+		"id":      id,
+		"success": false,
+		"status":  failure.Status,
+	}
+	for key, value := range failure.Body {
+		result[key] = value
+	}
+	return result
+}
+
+// BulkVerifyAchievements godoc
+// @Summary Bulk approve achievements
+// @Description Approve the current verification stage of many submitted achievements at once (max 100). Every item goes through the same checks as POST /achievements/{id}/verify and is committed on its own, so one failing item does not affect the others. The response reports success or failure per item
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkVerifyRequest true "Achievement IDs and optional shared note"
+// @Success 200 {object} map[string]interface{} "Per-item results"
+// @Failure 400 {object} map[string]interface{} "Bad Request - No IDs or too many IDs"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /achievements/bulk/verify [post]
+func (s *AchievementService) BulkVerifyAchievements(c *fiber.Ctx) error {
+	var req models.BulkVerifyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	ids := uniqueIDs(req.IDs)
+	if len(ids) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "ids is required"})
+	}
+	if len(ids) > maxBulkItems {
+		return c.Status(400).JSON(fiber.Map{"error": "Too many achievements, maximum is 100 per request"})
+	}
+
+	note := strings.TrimSpace(req.Note)
+	results := make([]fiber.Map, 0, len(ids))
+	for _, id := range ids {
+		refUUID, err := uuid.Parse(id)
+		if err != nil {
+			results = append(results, bulkFailure(id, failAction(400, "Invalid achievement ID")))
+			continue
+		}
+
+		message, data, failure := s.approveAchievement(c, refUUID, note)
+		if failure != nil {
+			results = append(results, bulkFailure(id, failure))
+			continue
+		}
+		results = append(results, fiber.Map{
+			"id":      id,
+			"success": true,
+			"message": message,
+			"data":    data,
+		})
+	}
+
+	return bulkResult(c, results)
+}
+
+// BulkRejectAchievements godoc
+// @Summary Bulk reject achievements
+// @Description Reject many submitted achievements at once (max 100). Use ids with a shared rejection_note, and/or items with a rejection_note per achievement (a per-item note overrides the shared one). Every item goes through the same checks as POST /achievements/{id}/reject and is committed on its own. The response reports success or failure per item
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.BulkRejectRequest true "Achievements and rejection notes"
+// @Success 200 {object} map[string]interface{} "Per-item results"
+// @Failure 400 {object} map[string]interface{} "Bad Request - No IDs or too many IDs"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Router /achievements/bulk/reject [post]
+func (s *AchievementService) BulkRejectAchievements(c *fiber.Ctx) error {
+	var req models.BulkRejectRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	sharedNote := strings.TrimSpace(req.RejectionNote)
+	notes := map[string]string{}
+	ids := append([]string{}, req.IDs...)
+	for _, item := range req.Items {
+		ids = append(ids, item.ID)
+		if note := strings.TrimSpace(item.RejectionNote); note != "" {
+			notes[strings.TrimSpace(item.ID)] = note
+		}
+	}
+
+	ids = uniqueIDs(ids)
+	if len(ids) == 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "ids or items is required"})
+	}
+	if len(ids) > maxBulkItems {
+		return c.Status(400).JSON(fiber.Map{"error": "Too many achievements, maximum is 100 per request"})
+	}
+
+	results := make([]fiber.Map, 0, len(ids))
+	for _, id := range ids {
+		note, ok := notes[id]
+		if !ok {
+			note = sharedNote
+		}
+		if note == "" {
+			results = append(results, bulkFailure(id, failAction(400, "Rejection note is required")))
+			continue
+		}
+
+		refUUID, err := uuid.Parse(id)
+		if err != nil {
+			results = append(results, bulkFailure(id, failAction(400, "Invalid achievement ID")))
+			continue
+		}
+
+		data, failure := s.rejectAchievement(c, refUUID, note)
+		if failure != nil {
+			results = append(results, bulkFailure(id, failure))
+			continue
+		}
+		results = append(results, fiber.Map{
+			"id":      id,
+			"success": true,
+			"message": "Achievement rejected",
+			"data":    data,
+		})
+	}
+
+	return bulkResult(c, results)
+}
+
+// uniqueIDs - buang ID kosong & duplikat, urutan dipertahankan
+func uniqueIDs(ids []string) []string {
+	seen := map[string]bool{}
+	var unique []string
+	for _, id := range ids {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		unique = append(unique, id)
+	}
+	return unique
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"strings"
+	"time"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/config"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const maxCommentLength = 5000
+
+type AchievementCommentService struct {
+	commentRepo        repository.AchievementCommentRepository
+	achievementRefRepo repository.AchievementReferenceRepository
+	authorizer         *authz.Authorizer
+}
+
+func NewAchievementCommentService(
+	commentRepo repository.AchievementCommentRepository,
+	achievementRefRepo repository.AchievementReferenceRepository,
+	authorizer *authz.Authorizer,
+) *AchievementCommentService {
+	return &AchievementCommentService{
+		commentRepo:        commentRepo,
+		achievementRefRepo: achievementRefRepo,
+		authorizer:         authorizer,
+	}
+}
+
+// loadAchievement - load achievement dari :id dan cek aksi terhadapnya.
+// Status 0 berarti boleh lanjut.
+func (s *AchievementCommentService) loadAchievement(c *fiber.Ctx, action authz.Action) (*models.AchievementReference, *authz.Actor, authz.Resource, int, fiber.Map) {
+	refUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, nil, authz.Resource{}, 400, fiber.Map{"error": "Invalid achievement ID"}
+	}
+
+	ref, err := s.achievementRefRepo.GetReferenceByID(refUUID)
+	if err != nil {
+		return nil, nil, authz.Resource{}, 500, fiber.Map{"error": "Failed to get achievement", "details": err.Error()}
+	}
+	if ref == nil {
+		return nil, nil, authz.Resource{}, 404, fiber.Map{"error": "Achievement not found"}
+	}
+
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return nil, nil, authz.Resource{}, 500, fiber.Map{"error": "Failed to check access"}
+	}
+	resource, err := s.authorizer.AchievementResource(ref)
+	if err != nil {
+		return nil, nil, authz.Resource{}, 500, fiber.Map{"error": "Failed to check access"}
+	}
+	if !s.authorizer.Can(actor, action, resource) {
+		return nil, nil, authz.Resource{}, 403, fiber.Map{"error": "Access denied"}
+	}
+
+	return ref, actor, resource, 0, nil
+}
+
+// loadComment - komentar dari :commentId yang memang milik achievement ini
+func (s *AchievementCommentService) loadComment(c *fiber.Ctx, ref *models.AchievementReference) (*models.AchievementComment, int, fiber.Map) {
+	commentID, err := uuid.Parse(c.Params("commentId"))
+	if err != nil {
+		return nil, 400, fiber.Map{"error": "Invalid comment ID"}
+	}
+
+	comment, err := s.commentRepo.GetByID(commentID)
+	if err != nil {
+		return nil, 500, fiber.Map{"error": "Failed to get comment", "details": err.Error()}
+	}
+	if comment == nil || comment.AchievementRefID != ref.ID {
+		return nil, 404, fiber.Map{"error": "Comment not found"}
+	}
+	return comment, 0, nil
+}
+
+// validateCommentFields - field yang disebut harus field AchievementDetails
+func validateCommentFields(fields []string) ([]string, string) {
+	valid := models.AchievementDetailFields()
+	cleaned := []string{}
+	for _, field := range fields {
+		field = strings.TrimSpace(field)
+		if field == "" || contains(cleaned, field) {
+			continue
+		}
+		if !contains(valid, field) {
+			return nil, field
+		}
+		cleaned = append(cleaned, field)
+	}
+	return cleaned, ""
+}
+
+// isOwner - mahasiswa pemilik / anggota tim tidak bisa menandai "change requested" untuk prestasinya sendiri
+func (s *AchievementCommentService) isOwner(actor *authz.Actor, resource authz.Resource) bool {
+	return s.authorizer.Satisfies(actor, nil, []authz.Relation{authz.RelationOwner, authz.RelationMember}, resource)
+}
+
+// GetComments godoc
+// @Summary List achievement comments
+// @Description Discussion thread of an achievement, oldest first. Visible to everyone who can view the achievement
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Comments"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/comments [get]
+func (s *AchievementCommentService) GetComments(c *fiber.Ctx) error {
+	ref, actor, resource, status, body := s.loadAchievement(c, authz.ActionAchievementRead)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	comments, err := s.commentRepo.GetByAchievement(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get comments",
+			"details": err.Error(),
+		})
+	}
+	if comments == nil {
+		comments = []models.AchievementComment{}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"achievement_id": ref.ID,
+			"can_comment":    s.authorizer.Can(actor, authz.ActionAchievementComment, resource),
+			"comments":       comments,
+		},
+	})
+}
+
+// CreateComment godoc
+// @Summary Post a comment on an achievement
+// @Description Post to the discussion thread. Allowed for the owning student, the advisor and admins. Reviewers can mark a comment as change_requested, and any comment can mention fields of the achievement details (e.g. "competition_level")
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param request body models.CreateAchievementCommentRequest true "Comment"
+// @Success 201 {object} map[string]interface{} "Comment created"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Empty body or unknown field"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not allowed to comment"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/comments [post]
+func (s *AchievementCommentService) CreateComment(c *fiber.Ctx) error {
+	ref, actor, resource, status, body := s.loadAchievement(c, authz.ActionAchievementComment)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	var req models.CreateAchievementCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Comment body is required"})
+	}
+	if len(req.Body) > maxCommentLength {
+		return c.Status(400).JSON(fiber.Map{"error": "Comment is too long (max 5000 characters)"})
+	}
+
+	fields, invalid := validateCommentFields(req.Fields)
+	if invalid != "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error":        "Unknown achievement detail field: " + invalid,
+			"valid_fields": models.AchievementDetailFields(),
+		})
+	}
+
+	if req.ChangeRequested && s.isOwner(actor, resource) {
+		return c.Status(403).JSON(fiber.Map{"error": "Only reviewers can request changes"})
+	}
+
+	comment := &models.AchievementComment{
+		AchievementRefID: ref.ID,
+		AuthorID:         actor.User.ID,
+		Body:             req.Body,
+		ChangeRequested:  req.ChangeRequested,
+		Fields:           fields,
+	}
+	if err := s.commentRepo.Create(comment); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create comment",
+			"details": err.Error(),
+		})
+	}
+
+	comment.AuthorName = actor.User.FullName
+	comment.AuthorRole = actor.RoleName
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Comment created",
+		"data":    comment,
+	})
+}
+
+// UpdateComment godoc
+// @Summary Edit a comment
+// @Description Edit your own comment. Only possible within COMMENT_EDIT_WINDOW_MINUTES (default 15) after posting
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param commentId path string true "Comment ID (UUID)"
+// @Param request body models.UpdateAchievementCommentRequest true "Changes"
+// @Success 200 {object} map[string]interface{} "Comment updated"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Empty body or unknown field"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not the author or edit window passed"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/comments/{commentId} [put]
+func (s *AchievementCommentService) UpdateComment(c *fiber.Ctx) error {
+	ref, actor, resource, status, body := s.loadAchievement(c, authz.ActionAchievementComment)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	comment, status, body := s.loadComment(c, ref)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	if comment.AuthorID != actor.User.ID {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only edit your own comments"})
+	}
+	window := config.CommentEditWindow()
+	if time.Since(comment.CreatedAt) > window {
+		return c.Status(403).JSON(fiber.Map{
+			"error":               "Edit window has passed",
+			"edit_window_minutes": int(window.Minutes()),
+		})
+	}
+
+	var req models.UpdateAchievementCommentRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.Body != nil {
+		text := strings.TrimSpace(*req.Body)
+		if text == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Comment body is required"})
+		}
+		if len(text) > maxCommentLength {
+			return c.Status(400).JSON(fiber.Map{"error": "Comment is too long (max 5000 characters)"})
+		}
+		comment.Body = text
+	}
+	if req.Fields != nil {
+		fields, invalid := validateCommentFields(*req.Fields)
+		if invalid != "" {
+			return c.Status(400).JSON(fiber.Map{
+				"error":        "Unknown achievement detail field: " + invalid,
+				"valid_fields": models.AchievementDetailFields(),
+			})
+		}
+		comment.Fields = fields
+	}
+	if req.ChangeRequested != nil {
+		if *req.ChangeRequested && s.isOwner(actor, resource) {
+			return c.Status(403).JSON(fiber.Map{"error": "Only reviewers can request changes"})
+		}
+		comment.ChangeRequested = *req.ChangeRequested
+	}
+
+	if err := s.commentRepo.Update(comment); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update comment",
+			"details": err.Error(),
+		})
+	}
+	comment.Edited = true
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Comment updated",
+		"data":    comment,
+	})
+}
+
+// DeleteComment godoc
+// @Summary Delete a comment
+// @Description Delete a comment. Authors can delete their own comments; users who can comment on every achievement (admins) can delete any comment
+// @Tags Achievement Comments
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param commentId path string true "Comment ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Comment deleted"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not the author"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/comments/{commentId} [delete]
+func (s *AchievementCommentService) DeleteComment(c *fiber.Ctx) error {
+	ref, actor, _, status, body := s.loadAchievement(c, authz.ActionAchievementComment)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	comment, status, body := s.loadComment(c, ref)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	moderator := s.authorizer.Grants(actor, authz.ActionAchievementComment).Has(authz.RelationAny)
+	if comment.AuthorID != actor.User.ID && !moderator {
+		return c.Status(403).JSON(fiber.Map{"error": "You can only delete your own comments"})
+	}
+
+	if err := s.commentRepo.SoftDelete(comment.ID, actor.User.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to delete comment",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Comment deleted",
+	})
+}
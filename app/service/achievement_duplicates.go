@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"UAS/app/authz"
+	"UAS/app/duplicate"
+	"UAS/app/models"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// findDuplicates - prestasi lain (semua mahasiswa, kecuali yang sudah dihapus) yang
+// kemungkinan sama dengan achievement ini
+func (s *AchievementService) findDuplicates(ctx context.Context, achievement *models.Achievement) ([]models.DuplicateMatch, error) {
+	candidates, err := s.achievementRepo.FindDuplicateCandidates(
+		ctx,
+		achievement.ID,
+		achievement.Details.CertificationNumber,
+		duplicate.Hashes(achievement.Attachments),
+		achievement.Details.EventDate,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []models.DuplicateMatch{}
+	for i := range candidates {
+		reasons, similarity := duplicate.Compare(achievement, &candidates[i])
+		if len(reasons) == 0 {
+			continue
+		}
+
+		ref, err := s.achievementRefRepo.GetReferenceByMongoID(candidates[i].ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if ref == nil || ref.Status == models.AchievementStatusDeleted {
+			continue
+		}
+
+		match := models.DuplicateMatch{
+			AchievementID:   &ref.ID,
+			StudentID:       &ref.StudentID,
+			Title:           candidates[i].Title,
+			Status:          ref.Status,
+			Own:             ref.StudentID == achievement.StudentID,
+			Reasons:         reasons,
+			TitleSimilarity: math.Round(similarity*100) / 100,
+		}
+		if student, _ := s.studentRepo.GetByID(ref.StudentID); student != nil {
+			match.StudentNumber = student.StudentID
+			if studentUser, _ := s.userRepo.GetByID(student.UserID); studentUser != nil {
+				match.StudentName = studentUser.FullName
+			}
+		}
+		matches = append(matches, match)
+	}
+	return matches, nil
+}
+
+// studentDuplicateWarnings - peringatan untuk mahasiswa saat create / submit. Deteksi
+// duplikat tidak memblokir penyimpanan, jadi error cukup diabaikan.
+func (s *AchievementService) studentDuplicateWarnings(ctx context.Context, achievement *models.Achievement) fiber.Map {
+	matches, err := s.findDuplicates(ctx, achievement)
+	if err != nil || len(matches) == 0 {
+		return nil
+	}
+
+	// Prestasi mahasiswa lain: hanya alasan dan kemiripan, tanpa judul, status atau identitas pemilik
+	for i := range matches {
+		if !matches[i].Own {
+			matches[i] = models.DuplicateMatch{
+				Reasons:         matches[i].Reasons,
+				TitleSimilarity: matches[i].TitleSimilarity,
+			}
+		}
+	}
+
+	return fiber.Map{
+		"message": fmt.Sprintf(
+			"This achievement looks like %d existing achievement(s). If it is a team achievement, ask the owner to invite you as a member instead of submitting it again",
+			len(matches),
+		),
+		"possible_duplicates": matches,
+	}
+}
+
+// reviewerDuplicates - panel "possible duplicates" di detail achievement, hanya untuk
+// reviewer (bukan pemilik / anggota tim). nil berarti panel tidak ditampilkan.
+func (s *AchievementService) reviewerDuplicates(ctx context.Context, actor *authz.Actor, ref *models.AchievementReference, achievement *models.Achievement) []models.DuplicateMatch {
+	resource, err := s.authorizer.AchievementResource(ref)
+	if err != nil {
+		return nil
+	}
+	if s.authorizer.Satisfies(actor, nil, []authz.Relation{authz.RelationOwner, authz.RelationMember}, resource) {
+		return nil
+	}
+
+	matches, err := s.findDuplicates(ctx, achievement)
+	if err != nil {
+		return nil
+	}
+	return matches
+}
+
+// hashFile - SHA-256 (hex) dari isi file lampiran
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
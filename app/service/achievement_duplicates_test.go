@@ -0,0 +1,63 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"UAS/app/duplicate"
+	"UAS/app/models"
+
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestStudentDuplicateWarningsHideOtherStudentsAchievements(t *testing.T) {
+	f := newAchievementFixture("certification", models.AchievementDetails{CertificationName: "CCNA", IssuedBy: "Cisco", CertificationNumber: "ABC-123"})
+
+	// Prestasi lain milik mahasiswa yang sama dan milik mahasiswa lain, nomor sertifikat ditulis berbeda
+	addAchievement := func(studentID uuid.UUID, title, number string) {
+		achievement := &models.Achievement{
+			ID:              primitive.NewObjectID(),
+			StudentID:       studentID,
+			AchievementType: "certification",
+			Title:           title,
+			Details:         models.AchievementDetails{CertificationNumber: number},
+		}
+		f.achievements.achievements[achievement.ID.Hex()] = achievement
+		ref := &models.AchievementReference{ID: uuid.New(), StudentID: studentID, MongoAchievementID: achievement.ID.Hex(), Status: models.AchievementStatusVerified}
+		f.refRepo.refs[ref.ID] = ref
+	}
+	addAchievement(f.achievement.StudentID, "Own certificate", "abc 123")
+	addAchievement(uuid.New(), "Someone else's certificate", "ABC123")
+
+	warnings := f.svc.studentDuplicateWarnings(context.Background(), f.achievement)
+	matches, ok := warnings["possible_duplicates"].([]models.DuplicateMatch)
+	if !ok || len(matches) != 2 {
+		t.Fatalf("possible_duplicates = %v, want 2 matches", warnings["possible_duplicates"])
+	}
+
+	for _, match := range matches {
+		raw, err := json.Marshal(match)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var fields map[string]interface{}
+		if err := json.Unmarshal(raw, &fields); err != nil {
+			t.Fatal(err)
+		}
+
+		if match.Own {
+			if match.Title != "Own certificate" || match.AchievementID == nil || match.Status == "" {
+				t.Fatalf("own match lost its details: %s", raw)
+			}
+			continue
+		}
+		if len(fields) != 2 || fields["reasons"] == nil || fields["title_similarity"] == nil {
+			t.Fatalf("other student's match = %s, want only reasons and title_similarity", raw)
+		}
+		if len(match.Reasons) != 1 || match.Reasons[0] != duplicate.ReasonCertificationNumber {
+			t.Fatalf("reasons = %v, want [%s]", match.Reasons, duplicate.ReasonCertificationNumber)
+		}
+	}
+}
@@ -0,0 +1,563 @@
+package service
+
+import (
+	"strings"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type AchievementMemberService struct {
+	memberRepo         repository.AchievementMemberRepository
+	achievementRefRepo repository.AchievementReferenceRepository
+	studentRepo        repository.StudentRepository
+	authorizer         *authz.Authorizer
+}
+
+func NewAchievementMemberService(
+	memberRepo repository.AchievementMemberRepository,
+	achievementRefRepo repository.AchievementReferenceRepository,
+	studentRepo repository.StudentRepository,
+	authorizer *authz.Authorizer,
+) *AchievementMemberService {
+	return &AchievementMemberService{
+		memberRepo:         memberRepo,
+		achievementRefRepo: achievementRefRepo,
+		studentRepo:        studentRepo,
+		authorizer:         authorizer,
+	}
+}
+
+// loadAchievement - load achievement dari :id dan cek aksi terhadapnya.
+// Status 0 berarti boleh lanjut.
+func (s *AchievementMemberService) loadAchievement(c *fiber.Ctx, action authz.Action) (*models.AchievementReference, *authz.Actor, authz.Resource, int, fiber.Map) {
+	refUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, nil, authz.Resource{}, 400, fiber.Map{"error": "Invalid achievement ID"}
+	}
+
+	ref, err := s.achievementRefRepo.GetReferenceByID(refUUID)
+	if err != nil {
+		return nil, nil, authz.Resource{}, 500, fiber.Map{"error": "Failed to get achievement", "details": err.Error()}
+	}
+	if ref == nil || ref.Status == models.AchievementStatusDeleted {
+		return nil, nil, authz.Resource{}, 404, fiber.Map{"error": "Achievement not found"}
+	}
+
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return nil, nil, authz.Resource{}, 500, fiber.Map{"error": "Failed to check access"}
+	}
+	resource, err := s.authorizer.AchievementResource(ref)
+	if err != nil {
+		return nil, nil, authz.Resource{}, 500, fiber.Map{"error": "Failed to check access"}
+	}
+	if !s.authorizer.Can(actor, action, resource) {
+		return nil, nil, authz.Resource{}, 403, fiber.Map{"error": "Access denied"}
+	}
+
+	return ref, actor, resource, 0, nil
+}
+
+// loadMember - anggota dari :studentId pada achievement ini
+func (s *AchievementMemberService) loadMember(c *fiber.Ctx, ref *models.AchievementReference) (*models.AchievementMember, int, fiber.Map) {
+	studentID, err := uuid.Parse(c.Params("studentId"))
+	if err != nil {
+		return nil, 400, fiber.Map{"error": "Invalid student ID"}
+	}
+
+	member, err := s.memberRepo.Get(ref.ID, studentID)
+	if err != nil {
+		return nil, 500, fiber.Map{"error": "Failed to get member", "details": err.Error()}
+	}
+	if member == nil {
+		return nil, 404, fiber.Map{"error": "Member not found"}
+	}
+	return member, 0, nil
+}
+
+// findStudent - anggota bisa diundang lewat ID profil (UUID) atau NIM
+func (s *AchievementMemberService) findStudent(identifier string) (*models.Student, error) {
+	if id, err := uuid.Parse(identifier); err == nil {
+		return s.studentRepo.GetByID(id)
+	}
+	return s.studentRepo.GetByStudentID(identifier)
+}
+
+// withOwnerVerification - verifikasi pemilik mengikuti status prestasi itu sendiri
+func withOwnerVerification(ref *models.AchievementReference, members []models.AchievementMember) []models.AchievementMember {
+	if members == nil {
+		return []models.AchievementMember{}
+	}
+	for i := range members {
+		if !members[i].Owner {
+			continue
+		}
+		status := models.MemberVerificationPending
+		switch ref.Status {
+		case models.AchievementStatusVerified:
+			status = models.MemberVerificationVerified
+		case models.AchievementStatusRejected:
+			status = models.MemberVerificationRejected
+		}
+		members[i].VerificationStatus = &status
+		members[i].VerifiedBy = ref.VerifiedBy
+		members[i].VerifiedAt = ref.VerifiedAt
+		members[i].RejectionNote = ref.RejectionNote
+	}
+	return members
+}
+
+func validMemberRole(role string) bool {
+	return role == models.MemberRoleLeader || role == models.MemberRoleMember
+}
+
+// GetMembers godoc
+// @Summary List achievement team members
+// @Description Team of an achievement: the owning student plus invited co-members, with their role, invitation status and per-member verification. Visible to everyone who can view the achievement
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Members"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members [get]
+func (s *AchievementMemberService) GetMembers(c *fiber.Ctx) error {
+	ref, actor, resource, status, body := s.loadAchievement(c, authz.ActionAchievementRead)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	members, err := s.memberRepo.GetByAchievement(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get members",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"achievement_id": ref.ID,
+			"can_manage":     isEditableStatus(ref.Status) && s.authorizer.Can(actor, authz.ActionAchievementUpdate, resource),
+			"members":        withOwnerVerification(ref, members),
+		},
+	})
+}
+
+// InviteMember godoc
+// @Summary Invite a co-member to a team achievement
+// @Description Invite another student (by profile ID or student number) as leader or member. The invitee has to accept before the achievement counts for them. Only while the achievement is draft or rejected. Inviting a leader makes the current leader a member
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param request body models.InviteMemberRequest true "Invitation"
+// @Success 201 {object} map[string]interface{} "Member invited"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid role, unknown student or not editable"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Student is already on the team"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members [post]
+func (s *AchievementMemberService) InviteMember(c *fiber.Ctx) error {
+	ref, actor, _, status, body := s.loadAchievement(c, authz.ActionAchievementUpdate)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if !isEditableStatus(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Team can only be changed while the achievement is draft or rejected",
+		})
+	}
+
+	var req models.InviteMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	req.StudentID = strings.TrimSpace(req.StudentID)
+	if req.StudentID == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "student_id is required"})
+	}
+	if req.Role == "" {
+		req.Role = models.MemberRoleMember
+	}
+	if !validMemberRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Role must be leader or member"})
+	}
+
+	student, err := s.findStudent(req.StudentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get student",
+			"details": err.Error(),
+		})
+	}
+	if student == nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Student not found"})
+	}
+
+	existing, err := s.memberRepo.Get(ref.ID, student.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get member",
+			"details": err.Error(),
+		})
+	}
+	if existing != nil && existing.Status != models.MemberStatusDeclined {
+		return c.Status(409).JSON(fiber.Map{"error": "Student is already on the team"})
+	}
+
+	member := &models.AchievementMember{
+		AchievementRefID: ref.ID,
+		StudentID:        student.ID,
+		Role:             req.Role,
+		InvitedBy:        &actor.User.ID,
+	}
+	if err := s.memberRepo.Invite(member); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to invite member",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Member invited",
+		"data":    member,
+	})
+}
+
+// UpdateMember godoc
+// @Summary Change a team member's role
+// @Description Set a member's role to leader or member. A team has one leader, so promoting a member demotes the current leader. Only while the achievement is draft or rejected
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param studentId path string true "Student ID (UUID)"
+// @Param request body models.UpdateMemberRequest true "Role"
+// @Success 200 {object} map[string]interface{} "Role updated"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid role or not editable"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members/{studentId} [put]
+func (s *AchievementMemberService) UpdateMember(c *fiber.Ctx) error {
+	ref, _, _, status, body := s.loadAchievement(c, authz.ActionAchievementUpdate)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if !isEditableStatus(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Team can only be changed while the achievement is draft or rejected",
+		})
+	}
+
+	member, status, body := s.loadMember(c, ref)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+
+	var req models.UpdateMemberRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+	if !validMemberRole(req.Role) {
+		return c.Status(400).JSON(fiber.Map{"error": "Role must be leader or member"})
+	}
+
+	if err := s.memberRepo.UpdateRole(ref.ID, member.StudentID, req.Role); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update member",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Member role updated",
+	})
+}
+
+// RemoveMember godoc
+// @Summary Remove a member from a team achievement
+// @Description Remove a co-member or withdraw an invitation. The owning student cannot be removed. A co-member can also leave the team on their own. Only while the achievement is draft or rejected
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param studentId path string true "Student ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Member removed"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Owner cannot be removed or not editable"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members/{studentId} [delete]
+func (s *AchievementMemberService) RemoveMember(c *fiber.Ctx) error {
+	ref, actor, resource, status, body := s.loadAchievement(c, authz.ActionAchievementRead)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if !isEditableStatus(ref.Status) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Team can only be changed while the achievement is draft or rejected",
+		})
+	}
+
+	member, status, body := s.loadMember(c, ref)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if member.Owner {
+		return c.Status(400).JSON(fiber.Map{"error": "The owner cannot be removed from the team"})
+	}
+
+	// Anggota boleh keluar sendiri, selain itu perlu akses ubah prestasi.
+	// Keluar tetap penulisan, jadi API key harus punya permission ubah prestasi.
+	leaving := actor.StudentID != nil && *actor.StudentID == member.StudentID
+	if leaving && !actor.KeyAllows(authz.ActionAchievementUpdate) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+	if !leaving && !s.authorizer.Can(actor, authz.ActionAchievementUpdate, resource) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+
+	if err := s.memberRepo.Remove(ref.ID, member.StudentID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to remove member",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Member removed",
+	})
+}
+
+// RespondInvitation godoc
+// @Summary Accept or decline a team invitation
+// @Description The invited student confirms (accept=true) or declines their membership. The achievement only counts for a member after they accept and their own advisor verifies them
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param request body models.RespondInvitationRequest true "Answer"
+// @Success 200 {object} map[string]interface{} "Invitation answered"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found - No pending invitation"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members/respond [post]
+func (s *AchievementMemberService) RespondInvitation(c *fiber.Ctx) error {
+	ref, actor, _, status, body := s.loadAchievement(c, authz.ActionAchievementRead)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if actor.StudentID == nil {
+		return c.Status(403).JSON(fiber.Map{"error": "Only students can answer invitations"})
+	}
+	if !actor.KeyAllows(authz.ActionAchievementUpdate) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+
+	member, err := s.memberRepo.Get(ref.ID, *actor.StudentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get member",
+			"details": err.Error(),
+		})
+	}
+	if member == nil || member.Status != models.MemberStatusInvited {
+		return c.Status(404).JSON(fiber.Map{"error": "No pending invitation for this achievement"})
+	}
+
+	var req models.RespondInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	answer := models.MemberStatusDeclined
+	if req.Accept {
+		answer = models.MemberStatusAccepted
+	}
+	if err := s.memberRepo.Respond(ref.ID, member.StudentID, answer); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to answer invitation",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Invitation " + answer,
+		"data": fiber.Map{
+			"achievement_id": ref.ID,
+			"status":         answer,
+		},
+	})
+}
+
+// GetInvitations godoc
+// @Summary List my pending team invitations
+// @Description Team achievements the current student has been invited to and not answered yet
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Invitations"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not a student"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/invitations [get]
+func (s *AchievementMemberService) GetInvitations(c *fiber.Ctx) error {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if actor.StudentID == nil {
+		return c.Status(403).JSON(fiber.Map{"error": "Only students have team invitations"})
+	}
+
+	invitations, err := s.memberRepo.GetInvitations(*actor.StudentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get invitations",
+			"details": err.Error(),
+		})
+	}
+	if invitations == nil {
+		invitations = []models.AchievementMember{}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    invitations,
+	})
+}
+
+// VerifyMember godoc
+// @Summary Verify a co-member of a team achievement
+// @Description The co-member's own advisor (or an admin) confirms their participation. The owner is verified through the normal achievement workflow. Only for accepted members, once the achievement is submitted or verified
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param studentId path string true "Student ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Member verified"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Member cannot be verified yet"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not the member's advisor"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members/{studentId}/verify [post]
+func (s *AchievementMemberService) VerifyMember(c *fiber.Ctx) error {
+	return s.setMemberVerification(c, models.MemberVerificationVerified)
+}
+
+// RejectMember godoc
+// @Summary Reject a co-member of a team achievement
+// @Description The co-member's own advisor (or an admin) rejects their participation with a note. The achievement stays valid for the rest of the team
+// @Tags Achievement Members
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param studentId path string true "Student ID (UUID)"
+// @Param request body models.RejectMemberRequest true "Rejection note"
+// @Success 200 {object} map[string]interface{} "Member rejected"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Missing note or member cannot be verified yet"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not the member's advisor"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/members/{studentId}/reject [post]
+func (s *AchievementMemberService) RejectMember(c *fiber.Ctx) error {
+	return s.setMemberVerification(c, models.MemberVerificationRejected)
+}
+
+func (s *AchievementMemberService) setMemberVerification(c *fiber.Ctx, verification string) error {
+	ref, actor, _, status, body := s.loadAchievement(c, authz.ActionAchievementRead)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if ref.Status != models.AchievementStatusSubmitted && ref.Status != models.AchievementStatusVerified {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Members can only be verified after the achievement is submitted",
+		})
+	}
+
+	member, status, body := s.loadMember(c, ref)
+	if status != 0 {
+		return c.Status(status).JSON(body)
+	}
+	if member.Owner {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "The owner is verified through the achievement workflow",
+		})
+	}
+	if member.Status != models.MemberStatusAccepted {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Member has not accepted the invitation",
+		})
+	}
+
+	// Setiap anggota diverifikasi oleh dosen walinya sendiri
+	memberResource, err := s.authorizer.StudentResourceByID(member.StudentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if !s.authorizer.Satisfies(actor, []string{"Admin"}, []authz.Relation{authz.RelationAdvisor}, memberResource) {
+		return c.Status(403).JSON(fiber.Map{"error": "Only the member's advisor can verify this member"})
+	}
+
+	var note string
+	if verification == models.MemberVerificationRejected {
+		var req models.RejectMemberRequest
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+		}
+		note = strings.TrimSpace(req.RejectionNote)
+		if note == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "Rejection note is required"})
+		}
+	}
+
+	if err := s.memberRepo.SetVerification(ref.ID, member.StudentID, verification, actor.User.ID, note); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update member verification",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Member " + verification,
+		"data": fiber.Map{
+			"achievement_id":      ref.ID,
+			"student_id":          member.StudentID,
+			"verification_status": verification,
+		},
+	})
+}
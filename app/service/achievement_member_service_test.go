@@ -0,0 +1,71 @@
+package service
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestLeaveTeamRespectsAPIKeyPermissions(t *testing.T) {
+	tests := []struct {
+		name        string
+		apiKey      bool
+		permissions []string
+		wantStatus  int
+	}{
+		{"member login", false, []string{"achievement:read", "achievement:update"}, fiber.StatusOK},
+		{"read-only api key", true, []string{"achievement:read"}, fiber.StatusForbidden},
+		{"api key with achievement:update", true, []string{"achievement:read", "achievement:update"}, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			studentRole := &models.Role{ID: uuid.New(), Name: "Mahasiswa"}
+			ownerUser := &models.User{ID: uuid.New(), RoleID: studentRole.ID, IsActive: true}
+			memberUser := &models.User{ID: uuid.New(), RoleID: studentRole.ID, IsActive: true}
+			owner := &models.Student{ID: uuid.New(), UserID: ownerUser.ID}
+			member := &models.Student{ID: uuid.New(), UserID: memberUser.ID}
+			ref := &models.AchievementReference{ID: uuid.New(), StudentID: owner.ID, Status: models.AchievementStatusDraft}
+
+			roleRepo := &fakeRoleRepo{roles: map[uuid.UUID]*models.Role{studentRole.ID: studentRole}}
+			studentRepo := &fakeStudentRepo{students: map[uuid.UUID]*models.Student{owner.ID: owner, member.ID: member}}
+			memberRepo := &fakeMemberRepo{members: []models.AchievementMember{
+				{AchievementRefID: ref.ID, StudentID: owner.ID, Owner: true, Status: models.MemberStatusAccepted},
+				{AchievementRefID: ref.ID, StudentID: member.ID, Status: models.MemberStatusAccepted},
+			}}
+			refRepo := &fakeAchievementRefRepo{refs: map[uuid.UUID]*models.AchievementReference{ref.ID: ref}}
+			authorizer := authz.NewAuthorizer(roleRepo, studentRepo, &fakeLecturerRepo{}, &fakeUserScopeRepo{}, memberRepo)
+			svc := NewAchievementMemberService(memberRepo, refRepo, studentRepo, authorizer)
+
+			app := fiber.New()
+			// Meniru middleware.RequireAuth
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("user", memberUser)
+				c.Locals("user_id", memberUser.ID)
+				c.Locals("role_id", memberUser.RoleID)
+				c.Locals("permissions", tt.permissions)
+				if tt.apiKey {
+					c.Locals("api_key", &models.APIKey{ID: uuid.New(), UserID: memberUser.ID})
+				}
+				return c.Next()
+			})
+			app.Delete("/achievements/:id/members/:studentId", svc.RemoveMember)
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/achievements/"+ref.ID.String()+"/members/"+member.ID.String(), nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if left := len(memberRepo.members) == 1; left != (tt.wantStatus == fiber.StatusOK) {
+				t.Fatalf("member removed = %v, want %v", left, tt.wantStatus == fiber.StatusOK)
+			}
+		})
+	}
+}
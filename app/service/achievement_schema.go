@@ -0,0 +1,45 @@
+package service
+
+import (
+	"fmt"
+	"strings"
+
+	"UAS/app/models"
+	"UAS/app/schema"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// validateDetails - tipe harus terdaftar (dan belum retired untuk prestasi baru), lalu
+// details dicek terhadap schema tipe-nya. Prestasi lama dengan tipe yang sudah retired
+// tetap bisa diubah dan diajukan.
+func (s *AchievementService) validateDetails(achievementType string, details models.AchievementDetails, isNew bool) ([]schema.FieldError, error) {
+	t, err := s.achievementTypeRepo.GetByCode(achievementType)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil || (isNew && t.RetiredAt != nil) {
+		active, err := s.achievementTypeRepo.List(false)
+		if err != nil {
+			return nil, err
+		}
+		codes := make([]string, len(active))
+		for i, activeType := range active {
+			codes[i] = activeType.Code
+		}
+		return []schema.FieldError{{
+			Field:   "achievement_type",
+			Code:    schema.CodeInvalid,
+			Message: fmt.Sprintf("unknown or retired achievement type, valid types: %s", strings.Join(codes, ", ")),
+		}}, nil
+	}
+	return s.schemas.Validate(t, details, isNew), nil
+}
+
+// detailsInvalid - 400 dengan daftar error per field dari schema tipe prestasi
+func detailsInvalid(c *fiber.Ctx, errs []schema.FieldError) error {
+	return c.Status(400).JSON(fiber.Map{
+		"error":  "Achievement details validation failed",
+		"fields": errs,
+	})
+}
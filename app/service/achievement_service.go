@@ -2,14 +2,20 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
 	"os"
 	"path/filepath"
 	"strings"
 	"time"
 
+	"UAS/app/authz"
 	"UAS/app/models"
 	"UAS/app/repository"
+	"UAS/app/schema"
+	"UAS/app/workflow"
+	"UAS/config"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -17,29 +23,47 @@ import (
 )
 
 type AchievementService struct {
-	achievementRepo    repository.AchievementRepository
-	achievementRefRepo repository.AchievementReferenceRepository
-	studentRepo        repository.StudentRepository
-	lecturerRepo       repository.LecturerRepository
-	userRepo           repository.UserRepository
-	roleRepo           repository.RoleRepository
+	achievementRepo        repository.AchievementRepository
+	achievementRefRepo     repository.AchievementReferenceRepository
+	achievementVersionRepo repository.AchievementVersionRepository
+	studentRepo            repository.StudentRepository
+	lecturerRepo           repository.LecturerRepository
+	userRepo               repository.UserRepository
+	roleRepo               repository.RoleRepository
+	authorizer             *authz.Authorizer
+	workflows              workflow.Workflows
+	achievementTypeRepo    repository.AchievementTypeRepository
+	schemas                schema.Registry
+	pointsService          *PointsService
 }
 
 func NewAchievementService(
 	achievementRepo repository.AchievementRepository,
 	achievementRefRepo repository.AchievementReferenceRepository,
+	achievementVersionRepo repository.AchievementVersionRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
+	authorizer *authz.Authorizer,
+	workflows workflow.Workflows,
+	achievementTypeRepo repository.AchievementTypeRepository,
+	schemas schema.Registry,
+	pointsService *PointsService,
 ) *AchievementService {
 	return &AchievementService{
-		achievementRepo:    achievementRepo,
-		achievementRefRepo: achievementRefRepo,
-		studentRepo:        studentRepo,
-		lecturerRepo:       lecturerRepo,
-		userRepo:           userRepo,
-		roleRepo:           roleRepo,
+		achievementRepo:        achievementRepo,
+		achievementRefRepo:     achievementRefRepo,
+		achievementVersionRepo: achievementVersionRepo,
+		studentRepo:            studentRepo,
+		lecturerRepo:           lecturerRepo,
+		userRepo:               userRepo,
+		roleRepo:               roleRepo,
+		authorizer:             authorizer,
+		workflows:              workflows,
+		achievementTypeRepo:    achievementTypeRepo,
+		schemas:                schemas,
+		pointsService:          pointsService,
 	}
 }
 
@@ -53,6 +77,55 @@ func contains(slice []string, item string) bool {
 	return false
 }
 
+// displayPoints - poin final prestasi verified dibaca dari achievement_references (sumber
+// utama), selain itu estimasi yang tersimpan di MongoDB
+func displayPoints(ref *models.AchievementReference, achievement *models.Achievement) int {
+	if ref.Status == models.AchievementStatusVerified {
+		return ref.Points
+	}
+	return achievement.Points
+}
+
+// isEditableStatus - isi prestasi hanya boleh diubah saat draft atau setelah ditolak (untuk diajukan ulang)
+func isEditableStatus(status string) bool {
+	return status == models.AchievementStatusDraft || status == models.AchievementStatusRejected
+}
+
+// maxRequestIDLength - sesuai kolom achievement_status_events.request_id (VARCHAR(64))
+const maxRequestIDLength = 64
+
+// statusChange - siapa yang mengubah status dan request ID-nya, untuk achievement_status_events
+func statusChange(c *fiber.Ctx, note string) models.StatusChange {
+	change := models.StatusChange{Note: note}
+	if userID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		change.ChangedBy = userID
+	}
+	if requestID, ok := c.Locals("requestid").(string); ok {
+		// X-Request-ID dari client diteruskan apa adanya oleh middleware requestid;
+		// dipotong supaya header yang kepanjangan tidak menggagalkan transisi status
+		if runes := []rune(requestID); len(runes) > maxRequestIDLength {
+			requestID = string(runes[:maxRequestIDLength])
+		}
+		change.RequestID = requestID
+	}
+	return change
+}
+
+// authorizeAchievement - load actor lalu cek policy terhadap pemilik achievement
+func (s *AchievementService) authorizeAchievement(c *fiber.Ctx, action authz.Action, ref *models.AchievementReference) (*authz.Actor, bool, error) {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return nil, false, err
+	}
+
+	resource, err := s.authorizer.AchievementResource(ref)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return actor, s.authorizer.Can(actor, action, resource), nil
+}
+
 // @Summary Get all achievements
 // @Description Get list of achievements based on user role. Admin: all achievements, Dosen Wali: advisee's achievements, Mahasiswa: own achievements
 // @Tags Achievements
@@ -73,14 +146,9 @@ func contains(slice []string, item string) bool {
 func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 	ctx := context.Background()
 
-	// Get user dari middleware
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-
-	// Get user role
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user access"})
 	}
 
 	// Get query parameters
@@ -100,35 +168,37 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 	var references []models.AchievementReference
 	var total int
 
-	// Role-based access
-	switch userRole.Name {
-	case "Admin":
-		// Admin bisa lihat semua
+	// Scope list ditentukan policy: semua, mahasiswa bimbingan, atau milik sendiri
+	grants := s.authorizer.Grants(actor, authz.ActionAchievementList)
+	switch {
+	case grants.Has(authz.RelationAny):
 		offset := (page - 1) * limit
 		references, total, err = s.achievementRefRepo.GetAllReferences(status, limit, offset)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievements"})
 		}
 
-	case "Dosen Wali":
-		// Dosen hanya bisa lihat mahasiswa bimbingannya
-		lecturer, err := s.lecturerRepo.GetByUserID(userID)
-		if err != nil || lecturer == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "User is not a lecturer"})
+	case grants.Has(authz.RelationScope):
+		// Scope prodi / departemen sudah mencakup mahasiswa bimbingan
+		studentIDs, err := s.authorizer.ScopedStudentIDs(actor)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievements"})
 		}
-		references, err = s.achievementRefRepo.GetReferencesByAdvisor(lecturer.ID, status)
+		references, err = s.achievementRefRepo.GetReferencesByStudentIDs(studentIDs, status)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievements"})
 		}
 		total = len(references)
 
-	case "Mahasiswa":
-		// Mahasiswa hanya bisa lihat miliknya sendiri
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err != nil || student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "User is not a student"})
+	case grants.Has(authz.RelationAdvisor):
+		references, err = s.achievementRefRepo.GetReferencesByAdvisor(*actor.LecturerID, status)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievements"})
 		}
-		references, err = s.achievementRefRepo.GetReferencesByStudentID(student.ID, status)
+		total = len(references)
+
+	case grants.Has(authz.RelationOwner):
+		references, err = s.achievementRefRepo.GetReferencesByStudentID(*actor.StudentID, status)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievements"})
 		}
@@ -195,14 +265,15 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 
 			// Data minimal jika achievement tidak ditemukan di MongoDB
 			results = append(results, fiber.Map{
-				"id":           ref.ID,
-				"status":       ref.Status,
-				"title":        "Achievement data not available",
-				"type":         "unknown",
-				"points":       0,
-				"submitted_at": ref.SubmittedAt,
-				"verified_at":  ref.VerifiedAt,
-				"created_at":   ref.CreatedAt,
+				"id":            ref.ID,
+				"status":        ref.Status,
+				"current_stage": ref.WorkflowStage,
+				"title":         "Achievement data not available",
+				"type":          "unknown",
+				"points":        displayPoints(&ref, &models.Achievement{}),
+				"submitted_at":  ref.SubmittedAt,
+				"verified_at":   ref.VerifiedAt,
+				"created_at":    ref.CreatedAt,
 				"student": fiber.Map{
 					"id":         student.ID,
 					"name":       studentName,
@@ -247,14 +318,15 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 		}
 
 		results = append(results, fiber.Map{
-			"id":           ref.ID,
-			"status":       ref.Status,
-			"title":        achievement.Title,
-			"type":         achievement.AchievementType,
-			"points":       achievement.Points,
-			"submitted_at": ref.SubmittedAt,
-			"verified_at":  ref.VerifiedAt,
-			"created_at":   ref.CreatedAt,
+			"id":            ref.ID,
+			"status":        ref.Status,
+			"current_stage": ref.WorkflowStage,
+			"title":         achievement.Title,
+			"type":          achievement.AchievementType,
+			"points":        displayPoints(&ref, &achievement),
+			"submitted_at":  ref.SubmittedAt,
+			"verified_at":   ref.VerifiedAt,
+			"created_at":    ref.CreatedAt,
 			"student": fiber.Map{
 				"id":         student.ID,
 				"name":       studentName,
@@ -299,7 +371,7 @@ func (s *AchievementService) GetAllAchievements(c *fiber.Ctx) error {
 
 // GetAchievementByID godoc
 // @Summary Get achievement by ID
-// @Description Get achievement details by ID. Access based on role: Admin: all, Dosen Wali: advisee's, Mahasiswa: own
+// @Description Get achievement details by ID. Access based on role: Admin: all, Dosen Wali: advisee's, Mahasiswa: own. Reviewers also get possible_duplicates: other achievements with the same certification number, the same attachment file, or a similar title on the same event date by the same organizer
 // @Tags Achievements
 // @Accept json
 // @Produce json
@@ -340,33 +412,11 @@ func (s *AchievementService) GetAchievementByID(c *fiber.Ctx) error {
 	}
 
 	// 3. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
-	}
-
-	canAccess := false
-	switch userRole.Name {
-	case "Admin":
-		canAccess = true
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err == nil && student != nil && student.ID == ref.StudentID {
-			canAccess = true
-		}
-	case "Dosen Wali":
-		lecturer, err := s.lecturerRepo.GetByUserID(userID)
-		if err == nil && lecturer != nil {
-			student, err := s.studentRepo.GetByID(ref.StudentID)
-			if err == nil && student != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID {
-				canAccess = true
-			}
-		}
+	actor, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementRead, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
 	}
-
-	if !canAccess {
+	if !allowed {
 		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
@@ -416,18 +466,26 @@ func (s *AchievementService) GetAchievementByID(c *fiber.Ctx) error {
 			"achievement_type": achievement.AchievementType,
 			"title":            achievement.Title,
 			"description":      achievement.Description,
-			"points":           achievement.Points,
+			"points":           displayPoints(ref, achievement),
 			"tags":             achievement.Tags,
 			"details":          achievement.Details,
 			"attachments":      achievement.Attachments,
 
 			// Status info dari PostgreSQL
 			"status":         ref.Status,
+			"current_stage":  ref.WorkflowStage,
+			"revision":       ref.Revision,
 			"submitted_at":   ref.SubmittedAt,
 			"verified_at":    ref.VerifiedAt,
 			"verified_by":    verifiedByInfo,
 			"rejection_note": ref.RejectionNote,
 
+			// Versi aturan poin final, null selama belum verified
+			"points_rule_version": ref.PointsRuleVersion,
+
+			// Kemungkinan duplikat untuk reviewer, null untuk pemilik / anggota tim
+			"possible_duplicates": s.reviewerDuplicates(ctx, actor, ref, achievement),
+
 			// Student info
 			"student":    studentInfo,
 			"student_id": ref.StudentID,
@@ -441,14 +499,14 @@ func (s *AchievementService) GetAchievementByID(c *fiber.Ctx) error {
 
 // CreateAchievement godoc
 // @Summary Create new achievement
-// @Description Create new achievement. Mahasiswa: only for themselves, Admin: for any student (require student_id), Dosen Wali: cannot create
+// @Description Create new achievement. Mahasiswa: only for themselves, Admin: for any student (require student_id), Dosen Wali: cannot create. Points are estimated by the server from the points rules. Possible duplicates are returned as non-blocking warnings
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body models.CreateAchievementRequest true "Achievement data"
 // @Success 201 {object} map[string]interface{} "Achievement created successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid data or missing fields"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid data, missing fields or details fail the type schema (see fields)"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Role not allowed"
 // @Failure 404 {object} map[string]interface{} "Not Found - Student not found (for admin)"
@@ -461,11 +519,10 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 	userID := c.Locals("user_id").(uuid.UUID)
 	user := c.Locals("user").(*models.User)
 
-	// Get user role
-	role, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || role == nil {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
-			"error": "Failed to get user role",
+			"error": "Failed to get user access",
 		})
 	}
 
@@ -491,105 +548,94 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	// Validate achievement type
-	validTypes := []string{"academic", "competition", "organization", "publication", "certification", "other"}
-	if !contains(validTypes, req.AchievementType) {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Invalid achievement type. Valid types: %v", validTypes),
+	// Validate achievement type & details sesuai schema tipe-nya
+	if errs, err := s.validateDetails(req.AchievementType, req.Details, true); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement type",
+			"details": err.Error(),
 		})
+	} else if len(errs) > 0 {
+		return detailsInvalid(c, errs)
 	}
 
 	var studentID uuid.UUID
 	var studentName string
 
-	// Determine target student ID based on role
-	switch role.Name {
-	case "Mahasiswa":
-		// Mahasiswa can only create achievements for themselves
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err != nil {
-			return c.Status(500).JSON(fiber.Map{
-				"error":   "Failed to get student profile",
-				"details": err.Error(),
-			})
-		}
-		
-		if student == nil {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "User is not a student or student profile not found",
-			})
-		}
-		
-		studentID = student.ID
-		
-		// Get student name from user
-		studentUser, _ := s.userRepo.GetByID(student.UserID)
-		if studentUser != nil {
-			studentName = studentUser.FullName
-		}
+	// Tentukan mahasiswa target: yang boleh membuat untuk siapa saja wajib
+	// menyertakan student_id, selain itu selalu untuk dirinya sendiri
+	var targetReq struct {
+		StudentID *string `json:"student_id,omitempty"`
+	}
+	if err := c.BodyParser(&targetReq); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+	}
 
-	case "Admin":
-		// Admin perlu menyertakan student_id di body
-		var adminReq struct {
-			models.CreateAchievementRequest
-			StudentID *string `json:"student_id,omitempty"` // Changed to string for parsing
-		}
-		
-		if err := c.BodyParser(&adminReq); err != nil {
-			return c.Status(400).JSON(fiber.Map{
-				"error":   "Invalid request body for admin",
-				"details": err.Error(),
-			})
-		}
-		
-		if adminReq.StudentID == nil || *adminReq.StudentID == "" {
+	var student *models.Student
+	switch grants := s.authorizer.Grants(actor, authz.ActionAchievementCreate); {
+	case grants.Has(authz.RelationAny):
+		if targetReq.StudentID == nil || *targetReq.StudentID == "" {
 			return c.Status(400).JSON(fiber.Map{
-				"error": "student_id is required when creating achievement as admin",
+				"error": "student_id is required when creating achievement for another student",
 			})
 		}
-		
-		// Parse student_id string to UUID
-		parsedStudentID, err := uuid.Parse(*adminReq.StudentID)
+
+		parsedStudentID, err := uuid.Parse(*targetReq.StudentID)
 		if err != nil {
 			return c.Status(400).JSON(fiber.Map{
 				"error": "Invalid student_id format",
 			})
 		}
-		
-		student, err := s.studentRepo.GetByID(parsedStudentID)
+
+		student, err = s.studentRepo.GetByID(parsedStudentID)
 		if err != nil {
 			return c.Status(500).JSON(fiber.Map{
 				"error":   "Failed to get student",
 				"details": err.Error(),
 			})
 		}
-		
 		if student == nil {
 			return c.Status(404).JSON(fiber.Map{
 				"error": "Student not found",
 			})
 		}
-		
-		studentID = parsedStudentID
-		req = adminReq.CreateAchievementRequest
-		
-		// Get student name
-		studentUser, _ := s.userRepo.GetByID(student.UserID)
-		if studentUser != nil {
-			studentName = studentUser.FullName
-		}
 
-	case "Dosen Wali":
-		return c.Status(403).JSON(fiber.Map{
-			"error": "Dosen Wali cannot create achievements",
-		})
+	case grants.Has(authz.RelationOwner):
+		student, err = s.studentRepo.GetByID(*actor.StudentID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Failed to get student profile",
+				"details": err.Error(),
+			})
+		}
+		if student == nil {
+			return c.Status(403).JSON(fiber.Map{
+				"error": "User is not a student or student profile not found",
+			})
+		}
 
 	default:
 		return c.Status(403).JSON(fiber.Map{
-			"error": "Unauthorized role: " + role.Name,
+			"error": "You are not allowed to create achievements",
 		})
 	}
 
+	resource, err := s.authorizer.StudentResource(student)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if !s.authorizer.Can(actor, authz.ActionAchievementCreate, resource) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+
+	studentID = student.ID
+	studentUser, _ := s.userRepo.GetByID(student.UserID)
+	if studentUser != nil {
+		studentName = studentUser.FullName
+	}
+
 	// Initialize Attachments slice if nil
 	if req.Attachments == nil {
 		req.Attachments = []models.Attachment{}
@@ -610,11 +656,20 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 		Details:         req.Details,
 		Attachments:     req.Attachments,
 		Tags:            req.Tags,
-		Points:          req.Points,
 		CreatedAt:       time.Now(),
 		UpdatedAt:       time.Now(),
 	}
 
+	// Poin sementara dari aturan aktif, poin final dihitung saat verifikasi
+	estimate, err := s.pointsService.Calculate(achievement)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to calculate points",
+			"details": err.Error(),
+		})
+	}
+	achievement.Points = estimate.Points
+
 	// 1. Simpan ke MongoDB dulu
 	mongoID, err := s.achievementRepo.CreateAchievement(ctx, achievement)
 	if err != nil {
@@ -636,7 +691,7 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 	}
 
 	// Create reference in PostgreSQL
-	if err := s.achievementRefRepo.CreateReference(ref); err != nil {
+	if err := s.achievementRefRepo.CreateReference(ref, statusChange(c, "Achievement created")); err != nil {
 		// Rollback: delete from MongoDB
 		_ = s.achievementRepo.DeleteAchievement(ctx, mongoID)
 		return c.Status(500).JSON(fiber.Map{
@@ -644,6 +699,7 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 			"details": err.Error(),
 		})
 	}
+	versionWarning := s.recordVersion(ctx, c, ref)
 
 	return c.Status(201).JSON(fiber.Map{
 		"success": true,
@@ -657,18 +713,19 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 			"title":             req.Title,
 			"description":       req.Description,
 			"status":            ref.Status,
-			"points":            req.Points,
+			"points":            achievement.Points,
 			"created_at":        ref.CreatedAt,
 			"created_by":        userID,
 			"created_by_name":   user.FullName,
 		},
+		"warnings": mergeWarnings(s.studentDuplicateWarnings(ctx, achievement), versionWarning),
 	})
 }
 
 // ==================== 4. UPDATE ACHIEVEMENT ====================
 // UpdateAchievement godoc
 // @Summary Update achievement
-// @Description Update achievement. Only draft achievements can be updated. Mahasiswa: own achievements only, Admin: all achievements
+// @Description Update achievement. Only draft or rejected achievements can be updated; a rejected achievement is then sent back with /resubmit. Details are merged with the stored details: fields that are not sent stay unchanged, null or "" clears a field, period and custom_fields are replaced as a whole. Mahasiswa: own achievements only, Admin: all achievements
 // @Tags Achievements
 // @Accept json
 // @Produce json
@@ -676,7 +733,7 @@ func (s *AchievementService) CreateAchievement(c *fiber.Ctx) error {
 // @Param id path string true "Achievement ID (UUID)"
 // @Param request body map[string]interface{} true "Update data"
 // @Success 200 {object} map[string]interface{} "Achievement updated successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID or not draft status"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID, not draft/rejected status or details fail the type schema (see fields)"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Not owner or role not allowed"
 // @Failure 404 {object} map[string]interface{} "Not Found"
@@ -698,33 +755,18 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 	}
 
 	// 2. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementUpdate, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
 	}
-
-	// Ownership check
-	switch userRole.Name {
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err != nil || student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "Student not found"})
-		}
-		if student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
-	case "Admin":
-		// Admin bisa update semua
-	default:
-		return c.Status(403).JSON(fiber.Map{"error": "Unauthorized role"})
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
 	}
 
-	// 3. Status check (hanya draft yang bisa diupdate)
-	if ref.Status != models.AchievementStatusDraft {
+	// 3. Status check (draft, atau rejected yang akan diajukan ulang)
+	if !isEditableStatus(ref.Status) {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Only draft achievements can be updated",
+			"error": "Only draft or rejected achievements can be updated",
 		})
 	}
 
@@ -733,6 +775,7 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 	if err != nil || achievement == nil {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement details not found"})
 	}
+	s.ensureBaselineVersion(ctx, ref, achievement)
 
 	// 5. Parse request body
 	var req map[string]interface{}
@@ -747,9 +790,6 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 	if description, ok := req["description"].(string); ok {
 		achievement.Description = description
 	}
-	if points, ok := req["points"].(float64); ok {
-		achievement.Points = int(points)
-	}
 	if tags, ok := req["tags"].([]interface{}); ok && len(tags) > 0 {
 		var newTags []string
 		for _, tag := range tags {
@@ -762,58 +802,46 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 		}
 	}
 
-	// 7. Update details jika ada
+	// 7. Update details jika ada: field yang tidak dikirim tetap, null / "" mengosongkan
 	if details, ok := req["details"].(map[string]interface{}); ok {
-		// Update competition fields
-		if compName, ok := details["competition_name"].(string); ok && compName != "" {
-			achievement.Details.CompetitionName = compName
-		}
-		if compLevel, ok := details["competition_level"].(string); ok && compLevel != "" {
-			achievement.Details.CompetitionLevel = compLevel
-		}
-		if rank, ok := details["rank"].(float64); ok {
-			achievement.Details.Rank = int(rank)
-		}
-		if medalType, ok := details["medal_type"].(string); ok && medalType != "" {
-			achievement.Details.MedalType = medalType
+		merged, errs := schema.MergeDetails(achievement.Details, details)
+		if len(errs) > 0 {
+			return detailsInvalid(c, errs)
 		}
+		achievement.Details = merged
+	}
 
-		// Update publication fields
-		if pubType, ok := details["publication_type"].(string); ok && pubType != "" {
-			achievement.Details.PublicationType = pubType
-		}
-		if pubTitle, ok := details["publication_title"].(string); ok && pubTitle != "" {
-			achievement.Details.PublicationTitle = pubTitle
-		}
-		if authors, ok := details["authors"].([]interface{}); ok && len(authors) > 0 {
-			var authorList []string
-			for _, author := range authors {
-				if str, ok := author.(string); ok {
-					authorList = append(authorList, str)
-				}
-			}
-			achievement.Details.Authors = authorList
-		}
-		if publisher, ok := details["publisher"].(string); ok && publisher != "" {
-			achievement.Details.Publisher = publisher
-		}
-		if issn, ok := details["issn"].(string); ok && issn != "" {
-			achievement.Details.ISSN = issn
-		}
+	// 8. Validasi hasil akhir terhadap schema tipe-nya
+	if errs, err := s.validateDetails(achievement.AchievementType, achievement.Details, false); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievement type"})
+	} else if len(errs) > 0 {
+		return detailsInvalid(c, errs)
 	}
 
+	// Poin sementara mengikuti details terbaru
+	estimate, err := s.pointsService.Calculate(achievement)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to calculate points"})
+	}
+	achievement.Points = estimate.Points
 	achievement.UpdatedAt = time.Now()
 
-	// 8. Update di MongoDB
-	if err := s.achievementRepo.UpdateAchievement(ctx, ref.MongoAchievementID, achievement); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement"})
+	// 9. Update timestamp di PostgreSQL, sekaligus guard kalau status berubah
+	// (mis. sudah diajukan) sejak dibaca di langkah 3
+	if err := s.achievementRefRepo.TouchEditable(ref.ID); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "Achievement status changed, it can no longer be updated",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement reference"})
 	}
 
-	// 9. Update timestamp di PostgreSQL
-	ref.UpdatedAt = time.Now()
-	if err := s.achievementRefRepo.UpdateReference(ref); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement reference"})
+	// 10. Update di MongoDB
+	if err := s.achievementRepo.UpdateAchievement(ctx, ref.MongoAchievementID, achievement); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to update achievement"})
 	}
+	versionWarning := s.recordVersion(ctx, c, ref)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -823,6 +851,7 @@ func (s *AchievementService) UpdateAchievement(c *fiber.Ctx) error {
 			"status":     ref.Status,
 			"updated_at": achievement.UpdatedAt,
 		},
+		"warnings": versionWarning,
 	})
 }
 
@@ -861,27 +890,12 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 	}
 
 	// 2. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementDelete, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
 	}
-
-	// Ownership check
-	switch userRole.Name {
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err != nil || student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "Student not found"})
-		}
-		if student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
-	case "Admin":
-		// Admin bisa delete semua
-	default:
-		return c.Status(403).JSON(fiber.Map{"error": "Unauthorized role"})
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
 	}
 
 	if ref.Status != models.AchievementStatusDraft {
@@ -890,7 +904,7 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	err = s.achievementRefRepo.SoftDelete(refUUID)
+	err = s.achievementRefRepo.SoftDelete(refUUID, statusChange(c, ""))
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error":   "Failed to delete achievement",
@@ -911,14 +925,14 @@ func (s *AchievementService) DeleteAchievement(c *fiber.Ctx) error {
 
 // SubmitAchievement godoc
 // @Summary Submit achievement for verification
-// @Description Submit draft achievement for verification. Only draft achievements can be submitted
+// @Description Submit draft achievement for verification. Only draft achievements can be submitted. Possible duplicates (same certification number, same attachment file, or similar title + event date + organizer) are returned as non-blocking warnings
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Achievement ID (UUID)"
 // @Success 200 {object} map[string]interface{} "Achievement submitted successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request - Not draft status"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Not draft status or details fail the type schema (see fields)"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Not owner"
 // @Failure 404 {object} map[string]interface{} "Not Found"
@@ -937,11 +951,12 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 	}
 
 	// 2. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementSubmit, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
 	}
 
 	// Status check
@@ -951,49 +966,55 @@ func (s *AchievementService) SubmitAchievement(c *fiber.Ctx) error {
 		})
 	}
 
-	// Jika Mahasiswa, cek ownership
-	if userRole.Name == "Mahasiswa" {
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err != nil || student == nil || student.ID != ref.StudentID {
-			return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
-		}
+	// 3. Details harus lengkap sesuai schema sebelum diverifikasi
+	achievement, err := s.achievementRepo.GetAchievementByID(context.Background(), ref.MongoAchievementID)
+	if err != nil || achievement == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement details not found"})
 	}
-	// Admin tidak perlu validasi ownership
+	if errs, err := s.validateDetails(achievement.AchievementType, achievement.Details, false); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievement type"})
+	} else if len(errs) > 0 {
+		return detailsInvalid(c, errs)
+	}
+
+	// 4. Submit ke stage pertama workflow
+	firstStage := firstStageName(s.workflows.Stages(achievement))
 
-	// 3. Submit
-	if err := s.achievementRefRepo.SubmitForVerification(refUUID); err != nil {
+	if err := s.achievementRefRepo.SubmitForVerification(refUUID, firstStage, statusChange(c, "")); err != nil {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to submit achievement"})
 	}
 
 	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Achievement submitted",
+		"success":  true,
+		"message":  "Achievement submitted",
+		"warnings": s.studentDuplicateWarnings(context.Background(), achievement),
 		"data": fiber.Map{
-			"id":           ref.ID,
-			"new_status":   models.AchievementStatusSubmitted,
-			"submitted_at": time.Now(),
+			"id":            ref.ID,
+			"new_status":    models.AchievementStatusSubmitted,
+			"current_stage": firstStage,
+			"submitted_at":  time.Now(),
 		},
 	})
 }
 
-// ==================== 7. VERIFY ACHIEVEMENT ====================
-// VerifyAchievement godoc
-// @Summary Verify achievement
-// @Description Verify submitted achievement. Only submitted achievements can be verified. Dosen Wali: only advisee's achievements, Admin: all achievements
+// ResubmitAchievement godoc
+// @Summary Resubmit rejected achievement
+// @Description Send a rejected achievement back for verification after revising it. The revision counter is incremented, the previous rejection note stays in history, and the number of resubmissions is limited per achievement type (ACHIEVEMENT_MAX_RESUBMISSIONS[_<TYPE>])
 // @Tags Achievements
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path string true "Achievement ID (UUID)"
-// @Success 200 {object} map[string]interface{} "Achievement verified successfully"
-// @Failure 400 {object} map[string]interface{} "Bad Request - Not submitted status"
+// @Param request body map[string]interface{} false "Optional response to the rejection" SchemaExample({"note": "Certificate re-uploaded"})
+// @Success 200 {object} map[string]interface{} "Achievement resubmitted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Not rejected status or details fail the type schema (see fields)"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
-// @Failure 403 {object} map[string]interface{} "Forbidden - Not advisor or role not allowed"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not owner"
 // @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Resubmission limit reached or already resubmitted"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
-// @Router /achievements/{id}/verify [post]
-func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
-	// Parse ID sebagai UUID PostgreSQL
+// @Router /achievements/{id}/resubmit [post]
+func (s *AchievementService) ResubmitAchievement(c *fiber.Ctx) error {
 	refUUID, err := uuid.Parse(c.Params("id"))
 	if err != nil {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
@@ -1005,56 +1026,123 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
 	}
 
-	// 2. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	// 2. Validate user access (sama dengan submit)
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementSubmit, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Not your achievement"})
 	}
 
 	// Status check
-	if ref.Status != models.AchievementStatusSubmitted {
+	if ref.Status != models.AchievementStatusRejected {
 		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Only submitted achievements can be verified. Current: %s", ref.Status),
+			"error": fmt.Sprintf("Only rejected achievements can be resubmitted. Current: %s", ref.Status),
 		})
 	}
 
-	// Jika Dosen, cek apakah mahasiswa bimbingannya
-	if userRole.Name == "Dosen Wali" {
-		lecturer, err := s.lecturerRepo.GetByUserID(userID)
-		if err != nil || lecturer == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "Lecturer not found"})
-		}
+	// 3. Batas pengajuan ulang per tipe prestasi
+	achievement, err := s.achievementRepo.GetAchievementByID(context.Background(), ref.MongoAchievementID)
+	if err != nil || achievement == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement details not found"})
+	}
 
-		student, err := s.studentRepo.GetByID(ref.StudentID)
-		if err != nil || student == nil || student.AdvisorID == nil || *student.AdvisorID != lecturer.ID {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "You can only verify achievements of your advisees",
+	if errs, err := s.validateDetails(achievement.AchievementType, achievement.Details, false); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get achievement type"})
+	} else if len(errs) > 0 {
+		return detailsInvalid(c, errs)
+	}
+
+	maxResubmissions := config.MaxResubmissions(achievement.AchievementType)
+	if ref.Revision >= maxResubmissions {
+		return c.Status(409).JSON(fiber.Map{
+			"error":             "Resubmission limit reached for this achievement",
+			"revision":          ref.Revision,
+			"max_resubmissions": maxResubmissions,
+		})
+	}
+
+	var req struct {
+		Note string `json:"note"`
+	}
+	// Body opsional
+	_ = c.BodyParser(&req)
+
+	// 4. Resubmit, mulai lagi dari stage pertama
+	firstStage := firstStageName(s.workflows.Stages(achievement))
+	if err := s.achievementRefRepo.ResubmitAchievement(refUUID, firstStage, maxResubmissions, statusChange(c, strings.TrimSpace(req.Note))); err != nil {
+		// Request lain sudah mengajukan ulang (atau batas tercapai) sejak status dibaca
+		if errors.Is(err, repository.ErrStatusChanged) {
+			return c.Status(409).JSON(fiber.Map{
+				"error":             "Achievement was already resubmitted or reached its resubmission limit",
+				"max_resubmissions": maxResubmissions,
 			})
 		}
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to resubmit achievement"})
+	}
+
+	return c.JSON(fiber.Map{
+		"success":  true,
+		"message":  "Achievement resubmitted",
+		"warnings": s.studentDuplicateWarnings(context.Background(), achievement),
+		"data": fiber.Map{
+			"id":                      ref.ID,
+			"new_status":              models.AchievementStatusSubmitted,
+			"revision":                ref.Revision + 1,
+			"remaining_resubmissions": maxResubmissions - ref.Revision - 1,
+			"current_stage":           firstStage,
+			"submitted_at":            time.Now(),
+		},
+	})
+}
+
+// ==================== 7. VERIFY ACHIEVEMENT ====================
+// VerifyAchievement godoc
+// @Summary Approve achievement at its current verification stage
+// @Description Approve the current stage of a submitted achievement. Stages come from the workflow of the achievement type (default: advisor, then faculty and final for national/international or high-point achievements). Approving the last stage marks the achievement verified and fixes its points with the active points rules; otherwise it moves to the next stage. Only the role or relation responsible for the current stage may approve
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param request body map[string]interface{} false "Optional approval note" SchemaExample({"note": "Looks good"})
+// @Success 200 {object} map[string]interface{} "Stage approved or achievement verified"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Not submitted status"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not responsible for the current stage"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Stage already handled by someone else"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/verify [post]
+func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
+	// Parse ID sebagai UUID PostgreSQL
+	refUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
 	}
 
-	// 3. Verify
-	if err := s.achievementRefRepo.VerifyAchievement(refUUID, userID); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to verify achievement"})
+	var req struct {
+		Note string `json:"note"`
+	}
+	// Body opsional
+	_ = c.BodyParser(&req)
+
+	message, data, failure := s.approveAchievement(c, refUUID, strings.TrimSpace(req.Note))
+	if failure != nil {
+		return c.Status(failure.Status).JSON(failure.Body)
 	}
 
 	return c.JSON(fiber.Map{
 		"success": true,
-		"message": "Achievement verified",
-		"data": fiber.Map{
-			"id":          ref.ID,
-			"new_status":  models.AchievementStatusVerified,
-			"verified_by": userID,
-			"verified_at": time.Now(),
-		},
+		"message": message,
+		"data":    data,
 	})
 }
 
 // RejectAchievement godoc
-// @Summary Reject achievement
-// @Description Reject submitted achievement with rejection note. Only submitted achievements can be rejected
+// @Summary Reject achievement at its current verification stage
+// @Description Reject a submitted achievement with a rejection note. Only the role or relation responsible for the current stage may reject; the achievement goes back to the student, who can resubmit it from the first stage
 // @Tags Achievements
 // @Accept json
 // @Produce json
@@ -1064,8 +1152,9 @@ func (s *AchievementService) VerifyAchievement(c *fiber.Ctx) error {
 // @Success 200 {object} map[string]interface{} "Achievement rejected successfully"
 // @Failure 400 {object} map[string]interface{} "Bad Request - Missing rejection note or not submitted status"
 // @Failure 401 {object} map[string]interface{} "Unauthorized"
-// @Failure 403 {object} map[string]interface{} "Forbidden - Not advisor or role not allowed"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not responsible for the current stage"
 // @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Stage already handled by someone else"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /achievements/{id}/reject [post]
 func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
@@ -1075,72 +1164,206 @@ func (s *AchievementService) RejectAchievement(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
 	}
 
+	// Parse rejection note
+	var req struct {
+		RejectionNote string `json:"rejection_note"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.RejectionNote == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "Rejection note is required"})
+	}
+
+	data, failure := s.rejectAchievement(c, refUUID, req.RejectionNote)
+	if failure != nil {
+		return c.Status(failure.Status).JSON(failure.Body)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Achievement rejected",
+		"data":    data,
+	})
+}
+
+// actionFailure - response error dari satu aksi, dipakai endpoint tunggal maupun bulk
+type actionFailure struct {
+	Status int
+	Body   fiber.Map
+}
+
+func failAction(status int, message string) *actionFailure {
+	return &actionFailure{Status: status, Body: fiber.Map{"error": message}}
+}
+
+// approveAchievement - setujui stage saat ini; verified jika ini stage terakhir.
+// Setiap transisi adalah satu transaksi Postgres; MongoDB hanya menerima salinan poin final.
+func (s *AchievementService) approveAchievement(c *fiber.Ctx, refUUID uuid.UUID, note string) (string, fiber.Map, *actionFailure) {
 	// 1. Get reference
 	ref, err := s.achievementRefRepo.GetReferenceByID(refUUID)
 	if err != nil || ref == nil {
-		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+		return "", nil, failAction(404, "Achievement not found")
 	}
 
-	// 2. Validate user access
+	// 2. Validate user access: harus penanggung jawab stage saat ini
 	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
+	achievement, err := s.achievementRepo.GetAchievementByID(context.Background(), ref.MongoAchievementID)
+	if err != nil || achievement == nil {
+		return "", nil, failAction(500, "Failed to resolve verification workflow")
 	}
-
-	// Parse rejection note
-	var req struct {
-		RejectionNote string `json:"rejection_note"`
+	stages := s.workflows.Stages(achievement)
+	stage, index, allowed, err := s.authorizeStage(c, ref, stages)
+	if err != nil {
+		return "", nil, failAction(500, "Failed to check access")
 	}
-	if err := c.BodyParser(&req); err != nil || req.RejectionNote == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "Rejection note is required"})
+	if !allowed {
+		failure := failAction(403, "You are not responsible for the current verification stage")
+		failure.Body["current_stage"] = storedStage(ref)
+		return "", nil, failure
 	}
 
 	// Status check
 	if ref.Status != models.AchievementStatusSubmitted {
-		return c.Status(400).JSON(fiber.Map{
-			"error": fmt.Sprintf("Only submitted achievements can be rejected. Current: %s", ref.Status),
-		})
+		return "", nil, failAction(400, fmt.Sprintf("Only submitted achievements can be verified. Current: %s", ref.Status))
 	}
 
-	// Jika Dosen, cek advisor
-	if userRole.Name == "Dosen Wali" {
-		lecturer, err := s.lecturerRepo.GetByUserID(userID)
-		if err != nil || lecturer == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "Lecturer not found"})
+	change := statusChange(c, note)
+	change.Stage = stage.Name
+
+	// 3. Lanjut ke stage berikutnya, atau verified jika ini stage terakhir
+	if next := workflow.Next(stages, index); next != nil {
+		if err := s.achievementRefRepo.AdvanceStage(refUUID, storedStage(ref), next.Name, change); err != nil {
+			if errors.Is(err, repository.ErrStatusChanged) {
+				failure := failAction(409, "Stage already handled by someone else")
+				failure.Body["details"] = err.Error()
+				return "", nil, failure
+			}
+			return "", nil, failAction(500, "Failed to approve stage")
 		}
 
-		student, err := s.studentRepo.GetByID(ref.StudentID)
-		if err != nil || student == nil || student.AdvisorID == nil || *student.AdvisorID != lecturer.ID {
-			return c.Status(403).JSON(fiber.Map{
-				"error": "You can only reject achievements of your advisees",
-			})
+		return fmt.Sprintf("Stage %s approved, waiting for %s", stage.Name, next.Name), fiber.Map{
+			"id":             ref.ID,
+			"new_status":     models.AchievementStatusSubmitted,
+			"approved_stage": stage.Name,
+			"current_stage":  next.Name,
+			"approved_by":    userID,
+			"approved_at":    time.Now(),
+		}, nil
+	}
+
+	// 4. Poin final dengan aturan aktif, disimpan bersama versi aturannya
+	award, err := s.pointsService.Calculate(achievement)
+	if err != nil {
+		return "", nil, failAction(500, "Failed to calculate points")
+	}
+
+	if err := s.achievementRefRepo.VerifyAchievement(refUUID, storedStage(ref), award, change); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			failure := failAction(409, "Stage already handled by someone else")
+			failure.Body["details"] = err.Error()
+			return "", nil, failure
+		}
+		return "", nil, failAction(500, "Failed to verify achievement")
+	}
+	data := fiber.Map{
+		"id":             ref.ID,
+		"new_status":     models.AchievementStatusVerified,
+		"approved_stage": stage.Name,
+		"points":         award,
+		"verified_by":    userID,
+		"verified_at":    time.Now(),
+	}
+
+	// Salinan di MongoDB untuk tampilan; sumber utama tetap achievement_references.
+	// Status sudah ter-commit sebagai verified, jadi kegagalan bukan kegagalan item ini:
+	// salinan dicoba ulang di background dan dilaporkan sebagai warning
+	// (selama itu poin yang tampil tetap dari PostgreSQL, lihat displayPoints).
+	if award.Points != achievement.Points {
+		if err := s.achievementRepo.UpdatePoints(context.Background(), ref.MongoAchievementID, award.Points); err != nil {
+			log.Printf("Warning: failed to copy points of achievement %s to MongoDB: %v", ref.ID, err)
+			go s.retryPointsCopy(ref.ID, ref.MongoAchievementID, award.Points)
+			data["warnings"] = fiber.Map{
+				"points_sync_pending": "Achievement verified, but copying its points to MongoDB failed and is being retried",
+			}
+		}
+	}
+
+	return "Achievement verified", data, nil
+}
+
+// pointsCopyRetries / pointsCopyRetryDelay - percobaan ulang salinan poin ke MongoDB,
+// jeda bertambah tiap percobaan
+const (
+	pointsCopyRetries    = 3
+	pointsCopyRetryDelay = 5 * time.Second
+)
+
+// retryPointsCopy - dijalankan di background setelah verified ter-commit
+func (s *AchievementService) retryPointsCopy(refID uuid.UUID, mongoID string, points int) {
+	for attempt := 1; attempt <= pointsCopyRetries; attempt++ {
+		time.Sleep(time.Duration(attempt) * pointsCopyRetryDelay)
+		err := s.achievementRepo.UpdatePoints(context.Background(), mongoID, points)
+		if err == nil {
+			return
 		}
+		log.Printf("Warning: retry %d of copying points of achievement %s to MongoDB failed: %v", attempt, refID, err)
+	}
+	log.Printf("Warning: gave up copying points of achievement %s to MongoDB", refID)
+}
+
+// rejectAchievement - tolak di stage saat ini, kembali ke mahasiswa
+func (s *AchievementService) rejectAchievement(c *fiber.Ctx, refUUID uuid.UUID, rejectionNote string) (fiber.Map, *actionFailure) {
+	// 1. Get reference
+	ref, err := s.achievementRefRepo.GetReferenceByID(refUUID)
+	if err != nil || ref == nil {
+		return nil, failAction(404, "Achievement not found")
+	}
+
+	// 2. Validate user access: harus penanggung jawab stage saat ini
+	userID := c.Locals("user_id").(uuid.UUID)
+	stages, err := s.workflowStages(context.Background(), ref)
+	if err != nil {
+		return nil, failAction(500, "Failed to resolve verification workflow")
+	}
+	stage, _, allowed, err := s.authorizeStage(c, ref, stages)
+	if err != nil {
+		return nil, failAction(500, "Failed to check access")
+	}
+	if !allowed {
+		failure := failAction(403, "You are not responsible for the current verification stage")
+		failure.Body["current_stage"] = storedStage(ref)
+		return nil, failure
+	}
+
+	// Status check
+	if ref.Status != models.AchievementStatusSubmitted {
+		return nil, failAction(400, fmt.Sprintf("Only submitted achievements can be rejected. Current: %s", ref.Status))
 	}
 
 	// 3. Reject
-	if err := s.achievementRefRepo.RejectAchievement(refUUID, userID, req.RejectionNote); err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to reject achievement"})
+	change := statusChange(c, rejectionNote)
+	change.Stage = stage.Name
+	if err := s.achievementRefRepo.RejectAchievement(refUUID, storedStage(ref), change); err != nil {
+		if errors.Is(err, repository.ErrStatusChanged) {
+			failure := failAction(409, "Stage already handled by someone else")
+			failure.Body["details"] = err.Error()
+			return nil, failure
+		}
+		return nil, failAction(500, "Failed to reject achievement")
 	}
 
-	return c.JSON(fiber.Map{
-		"success": true,
-		"message": "Achievement rejected",
-		"data": fiber.Map{
-			"id":             ref.ID,
-			"new_status":     models.AchievementStatusRejected,
-			"rejection_note": req.RejectionNote,
-			"rejected_by":    userID,
-			"rejected_at":    time.Now(),
-		},
-	})
+	return fiber.Map{
+		"id":             ref.ID,
+		"new_status":     models.AchievementStatusRejected,
+		"rejected_stage": stage.Name,
+		"rejection_note": rejectionNote,
+		"rejected_by":    userID,
+		"rejected_at":    time.Now(),
+	}, nil
 }
 
 // ==================== 9. GET ACHIEVEMENT HISTORY ====================
 // GetAchievementHistory godoc
 // @Summary Get achievement history
-// @Description Get achievement status change history
+// @Description Get achievement status change history, read from the persistent status-transition log (who, when, from/to status, stage, note and request ID), together with the verification workflow progress
 // @Tags Achievements
 // @Accept json
 // @Produce json
@@ -1167,97 +1390,53 @@ func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
 	}
 
 	// 2. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
-	}
-
-	canAccess := false
-	switch userRole.Name {
-	case "Admin":
-		canAccess = true
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err == nil && student != nil && student.ID == ref.StudentID {
-			canAccess = true
-		}
-	case "Dosen Wali":
-		lecturer, err := s.lecturerRepo.GetByUserID(userID)
-		if err == nil && lecturer != nil {
-			student, err := s.studentRepo.GetByID(ref.StudentID)
-			if err == nil && student != nil && student.AdvisorID != nil && *student.AdvisorID == lecturer.ID {
-				canAccess = true
-			}
-		}
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementRead, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
 	}
-
-	if !canAccess {
+	if !allowed {
 		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
-	// 3. Build history
-	history := []fiber.Map{
-		{
-			"status":      models.AchievementStatusDraft,
-			"changed_at":  ref.CreatedAt,
-			"changed_by":  nil,
-			"description": "Achievement created",
-		},
-	}
-
-	if ref.SubmittedAt != nil {
-		history = append(history, fiber.Map{
-			"status":      models.AchievementStatusSubmitted,
-			"changed_at":  ref.SubmittedAt,
-			"changed_by":  nil,
-			"description": "Submitted for verification",
-		})
-	}
-
-	if ref.Status == models.AchievementStatusVerified && ref.VerifiedAt != nil && ref.VerifiedBy != nil {
-		verifierUser, _ := s.userRepo.GetByID(*ref.VerifiedBy)
-		var verifierName string
-		if verifierUser != nil {
-			verifierName = verifierUser.FullName
-		}
-
-		history = append(history, fiber.Map{
-			"status":     models.AchievementStatusVerified,
-			"changed_at": ref.VerifiedAt,
-			"changed_by": fiber.Map{
-				"id":   ref.VerifiedBy,
-				"name": verifierName,
-			},
-			"description": "Verified by advisor",
+	// 3. Load history dari log transisi status
+	events, err := s.achievementRefRepo.GetStatusEvents(ref.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement history",
+			"details": err.Error(),
 		})
 	}
 
-	if ref.Status == models.AchievementStatusRejected && ref.VerifiedAt != nil && ref.VerifiedBy != nil {
-		rejectorUser, _ := s.userRepo.GetByID(*ref.VerifiedBy)
-		var rejectorName string
-		if rejectorUser != nil {
-			rejectorName = rejectorUser.FullName
+	history := make([]fiber.Map, 0, len(events))
+	for _, event := range events {
+		var changedBy interface{}
+		if event.ChangedBy != nil {
+			changedBy = fiber.Map{
+				"id":   event.ChangedBy,
+				"name": event.ChangedByName,
+			}
 		}
 
 		history = append(history, fiber.Map{
-			"status":     models.AchievementStatusRejected,
-			"changed_at": ref.VerifiedAt,
-			"changed_by": fiber.Map{
-				"id":   ref.VerifiedBy,
-				"name": rejectorName,
-			},
-			"description": fmt.Sprintf("Rejected: %s", *ref.RejectionNote),
+			"status":      event.ToStatus,
+			"from_status": event.FromStatus,
+			"changed_at":  event.CreatedAt,
+			"changed_by":  changedBy,
+			"note":        event.Note,
+			"request_id":  event.RequestID,
+			"stage":       event.Stage,
+			"description": statusEventDescription(event),
 		})
 	}
 
-	// 4. Get achievement title
+	// 4. Get achievement title & progress workflow
 	ctx := context.Background()
 	achievement, _ := s.achievementRepo.GetAchievementByID(ctx, ref.MongoAchievementID)
 	var title string
+	stages := []fiber.Map{}
 	if achievement != nil {
 		title = achievement.Title
+		stages = stageProgress(ref, s.workflows.Stages(achievement))
 	}
 
 	return c.JSON(fiber.Map{
@@ -1266,15 +1445,49 @@ func (s *AchievementService) GetAchievementHistory(c *fiber.Ctx) error {
 			"achievement_id": ref.ID,
 			"title":          title,
 			"current_status": ref.Status,
+			"current_stage":  ref.WorkflowStage,
+			"revision":       ref.Revision,
+			"workflow":       stages,
 			"history":        history,
 		},
 	})
 }
 
+func statusEventDescription(event models.AchievementStatusEvent) string {
+	switch event.ToStatus {
+	case models.AchievementStatusDraft:
+		return "Achievement created"
+	case models.AchievementStatusSubmitted:
+		if event.FromStatus != nil && *event.FromStatus == models.AchievementStatusSubmitted {
+			return fmt.Sprintf("Approved at stage %s", event.Stage)
+		}
+		if event.FromStatus != nil && *event.FromStatus == models.AchievementStatusRejected {
+			return "Resubmitted after rejection"
+		}
+		return "Submitted for verification"
+	case models.AchievementStatusVerified:
+		if event.Stage != "" {
+			return fmt.Sprintf("Verified at stage %s", event.Stage)
+		}
+		return "Verified"
+	case models.AchievementStatusRejected:
+		if event.Note != nil && event.Stage != "" {
+			return fmt.Sprintf("Rejected at stage %s: %s", event.Stage, *event.Note)
+		}
+		if event.Note != nil {
+			return fmt.Sprintf("Rejected: %s", *event.Note)
+		}
+		return "Rejected"
+	case models.AchievementStatusDeleted:
+		return "Achievement deleted"
+	}
+	return fmt.Sprintf("Status changed to %s", event.ToStatus)
+}
+
 // ==================== 10. UPLOAD ATTACHMENT ====================
 // UploadAttachment godoc
 // @Summary Upload achievement attachment
-// @Description Upload file attachment to achievement. Only draft achievements can have attachments uploaded
+// @Description Upload file attachment to achievement. Only draft or rejected achievements can have attachments uploaded
 // @Tags Achievements
 // @Accept multipart/form-data
 // @Produce json
@@ -1304,32 +1517,18 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 	}
 
 	// 2. Validate user access
-	userID := c.Locals("user_id").(uuid.UUID)
-	user := c.Locals("user").(*models.User)
-	userRole, err := s.roleRepo.GetByID(user.RoleID)
-	if err != nil || userRole == nil {
-		return c.Status(500).JSON(fiber.Map{"error": "Failed to get user role"})
-	}
-
-	canUpload := false
-	switch userRole.Name {
-	case "Admin":
-		canUpload = true
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(userID)
-		if err == nil && student != nil && student.ID == ref.StudentID {
-			canUpload = true
-		}
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementAttach, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
 	}
-
-	if !canUpload {
+	if !allowed {
 		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
 	}
 
-	// 3. Status check (hanya draft yang bisa upload attachment)
-	if ref.Status != models.AchievementStatusDraft {
+	// 3. Status check (draft, atau rejected yang sedang direvisi)
+	if !isEditableStatus(ref.Status) {
 		return c.Status(400).JSON(fiber.Map{
-			"error": "Only draft achievements can have attachments uploaded",
+			"error": "Only draft or rejected achievements can have attachments uploaded",
 		})
 	}
 
@@ -1386,20 +1585,32 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to save file"})
 	}
 
+	// Hash isi file untuk mendeteksi sertifikat yang sama diunggah di prestasi lain
+	fileHash, err := hashFile(filePath)
+	if err != nil {
+		os.Remove(filePath)
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to save file"})
+	}
+
 	// 6. Create attachment object
 	attachment := models.Attachment{
 		FileName:   file.Filename,
 		FileURL:    "/uploads/achievements/" + filename,
 		FileType:   fileType,
 		UploadedAt: time.Now(),
+		SHA256:     fileHash,
 	}
 
 	// 7. Save to MongoDB
+	if achievement, err := s.achievementRepo.GetAchievementByID(ctx, ref.MongoAchievementID); err == nil && achievement != nil {
+		s.ensureBaselineVersion(ctx, ref, achievement)
+	}
 	err = s.achievementRepo.AddAttachment(ctx, ref.MongoAchievementID, attachment)
 	if err != nil {
 		os.Remove(filePath)
 		return c.Status(500).JSON(fiber.Map{"error": "Failed to save attachment"})
 	}
+	versionWarning := s.recordVersion(ctx, c, ref)
 
 	return c.JSON(fiber.Map{
 		"success": true,
@@ -1409,5 +1620,6 @@ func (s *AchievementService) UploadAttachment(c *fiber.Ctx) error {
 			"achievement_id": ref.ID,
 			"uploaded_at":    attachment.UploadedAt,
 		},
+		"warnings": versionWarning,
 	})
 }
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/config"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestResubmitAchievementGuards(t *testing.T) {
+	max := config.MaxResubmissions("academic")
+
+	tests := []struct {
+		name          string
+		revision      int
+		transitionErr error
+		wantStatus    int
+	}{
+		{"first resubmission", 0, nil, fiber.StatusOK},
+		{"limit already reached", max, nil, fiber.StatusConflict},
+		// Request lain mengajukan ulang di antara pengecekan dan UPDATE
+		{"lost race at the limit", max - 1, fmt.Errorf("%w, expected: rejected", repository.ErrStatusChanged), fiber.StatusConflict},
+		{"database error", 0, fmt.Errorf("connection reset"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAchievementFixture("academic", models.AchievementDetails{})
+			f.ref.Status = models.AchievementStatusRejected
+			f.ref.Revision = tt.revision
+			f.refRepo.transitionErr = tt.transitionErr
+
+			app := f.app(f.ownerUser, "POST", "/achievements/:id/resubmit", f.svc.ResubmitAchievement)
+			resp, err := app.Test(httptest.NewRequest("POST", "/achievements/"+f.ref.ID.String()+"/resubmit", nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.revision < max && f.refRepo.maxRevisions != max {
+				t.Fatalf("repository guard got max revisions %d, want %d", f.refRepo.maxRevisions, max)
+			}
+		})
+	}
+}
+
+func TestVerifyAndRejectGuardCurrentStage(t *testing.T) {
+	stale := fmt.Errorf("%w, expected: submitted", repository.ErrStatusChanged)
+
+	tests := []struct {
+		name          string
+		path          string
+		body          string
+		level         string
+		stage         string
+		transitionErr error
+		wantStatus    int
+	}{
+		{"verify last stage", "verify", `{}`, "", "advisor", nil, fiber.StatusOK},
+		{"advance to next stage", "verify", `{}`, "national", "advisor", nil, fiber.StatusOK},
+		{"verify after stage was handled", "verify", `{}`, "", "advisor", stale, fiber.StatusConflict},
+		{"advance after stage was handled", "verify", `{}`, "national", "advisor", stale, fiber.StatusConflict},
+		{"advance fails on database error", "verify", `{}`, "national", "advisor", errors.New("connection reset"), fiber.StatusInternalServerError},
+		{"reject", "reject", `{"rejection_note": "incomplete"}`, "", "advisor", nil, fiber.StatusOK},
+		{"reject after stage was handled", "reject", `{"rejection_note": "incomplete"}`, "", "advisor", stale, fiber.StatusConflict},
+		{"reject legacy row without stage", "reject", `{"rejection_note": "incomplete"}`, "", "", nil, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAchievementFixture("competition", models.AchievementDetails{
+				CompetitionName:  "Gemastik",
+				CompetitionLevel: tt.level,
+			})
+			f.ref.Status = models.AchievementStatusSubmitted
+			if tt.stage != "" {
+				stage := tt.stage
+				f.ref.WorkflowStage = &stage
+			}
+			f.refRepo.transitionErr = tt.transitionErr
+
+			handler := f.svc.VerifyAchievement
+			if tt.path == "reject" {
+				handler = f.svc.RejectAchievement
+			}
+			app := f.app(f.adminUser, "POST", "/achievements/:id/"+tt.path, handler)
+
+			req := httptest.NewRequest("POST", "/achievements/"+f.ref.ID.String()+"/"+tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if f.refRepo.expectedStage == nil || *f.refRepo.expectedStage != tt.stage {
+				t.Fatalf("repository guard got stage %v, want %q", f.refRepo.expectedStage, tt.stage)
+			}
+		})
+	}
+}
+
+func TestVerifyWarnsWhenPointsCopyFails(t *testing.T) {
+	tests := []struct {
+		name            string
+		updatePointsErr error
+		wantWarning     bool
+	}{
+		{"copied", nil, false},
+		// Status sudah verified di PostgreSQL, jadi tetap sukses dengan warning
+		{"mongo unavailable", errors.New("server selection timeout"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAchievementFixture("competition", models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "local"})
+			f.ref.Status = models.AchievementStatusSubmitted
+			// Estimasi lama berbeda dengan poin final, jadi salinan MongoDB harus diperbarui
+			f.achievement.Points = -1
+			f.achievements.updatePointsErr = tt.updatePointsErr
+
+			app := f.app(f.adminUser, "POST", "/achievements/:id/verify", f.svc.VerifyAchievement)
+			resp, err := app.Test(httptest.NewRequest("POST", "/achievements/"+f.ref.ID.String()+"/verify", nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusOK {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+			}
+
+			var body struct {
+				Data struct {
+					Warnings map[string]interface{} `json:"warnings"`
+				} `json:"data"`
+			}
+			if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if _, got := body.Data.Warnings["points_sync_pending"]; got != tt.wantWarning {
+				t.Fatalf("points_sync_pending warning = %v, want %v", got, tt.wantWarning)
+			}
+			if tt.updatePointsErr == nil && f.achievement.Points != f.ref.Points {
+				t.Fatalf("MongoDB points = %d, want %d", f.achievement.Points, f.ref.Points)
+			}
+		})
+	}
+}
+
+func TestStatusChangeTruncatesRequestID(t *testing.T) {
+	tests := []struct {
+		name      string
+		requestID string
+		want      string
+	}{
+		{"short id", "abc-123", "abc-123"},
+		{"exactly max", strings.Repeat("a", maxRequestIDLength), strings.Repeat("a", maxRequestIDLength)},
+		{"too long", strings.Repeat("b", 300), strings.Repeat("b", maxRequestIDLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got models.StatusChange
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				c.Locals("requestid", tt.requestID)
+				got = statusChange(c, "")
+				return nil
+			})
+			if _, err := app.Test(httptest.NewRequest("GET", "/", nil)); err != nil {
+				t.Fatal(err)
+			}
+			if got.RequestID != tt.want {
+				t.Errorf("request id length = %d, want %d", len(got.RequestID), len(tt.want))
+			}
+		})
+	}
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"strings"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/app/schema"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type AchievementTypeService struct {
+	typeRepo repository.AchievementTypeRepository
+	schemas  schema.Registry
+}
+
+func NewAchievementTypeService(typeRepo repository.AchievementTypeRepository, schemas schema.Registry) *AchievementTypeService {
+	return &AchievementTypeService{
+		typeRepo: typeRepo,
+		schemas:  schemas,
+	}
+}
+
+// withSchema - tipe beserta field details yang wajib / boleh / dilarang
+func (s *AchievementTypeService) withSchema(t models.AchievementType) fiber.Map {
+	return fiber.Map{
+		"type":           t,
+		"details_schema": s.schemas.For(t.Code),
+	}
+}
+
+func (s *AchievementTypeService) findType(c *fiber.Ctx) (*models.AchievementType, int, fiber.Map) {
+	t, err := s.typeRepo.GetByCode(c.Params("code"))
+	if err != nil {
+		return nil, 500, fiber.Map{"error": "Failed to get achievement type", "details": err.Error()}
+	}
+	if t == nil {
+		return nil, 404, fiber.Map{"error": "Achievement type not found"}
+	}
+	return t, 0, nil
+}
+
+// GetAll godoc
+// @Summary List achievement types
+// @Description Achievement types with their custom field definitions (labels in Indonesian and English) and the details fields that are required, optional or forbidden. Retired types are hidden unless include_retired=true
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param include_retired query bool false "Include retired types"
+// @Success 200 {object} map[string]interface{} "Achievement types"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievement-types [get]
+func (s *AchievementTypeService) GetAll(c *fiber.Ctx) error {
+	types, err := s.typeRepo.List(c.QueryBool("include_retired", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement types",
+			"details": err.Error(),
+		})
+	}
+
+	data := make([]fiber.Map, len(types))
+	for i, t := range types {
+		data[i] = s.withSchema(t)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    data,
+	})
+}
+
+// GetByCode godoc
+// @Summary Get achievement type
+// @Description Achievement type with its custom field definitions, including retired fields
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Type code"
+// @Success 200 {object} map[string]interface{} "Achievement type"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievement-types/{code} [get]
+func (s *AchievementTypeService) GetByCode(c *fiber.Ctx) error {
+	t, status, resp := s.findType(c)
+	if t == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    s.withSchema(*t),
+	})
+}
+
+// Create godoc
+// @Summary Create achievement type
+// @Description Create a new achievement type (e.g. "community_service") with typed custom fields: text, number, date, enum (with options) or url. Admin only.
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAchievementTypeRequest true "Achievement type"
+// @Success 201 {object} map[string]interface{} "Achievement type created"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid code, names or field definitions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 409 {object} map[string]interface{} "Conflict - Code already exists"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievement-types [post]
+func (s *AchievementTypeService) Create(c *fiber.Ctx) error {
+	var req models.CreateAchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	req.Code = strings.TrimSpace(req.Code)
+	if !schema.KeyPattern.MatchString(req.Code) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Code must be lowercase letters, digits and underscore (max 50 characters)",
+		})
+	}
+	req.NameID = strings.TrimSpace(req.NameID)
+	req.NameEN = strings.TrimSpace(req.NameEN)
+	if req.NameID == "" || req.NameEN == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "name_id and name_en are required"})
+	}
+	if err := schema.ValidateDefinitions(req.Fields); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	existing, err := s.typeRepo.GetByCode(req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check achievement type",
+			"details": err.Error(),
+		})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{"error": "Achievement type code already exists"})
+	}
+
+	t := &models.AchievementType{
+		Code:        req.Code,
+		NameID:      req.NameID,
+		NameEN:      req.NameEN,
+		Description: strings.TrimSpace(req.Description),
+		Fields:      req.Fields,
+	}
+	if t.Fields == nil {
+		t.Fields = []models.CustomFieldDefinition{}
+	}
+	if err := s.typeRepo.Create(t); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create achievement type",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Achievement type created",
+		"data":    s.withSchema(*t),
+	})
+}
+
+// Update godoc
+// @Summary Update achievement type
+// @Description Change names, description or custom fields. custom_fields replaces the list of active fields; fields left out are retired (kept for existing achievements, not allowed on new ones). The type of an existing field cannot be changed. Admin only.
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Type code"
+// @Param request body models.UpdateAchievementTypeRequest true "Changes"
+// @Success 200 {object} map[string]interface{} "Achievement type updated"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid names or field definitions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievement-types/{code} [put]
+func (s *AchievementTypeService) Update(c *fiber.Ctx) error {
+	t, status, resp := s.findType(c)
+	if t == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	var req models.UpdateAchievementTypeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if req.NameID != nil {
+		if t.NameID = strings.TrimSpace(*req.NameID); t.NameID == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "name_id must not be empty"})
+		}
+	}
+	if req.NameEN != nil {
+		if t.NameEN = strings.TrimSpace(*req.NameEN); t.NameEN == "" {
+			return c.Status(400).JSON(fiber.Map{"error": "name_en must not be empty"})
+		}
+	}
+	if req.Description != nil {
+		t.Description = strings.TrimSpace(*req.Description)
+	}
+
+	if req.Fields != nil {
+		fields := *req.Fields
+		if err := schema.ValidateDefinitions(fields); err != nil {
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+
+		// Nilai yang sudah tersimpan harus tetap valid, jadi tipe field lama tidak boleh berubah
+		for i := range fields {
+			fields[i].Retired = false
+			for _, current := range t.Fields {
+				if current.Key == fields[i].Key && current.Type != fields[i].Type {
+					return c.Status(400).JSON(fiber.Map{
+						"error": "Type of custom field " + current.Key + " cannot be changed; add a field with a new key instead",
+					})
+				}
+			}
+		}
+		t.Fields = fields
+	} else {
+		// Daftar field tidak diubah, hanya field aktif yang dikirim ke repository
+		var active []models.CustomFieldDefinition
+		for _, field := range t.Fields {
+			if !field.Retired {
+				active = append(active, field)
+			}
+		}
+		t.Fields = active
+	}
+
+	if err := s.typeRepo.Update(t); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update achievement type",
+			"details": err.Error(),
+		})
+	}
+
+	updated, status, resp := s.findType(c)
+	if updated == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": "Achievement type updated",
+		"data":    s.withSchema(*updated),
+	})
+}
+
+// Retire godoc
+// @Summary Retire achievement type
+// @Description New achievements can no longer use the type. Existing achievements keep it and can still be edited, submitted and verified. Admin only.
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Type code"
+// @Success 200 {object} map[string]interface{} "Achievement type retired"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Already retired"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievement-types/{code} [delete]
+func (s *AchievementTypeService) Retire(c *fiber.Ctx) error {
+	return s.setRetired(c, true)
+}
+
+// Restore godoc
+// @Summary Restore retired achievement type
+// @Description Make a retired achievement type available for new achievements again. Admin only.
+// @Tags Achievement Types
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param code path string true "Type code"
+// @Success 200 {object} map[string]interface{} "Achievement type restored"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Not retired"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievement-types/{code}/restore [post]
+func (s *AchievementTypeService) Restore(c *fiber.Ctx) error {
+	return s.setRetired(c, false)
+}
+
+func (s *AchievementTypeService) setRetired(c *fiber.Ctx, retired bool) error {
+	t, status, resp := s.findType(c)
+	if t == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	if (t.RetiredAt != nil) == retired {
+		message := "Achievement type is not retired"
+		if retired {
+			message = "Achievement type is already retired"
+		}
+		return c.Status(409).JSON(fiber.Map{"error": message})
+	}
+
+	if err := s.typeRepo.SetRetired(t.Code, retired); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update achievement type",
+			"details": err.Error(),
+		})
+	}
+
+	message := "Achievement type restored"
+	if retired {
+		message = "Achievement type retired"
+	}
+	return c.JSON(fiber.Map{
+		"success": true,
+		"message": message,
+		"data": fiber.Map{
+			"code":    t.Code,
+			"retired": retired,
+		},
+	})
+}
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestUpdateAchievementDetailsPerType(t *testing.T) {
+	eventDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	newDate := time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	periodStart := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name            string
+		achievementType string
+		stored          models.AchievementDetails
+		body            string
+		wantStatus      int
+		want            models.AchievementDetails
+	}{
+		{
+			name:            "competition keeps fields that are not sent",
+			achievementType: "competition",
+			stored:          models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Rank: 2, Organizer: "Kemdikbud", Location: "Jakarta", EventDate: &eventDate},
+			body:            `{"details": {"rank": 1, "organizer": "Puspresnas", "event_date": "2024-06-02T00:00:00Z"}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Rank: 1, Organizer: "Puspresnas", Location: "Jakarta", EventDate: &newDate},
+		},
+		{
+			name:            "competition clears optional fields",
+			achievementType: "competition",
+			stored:          models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", MedalType: "gold", Location: "Jakarta", EventDate: &eventDate},
+			body:            `{"details": {"medal_type": null, "location": "", "event_date": null}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national"},
+		},
+		{
+			name:            "competition cannot clear a required field",
+			achievementType: "competition",
+			stored:          models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national"},
+			body:            `{"details": {"competition_level": ""}}`,
+			wantStatus:      fiber.StatusBadRequest,
+		},
+		{
+			name:            "organization updates name, position and period",
+			achievementType: "organization",
+			stored:          models.AchievementDetails{OrganizationName: "BEM", Position: "Staff", Period: &models.Period{Start: periodStart}, Location: "Malang"},
+			body:            `{"details": {"organization_name": "HIMA", "position": "Ketua", "period": {"start": "2024-01-01T00:00:00Z", "end": "2024-12-31T00:00:00Z"}}}`,
+			wantStatus:      fiber.StatusOK,
+			want: models.AchievementDetails{
+				OrganizationName: "HIMA",
+				Position:         "Ketua",
+				Period:           &models.Period{Start: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), End: time.Date(2024, 12, 31, 0, 0, 0, 0, time.UTC)},
+				Location:         "Malang",
+			},
+		},
+		{
+			name:            "organization rejects a period ending before it starts",
+			achievementType: "organization",
+			stored:          models.AchievementDetails{OrganizationName: "BEM", Position: "Staff", Period: &models.Period{Start: periodStart}},
+			body:            `{"details": {"period": {"start": "2024-01-01T00:00:00Z", "end": "2023-01-01T00:00:00Z"}}}`,
+			wantStatus:      fiber.StatusBadRequest,
+		},
+		{
+			name:            "publication replaces authors and clears issn",
+			achievementType: "publication",
+			stored:          models.AchievementDetails{PublicationTitle: "Paper", PublicationType: "journal", Authors: []string{"A"}, Publisher: "IEEE", ISSN: "2049-3630"},
+			body:            `{"details": {"authors": ["A", "B"], "issn": null}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{PublicationTitle: "Paper", PublicationType: "journal", Authors: []string{"A", "B"}, Publisher: "IEEE"},
+		},
+		{
+			name:            "publication rejects an invalid issn",
+			achievementType: "publication",
+			stored:          models.AchievementDetails{PublicationTitle: "Paper", PublicationType: "journal", Authors: []string{"A"}},
+			body:            `{"details": {"issn": "1234-5678"}}`,
+			wantStatus:      fiber.StatusBadRequest,
+		},
+		{
+			name:            "certification updates number, issuer and name",
+			achievementType: "certification",
+			stored:          models.AchievementDetails{CertificationName: "CCNA", IssuedBy: "Cisco", CertificationNumber: "OLD-1", EventDate: &eventDate},
+			body:            `{"details": {"certification_name": "CCNP", "issued_by": "Cisco Systems", "certification_number": "NEW-2"}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{CertificationName: "CCNP", IssuedBy: "Cisco Systems", CertificationNumber: "NEW-2", EventDate: &eventDate},
+		},
+		{
+			name:            "certification clears number and valid until",
+			achievementType: "certification",
+			stored:          models.AchievementDetails{CertificationName: "CCNA", IssuedBy: "Cisco", CertificationNumber: "OLD-1", ValidUntil: &eventDate},
+			body:            `{"details": {"certification_number": "", "valid_until": null}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{CertificationName: "CCNA", IssuedBy: "Cisco"},
+		},
+		{
+			name:            "academic updates score and location",
+			achievementType: "academic",
+			stored:          models.AchievementDetails{Score: 80, Location: "Malang"},
+			body:            `{"details": {"score": 95, "location": "Surabaya"}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{Score: 95, Location: "Surabaya"},
+		},
+		{
+			name:            "academic rejects a field of another type",
+			achievementType: "academic",
+			stored:          models.AchievementDetails{Score: 80},
+			body:            `{"details": {"organization_name": "BEM"}}`,
+			wantStatus:      fiber.StatusBadRequest,
+		},
+		{
+			name:            "wrong value type",
+			achievementType: "academic",
+			stored:          models.AchievementDetails{Score: 80},
+			body:            `{"details": {"score": "high"}}`,
+			wantStatus:      fiber.StatusBadRequest,
+		},
+		{
+			name:            "other type clears custom fields",
+			achievementType: "other",
+			stored:          models.AchievementDetails{Organizer: "Kampus", CustomFields: map[string]interface{}{"note": "x"}},
+			body:            `{"details": {"custom_fields": null}}`,
+			wantStatus:      fiber.StatusOK,
+			want:            models.AchievementDetails{Organizer: "Kampus"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := newAchievementFixture(tt.achievementType, tt.stored)
+			app := f.app(f.ownerUser, "PUT", "/achievements/:id", f.svc.UpdateAchievement)
+
+			req := httptest.NewRequest("PUT", "/achievements/"+f.ref.ID.String(), strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+
+			saved := f.achievements.achievements[f.achievement.ID.Hex()]
+			if tt.wantStatus != fiber.StatusOK {
+				if !reflect.DeepEqual(saved.Details, tt.stored) {
+					t.Fatalf("details changed on a rejected update: %+v", saved.Details)
+				}
+				return
+			}
+			if !reflect.DeepEqual(saved.Details, tt.want) {
+				t.Fatalf("details = %+v\nwant      %+v", saved.Details, tt.want)
+			}
+		})
+	}
+}
+
+func TestUpdateAchievementLostToStatusChange(t *testing.T) {
+	eventDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	f := newAchievementFixture("competition", models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Rank: 2, EventDate: &eventDate})
+	// Sudah diajukan oleh request lain setelah status dibaca
+	f.refRepo.transitionErr = repository.ErrStatusChanged
+	app := f.app(f.ownerUser, "PUT", "/achievements/:id", f.svc.UpdateAchievement)
+
+	req := httptest.NewRequest("PUT", "/achievements/"+f.ref.ID.String(), strings.NewReader(`{"title": "Judul baru"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusConflict {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusConflict)
+	}
+	if saved := f.achievements.achievements[f.achievement.ID.Hex()]; saved.Title != "Prestasi" {
+		t.Fatalf("title = %q, MongoDB was updated despite the conflict", saved.Title)
+	}
+}
+
+func TestUpdateAchievementWarnsWhenVersionIsNotSaved(t *testing.T) {
+	eventDate := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	f := newAchievementFixture("competition", models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Rank: 2, EventDate: &eventDate})
+	// Baseline sudah ada, jadi hanya versi hasil update yang gagal disimpan
+	f.versions.saved = 1
+	f.versions.saveErr = errors.New("server selection timeout")
+	app := f.app(f.ownerUser, "PUT", "/achievements/:id", f.svc.UpdateAchievement)
+
+	req := httptest.NewRequest("PUT", "/achievements/"+f.ref.ID.String(), strings.NewReader(`{"title": "Judul baru"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if resp.StatusCode != fiber.StatusOK {
+		t.Fatalf("status = %d, want %d", resp.StatusCode, fiber.StatusOK)
+	}
+
+	var body struct {
+		Warnings map[string]interface{} `json:"warnings"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := body.Warnings["version_not_saved"]; !ok {
+		t.Fatalf("warnings = %v, want version_not_saved", body.Warnings)
+	}
+}
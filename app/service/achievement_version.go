@@ -0,0 +1,309 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"reflect"
+	"sort"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ensureBaselineVersion - prestasi lama yang dibuat sebelum ada versioning disimpan dulu
+// sebagai versi pertama, supaya perubahan berikutnya tetap bisa dibandingkan
+func (s *AchievementService) ensureBaselineVersion(ctx context.Context, ref *models.AchievementReference, achievement *models.Achievement) {
+	count, err := s.achievementVersionRepo.CountVersions(ctx, ref.MongoAchievementID)
+	if err != nil {
+		log.Printf("WARNING: failed to count versions of achievement %s: %v", ref.ID, err)
+		return
+	}
+	if count > 0 {
+		return
+	}
+	if _, err := s.achievementVersionRepo.SaveVersion(ctx, achievement, ref.Revision, uuid.Nil); err != nil {
+		log.Printf("WARNING: failed to save baseline version of achievement %s: %v", ref.ID, err)
+	}
+}
+
+// recordVersion - simpan snapshot terbaru dari MongoDB setelah achievement disimpan.
+// Perubahannya sudah tersimpan, jadi kegagalan tidak membatalkan request tapi dikembalikan
+// sebagai warning supaya riwayat yang bolong tidak diam-diam terjadi.
+func (s *AchievementService) recordVersion(ctx context.Context, c *fiber.Ctx, ref *models.AchievementReference) fiber.Map {
+	achievement, err := s.achievementRepo.GetAchievementByID(ctx, ref.MongoAchievementID)
+	if err != nil || achievement == nil {
+		log.Printf("WARNING: failed to load achievement %s for versioning: %v", ref.ID, err)
+		return versionNotSavedWarning()
+	}
+
+	savedBy, _ := c.Locals("user_id").(uuid.UUID)
+	if _, err := s.achievementVersionRepo.SaveVersion(ctx, achievement, ref.Revision, savedBy); err != nil {
+		log.Printf("WARNING: failed to save version of achievement %s: %v", ref.ID, err)
+		return versionNotSavedWarning()
+	}
+	return nil
+}
+
+func versionNotSavedWarning() fiber.Map {
+	return fiber.Map{"version_not_saved": "The change was saved but could not be added to the version history"}
+}
+
+// mergeWarnings - gabungkan beberapa warning jadi satu field "warnings"; nil kalau tidak ada
+func mergeWarnings(warnings ...fiber.Map) fiber.Map {
+	var merged fiber.Map
+	for _, w := range warnings {
+		for key, value := range w {
+			if merged == nil {
+				merged = fiber.Map{}
+			}
+			merged[key] = value
+		}
+	}
+	return merged
+}
+
+// GetAchievementVersions godoc
+// @Summary List achievement versions
+// @Description List every saved version of an achievement, oldest first. A new version is stored each time the achievement is created, updated or gets an attachment
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Achievement versions"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/versions [get]
+func (s *AchievementService) GetAchievementVersions(c *fiber.Ctx) error {
+	refUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
+	}
+
+	ref, err := s.achievementRefRepo.GetReferenceByID(refUUID)
+	if err != nil || ref == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementRead, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+
+	versions, err := s.achievementVersionRepo.ListVersions(context.Background(), ref.MongoAchievementID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement versions",
+			"details": err.Error(),
+		})
+	}
+	if versions == nil {
+		versions = []models.AchievementVersion{}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data": fiber.Map{
+			"achievement_id": ref.ID,
+			"revision":       ref.Revision,
+			"versions":       versions,
+		},
+	})
+}
+
+// DiffAchievementVersions godoc
+// @Summary Diff two achievement versions
+// @Description Structured field-level diff of title, details, tags, points and attachments between version a and version b
+// @Tags Achievements
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Achievement ID (UUID)"
+// @Param a path int true "Base version"
+// @Param b path int true "Compared version"
+// @Success 200 {object} map[string]interface{} "Diff between versions"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID or version"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Access denied"
+// @Failure 404 {object} map[string]interface{} "Not Found - Achievement or version not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /achievements/{id}/versions/{a}/diff/{b} [get]
+func (s *AchievementService) DiffAchievementVersions(c *fiber.Ctx) error {
+	refUUID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid achievement ID"})
+	}
+
+	from, errA := c.ParamsInt("a")
+	to, errB := c.ParamsInt("b")
+	if errA != nil || errB != nil || from < 1 || to < 1 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid version number"})
+	}
+
+	ref, err := s.achievementRefRepo.GetReferenceByID(refUUID)
+	if err != nil || ref == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Achievement not found"})
+	}
+
+	_, allowed, err := s.authorizeAchievement(c, authz.ActionAchievementRead, ref)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+	if !allowed {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+
+	ctx := context.Background()
+	versionA, err := s.achievementVersionRepo.GetVersion(ctx, ref.MongoAchievementID, from)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement version",
+			"details": err.Error(),
+		})
+	}
+	versionB, err := s.achievementVersionRepo.GetVersion(ctx, ref.MongoAchievementID, to)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get achievement version",
+			"details": err.Error(),
+		})
+	}
+	if versionA == nil || versionB == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Version not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    diffAchievementVersions(versionA, versionB),
+	})
+}
+
+func diffAchievementVersions(a, b *models.AchievementVersion) models.AchievementDiff {
+	diff := models.AchievementDiff{
+		FromVersion: a.Version,
+		ToVersion:   b.Version,
+		Changes:     []models.FieldChange{},
+	}
+
+	if a.Title != b.Title {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "title", Old: a.Title, New: b.Title})
+	}
+
+	diff.Changes = append(diff.Changes, diffDetails(a.Details, b.Details)...)
+
+	if added, removed := diffStrings(a.Tags, b.Tags); len(added) > 0 || len(removed) > 0 {
+		change := models.FieldChange{Field: "tags"}
+		if len(added) > 0 {
+			change.Added = added
+		}
+		if len(removed) > 0 {
+			change.Removed = removed
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	if a.Points != b.Points {
+		diff.Changes = append(diff.Changes, models.FieldChange{Field: "points", Old: a.Points, New: b.Points})
+	}
+
+	if added, removed := diffAttachments(a.Attachments, b.Attachments); len(added) > 0 || len(removed) > 0 {
+		change := models.FieldChange{Field: "attachments"}
+		if len(added) > 0 {
+			change.Added = added
+		}
+		if len(removed) > 0 {
+			change.Removed = removed
+		}
+		diff.Changes = append(diff.Changes, change)
+	}
+
+	return diff
+}
+
+// diffDetails - bandingkan per field (nama field mengikuti JSON, mis. "details.competition_level")
+func diffDetails(a, b models.AchievementDetails) []models.FieldChange {
+	oldFields := detailsToMap(a)
+	newFields := detailsToMap(b)
+
+	keys := make([]string, 0, len(oldFields)+len(newFields))
+	for key := range oldFields {
+		keys = append(keys, key)
+	}
+	for key := range newFields {
+		if _, ok := oldFields[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	var changes []models.FieldChange
+	for _, key := range keys {
+		oldValue, newValue := oldFields[key], newFields[key]
+		if reflect.DeepEqual(oldValue, newValue) {
+			continue
+		}
+		changes = append(changes, models.FieldChange{Field: "details." + key, Old: oldValue, New: newValue})
+	}
+	return changes
+}
+
+func detailsToMap(details models.AchievementDetails) map[string]interface{} {
+	fields := map[string]interface{}{}
+	raw, err := json.Marshal(details)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(raw, &fields)
+	return fields
+}
+
+func diffStrings(old, new []string) (added, removed []string) {
+	oldSet := make(map[string]bool, len(old))
+	for _, v := range old {
+		oldSet[v] = true
+	}
+	newSet := make(map[string]bool, len(new))
+	for _, v := range new {
+		newSet[v] = true
+		if !oldSet[v] {
+			added = append(added, v)
+		}
+	}
+	for _, v := range old {
+		if !newSet[v] {
+			removed = append(removed, v)
+		}
+	}
+	return added, removed
+}
+
+// diffAttachments - attachment dibedakan lewat FileURL (nama file disimpan dengan timestamp)
+func diffAttachments(old, new []models.Attachment) (added, removed []models.Attachment) {
+	oldSet := make(map[string]bool, len(old))
+	for _, a := range old {
+		oldSet[a.FileURL] = true
+	}
+	newSet := make(map[string]bool, len(new))
+	for _, a := range new {
+		newSet[a.FileURL] = true
+		if !oldSet[a.FileURL] {
+			added = append(added, a)
+		}
+	}
+	for _, a := range old {
+		if !newSet[a.FileURL] {
+			removed = append(removed, a)
+		}
+	}
+	return added, removed
+}
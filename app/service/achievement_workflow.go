@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"UAS/app/models"
+	"UAS/app/workflow"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var errAchievementDetailsNotFound = errors.New("achievement details not found")
+
+// workflowStages - stage verifikasi yang berlaku untuk achievement ini (tergantung tipe, level & poin)
+func (s *AchievementService) workflowStages(ctx context.Context, ref *models.AchievementReference) ([]workflow.Stage, error) {
+	achievement, err := s.achievementRepo.GetAchievementByID(ctx, ref.MongoAchievementID)
+	if err != nil {
+		return nil, err
+	}
+	if achievement == nil {
+		return nil, errAchievementDetailsNotFound
+	}
+	return s.workflows.Stages(achievement), nil
+}
+
+func firstStageName(stages []workflow.Stage) string {
+	if len(stages) == 0 {
+		return ""
+	}
+	return stages[0].Name
+}
+
+// storedStage - nilai workflow_stage apa adanya ("" jika NULL), dipakai sebagai guard AdvanceStage
+func storedStage(ref *models.AchievementReference) string {
+	if ref.WorkflowStage == nil {
+		return ""
+	}
+	return *ref.WorkflowStage
+}
+
+// authorizeStage - cek apakah user penanggung jawab stage saat ini. Mengembalikan stage
+// beserta index-nya; stage nil berarti workflow tidak punya stage yang berlaku.
+func (s *AchievementService) authorizeStage(c *fiber.Ctx, ref *models.AchievementReference, stages []workflow.Stage) (*workflow.Stage, int, bool, error) {
+	stage, index := workflow.Current(stages, storedStage(ref))
+	if stage == nil {
+		return nil, -1, false, nil
+	}
+
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return nil, -1, false, err
+	}
+	resource, err := s.authorizer.AchievementResource(ref)
+	if err != nil {
+		return nil, -1, false, err
+	}
+
+	return stage, index, s.authorizer.Satisfies(actor, stage.Roles, stage.Relations, resource), nil
+}
+
+// stageProgress - posisi setiap stage relatif terhadap status achievement saat ini
+func stageProgress(ref *models.AchievementReference, stages []workflow.Stage) []fiber.Map {
+	_, currentIndex := workflow.Current(stages, storedStage(ref))
+
+	progress := make([]fiber.Map, 0, len(stages))
+	for i, stage := range stages {
+		state := "pending"
+		switch ref.Status {
+		case models.AchievementStatusVerified:
+			state = "approved"
+		case models.AchievementStatusSubmitted:
+			if i < currentIndex {
+				state = "approved"
+			} else if i == currentIndex {
+				state = "current"
+			}
+		}
+		progress = append(progress, fiber.Map{
+			"name":  stage.Name,
+			"state": state,
+		})
+	}
+	return progress
+}
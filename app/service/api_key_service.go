@@ -0,0 +1,351 @@
+package service
+
+import (
+	"crypto/subtle"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+var errInvalidAPIKey = errors.New("invalid api key")
+
+type APIKeyService struct {
+	apiKeyRepo        repository.APIKeyRepository
+	userRepo          repository.UserRepository
+	roleRepo          repository.RoleRepository
+	permissionService *PermissionService
+}
+
+func NewAPIKeyService(
+	apiKeyRepo repository.APIKeyRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	permissionService *PermissionService,
+) *APIKeyService {
+	return &APIKeyService{
+		apiKeyRepo:        apiKeyRepo,
+		userRepo:          userRepo,
+		roleRepo:          roleRepo,
+		permissionService: permissionService,
+	}
+}
+
+// AuthenticateAPIKey - dipakai middleware.RequireAuth. Permission efektif = permission key
+// yang masih dimiliki role pemiliknya saat ini.
+func (s *APIKeyService) AuthenticateAPIKey(rawKey string) (*models.APIKey, []string, error) {
+	parts := strings.SplitN(rawKey, "_", 3)
+	if len(parts) != 3 || parts[0] != models.APIKeyPrefix {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByPrefix(parts[1])
+	if err != nil {
+		return nil, nil, err
+	}
+	if apiKey == nil {
+		return nil, nil, errInvalidAPIKey
+	}
+	if subtle.ConstantTimeCompare([]byte(utils.HashToken(rawKey)), []byte(apiKey.KeyHash)) != 1 {
+		return nil, nil, errInvalidAPIKey
+	}
+	if !apiKey.IsUsable(time.Now()) {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	user, err := s.userRepo.GetByID(apiKey.UserID)
+	if err != nil {
+		return nil, nil, err
+	}
+	if user == nil {
+		return nil, nil, errInvalidAPIKey
+	}
+
+	rolePermissions, err := s.permissionService.GetPermissions(user.RoleID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	permissions := []string{}
+	for _, p := range apiKey.Permissions {
+		if contains(rolePermissions, p) {
+			permissions = append(permissions, p)
+		}
+	}
+
+	if err := s.apiKeyRepo.TouchLastUsed(apiKey.ID); err != nil {
+		log.Printf("Warning: failed to update last_used_at of API key %s: %v", apiKey.ID, err)
+	}
+
+	return apiKey, permissions, nil
+}
+
+// Create godoc
+// @Summary Create API key
+// @Description Create a scoped API key for integrations. Permissions must be a subset of the owner's role permissions. Admins may create keys for another user (service account) via userId. The full key is only returned once.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateAPIKeyRequest true "API key data"
+// @Success 201 {object} models.CreateAPIKeyResponse "API key created"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Validation failed"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Not allowed to create keys for this user"
+// @Failure 404 {object} map[string]interface{} "Not Found - User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /api-keys [post]
+func (s *APIKeyService) Create(c *fiber.Ctx) error {
+	if _, isAPIKey := c.Locals("api_key").(*models.APIKey); isAPIKey {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "API keys cannot be used to manage API keys",
+		})
+	}
+
+	var req models.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "name is required",
+		})
+	}
+	if len(req.Permissions) == 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "at least one permission is required",
+		})
+	}
+	if req.ExpiresInDays != nil && *req.ExpiresInDays <= 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "expiresInDays must be greater than 0",
+		})
+	}
+
+	ownerID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if req.UserID != nil && *req.UserID != "" && *req.UserID != ownerID.String() {
+		if !s.isAdmin(c) {
+			return c.Status(403).JSON(fiber.Map{
+				"error": "Only admin can create API keys for other users",
+			})
+		}
+		targetID, err := uuid.Parse(*req.UserID)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+		ownerID = targetID
+	}
+
+	owner, err := s.userRepo.GetByID(ownerID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if owner == nil || !owner.IsActive {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found or inactive",
+		})
+	}
+
+	rolePermissions, err := s.roleRepo.GetPermissionNamesByRoleID(owner.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role permissions",
+			"details": err.Error(),
+		})
+	}
+
+	permissions := []string{}
+	invalid := []string{}
+	for _, p := range req.Permissions {
+		if contains(permissions, p) {
+			continue
+		}
+		if !contains(rolePermissions, p) {
+			invalid = append(invalid, p)
+			continue
+		}
+		permissions = append(permissions, p)
+	}
+	if len(invalid) > 0 {
+		return c.Status(400).JSON(fiber.Map{
+			"error":               "Permissions not granted to the key owner's role",
+			"invalid_permissions": invalid,
+		})
+	}
+
+	rawKey, prefix, err := utils.GenerateAPIKey(models.APIKeyPrefix)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate API key",
+			"details": err.Error(),
+		})
+	}
+
+	apiKey := models.APIKey{
+		UserID:      owner.ID,
+		Name:        req.Name,
+		Prefix:      prefix,
+		KeyHash:     utils.HashToken(rawKey),
+		Permissions: permissions,
+	}
+	if req.ExpiresInDays != nil {
+		expiresAt := time.Now().AddDate(0, 0, *req.ExpiresInDays)
+		apiKey.ExpiresAt = &expiresAt
+	}
+
+	if err := s.apiKeyRepo.Create(&apiKey); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create API key",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "API key created. Store the key now, it will not be shown again",
+		"data": models.CreateAPIKeyResponse{
+			APIKey: apiKey,
+			Key:    rawKey,
+		},
+	})
+}
+
+// List godoc
+// @Summary List API keys
+// @Description List API keys of the authenticated user. Admins may pass userId to list the keys of another user.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId query string false "User ID (admin only)"
+// @Success 200 {object} map[string]interface{} "List of API keys"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid user ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /api-keys [get]
+func (s *APIKeyService) List(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	if param := c.Query("userId"); param != "" && param != userID.String() {
+		if !s.isAdmin(c) {
+			return c.Status(403).JSON(fiber.Map{
+				"error": "Only admin can list API keys of other users",
+			})
+		}
+		targetID, err := uuid.Parse(param)
+		if err != nil {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Invalid user ID",
+			})
+		}
+		userID = targetID
+	}
+
+	keys, err := s.apiKeyRepo.GetByUserID(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get API keys",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": keys,
+	})
+}
+
+// Revoke godoc
+// @Summary Revoke API key
+// @Description Revoke an API key. Owners can revoke their own keys, admins can revoke any key.
+// @Tags API Keys
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "API key ID (UUID)"
+// @Success 200 {object} map[string]interface{} "API key revoked"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 404 {object} map[string]interface{} "Not Found - API key not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /api-keys/{id} [delete]
+func (s *APIKeyService) Revoke(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid API key ID",
+		})
+	}
+
+	apiKey, err := s.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get API key",
+			"details": err.Error(),
+		})
+	}
+	if apiKey == nil || (apiKey.UserID != userID && !s.isAdmin(c)) {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "API key not found",
+		})
+	}
+
+	if _, err := s.apiKeyRepo.Revoke(id); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke API key",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
+
+// isAdmin - API key tidak pernah dianggap admin untuk manajemen key
+func (s *APIKeyService) isAdmin(c *fiber.Ctx) bool {
+	if _, isAPIKey := c.Locals("api_key").(*models.APIKey); isAPIKey {
+		return false
+	}
+
+	roleID, ok := c.Locals("role_id").(uuid.UUID)
+	if !ok {
+		return false
+	}
+
+	role, err := s.roleRepo.GetByID(roleID)
+	return err == nil && role != nil && role.Name == "Admin"
+}
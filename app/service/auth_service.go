@@ -1,10 +1,17 @@
 package service
 
 import (
+	"errors"
 	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"UAS/app/models"
 	"UAS/app/repository"
+	"UAS/config"
 	"UAS/utils"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,10 +19,17 @@ import (
 )
 
 type AuthService struct {
-	userRepo     repository.UserRepository
-	roleRepo     repository.RoleRepository
-	studentRepo  repository.StudentRepository
-	lecturerRepo repository.LecturerRepository
+	userRepo            repository.UserRepository
+	roleRepo            repository.RoleRepository
+	studentRepo         repository.StudentRepository
+	lecturerRepo        repository.LecturerRepository
+	tokenRevocationRepo repository.TokenRevocationRepository
+	refreshTokenRepo    repository.RefreshTokenRepository
+	passwordResetRepo   repository.PasswordResetRepository
+	loginAttemptRepo    repository.LoginAttemptRepository
+	twoFactorRepo       repository.TwoFactorRepository
+	sessionRepo         repository.SessionRepository
+	mailer              utils.Mailer
 }
 
 
@@ -24,12 +38,80 @@ func NewAuthService(
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	tokenRevocationRepo repository.TokenRevocationRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	sessionRepo repository.SessionRepository,
+	mailer utils.Mailer,
 ) *AuthService {
 	return &AuthService{
-		userRepo:     userRepo,
-		roleRepo:     roleRepo,
-		studentRepo:  studentRepo,
-		lecturerRepo: lecturerRepo,
+		userRepo:            userRepo,
+		roleRepo:            roleRepo,
+		studentRepo:         studentRepo,
+		lecturerRepo:        lecturerRepo,
+		tokenRevocationRepo: tokenRevocationRepo,
+		refreshTokenRepo:    refreshTokenRepo,
+		passwordResetRepo:   passwordResetRepo,
+		loginAttemptRepo:    loginAttemptRepo,
+		twoFactorRepo:       twoFactorRepo,
+		sessionRepo:         sessionRepo,
+		mailer:              mailer,
+	}
+}
+
+// issueRefreshToken - buat refresh token baru di dalam family dan simpan ke database.
+// Jika parentJTI diisi, token lama dirotasi secara atomik.
+func (s *AuthService) issueRefreshToken(userID, familyID uuid.UUID, parentJTI *string) (string, error) {
+	refreshToken, claims, err := utils.GenerateRefreshToken(userID, familyID)
+	if err != nil {
+		return "", err
+	}
+
+	stored := &models.RefreshToken{
+		JTI:       claims.ID,
+		FamilyID:  familyID,
+		UserID:    userID,
+		ParentJTI: parentJTI,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}
+
+	if parentJTI == nil {
+		err = s.refreshTokenRepo.StoreToken(stored)
+	} else {
+		err = s.refreshTokenRepo.Rotate(*parentJTI, stored)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+// handleRefreshTokenReuse - token yang sudah dirotasi dipakai lagi: kemungkinan dicuri,
+// seluruh family di-revoke supaya pencuri maupun pemilik asli harus login ulang.
+func (s *AuthService) handleRefreshTokenReuse(c *fiber.Ctx, token *models.RefreshToken) {
+	log.Printf(
+		"SECURITY: refresh token reuse detected (possible theft) user=%s family=%s jti=%s ip=%s ua=%q",
+		token.UserID, token.FamilyID, token.JTI, c.IP(), c.Get("User-Agent"),
+	)
+
+	if err := s.refreshTokenRepo.RevokeFamily(token.FamilyID, "refresh token reuse detected"); err != nil {
+		log.Printf("ERROR: failed to revoke refresh token family %s: %v", token.FamilyID, err)
+	}
+
+	// Access token yang sudah dipegang pencuri juga ditolak
+	session, err := s.sessionRepo.GetByFamilyID(token.FamilyID)
+	if err != nil {
+		log.Printf("ERROR: failed to get session of family %s: %v", token.FamilyID, err)
+		return
+	}
+	if session != nil {
+		if err := s.tokenRevocationRepo.RevokeSession(session.ID, session.UserID, "refresh token reuse detected", utils.AccessTokenTTL); err != nil {
+			log.Printf("ERROR: failed to revoke session %s: %v", session.ID, err)
+		}
 	}
 }
 
@@ -41,9 +123,12 @@ func NewAuthService(
 // @Produce json
 // @Param request body models.LoginRequest true "Login credentials"
 // @Success 200 {object} models.LoginResponse "Login successful"
+// @Success 202 {object} models.TwoFactorChallengeResponse "Password accepted, two-factor code or enrollment required"
 // @Failure 400 {object} map[string]interface{} "Bad Request - Invalid request body"
 // @Failure 401 {object} map[string]interface{} "Unauthorized - Invalid credentials"
 // @Failure 403 {object} map[string]interface{} "Forbidden - Account inactive"
+// @Failure 423 {object} map[string]interface{} "Locked - Too many failed attempts, account temporarily locked"
+// @Failure 429 {object} map[string]interface{} "Too Many Requests - Too many failed attempts from this IP"
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /auth/login [post]
 func (s *AuthService) Login(c *fiber.Ctx) error {
@@ -54,8 +139,29 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
+	identifier := req.Username
+	if identifier == "" {
+		identifier = req.Email
+	}
+	protection := config.LoginProtectionConfig()
+
+	// Batasi percobaan gagal per IP
+	ipFailures, err := s.loginAttemptRepo.CountRecentFailuresByIP(c.IP(), time.Now().Add(-protection.IPWindow))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking login attempts",
+			"details": err.Error(),
+		})
+	}
+	if ipFailures >= protection.MaxFailedAttemptsPerIP {
+		s.recordLoginAttempt(c, nil, identifier, models.LoginFailureIPRateLimited, "")
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(protection.IPWindow.Seconds())))
+		return c.Status(429).JSON(fiber.Map{
+			"error": "Too many failed login attempts, try again later",
+		})
+	}
+
 	var user *models.User
-	var err error
 
 	user, err = s.userRepo.GetByUsername(req.Username)
 	if err != nil {
@@ -76,23 +182,32 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 	}
 
 	if user == nil {
+		s.recordLoginAttempt(c, nil, identifier, models.LoginFailureUnknownUser, "")
 		return c.Status(401).JSON(fiber.Map{
 			"error": "Invalid credentials",
 		})
 	}
 
+	if locked, err := s.RejectLockedAccount(c, user, identifier); locked {
+		return err
+	}
+
 	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
-		return c.Status(401).JSON(fiber.Map{
-			"error": "Invalid credentials",
-		})
+		return s.handleFailedLogin(c, user, identifier, models.LoginFailureInvalidPassword, protection)
 	}
 
 	if !user.IsActive {
+		s.recordLoginAttempt(c, &user.ID, identifier, models.LoginFailureAccountInactive, "")
 		return c.Status(403).JSON(fiber.Map{
 			"error": "Account is inactive",
 		})
 	}
 
+	return s.afterPrimaryAuth(c, user, identifier)
+}
+
+// afterPrimaryAuth - faktor pertama (password atau SSO) sudah lolos, lanjut ke 2FA jika perlu
+func (s *AuthService) afterPrimaryAuth(c *fiber.Ctx, user *models.User, identifier string) error {
 	role, err := s.roleRepo.GetByID(user.RoleID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -101,13 +216,47 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
+	// Login belum selesai sebelum kode 2FA diverifikasi
+	twoFactor, err := s.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking two-factor status",
+			"details": err.Error(),
+		})
+	}
+	if twoFactor != nil && twoFactor.Enabled {
+		return twoFactorChallengeResponse(c, user.ID, models.TwoFactorPurposeVerify)
+	}
+	if role != nil && role.RequireTwoFactor {
+		return twoFactorChallengeResponse(c, user.ID, models.TwoFactorPurposeSetup)
+	}
+
+	return s.completeLogin(c, user, role, identifier)
+}
+
+// completeLogin - semua faktor sudah lolos: reset counter gagal, catat login dan terbitkan token
+func (s *AuthService) completeLogin(c *fiber.Ctx, user *models.User, role *models.Role, identifier string) error {
+	if err := s.loginAttemptRepo.ResetFailures(user.ID); err != nil {
+		log.Printf("Warning: failed to reset login failures for user %s: %v", user.ID, err)
+	}
+	s.recordLoginAttempt(c, &user.ID, identifier, "", "")
+
 	permissions, err := s.roleRepo.GetPermissionNamesByRoleID(user.RoleID)
 	if err != nil {
 		permissions = []string{}
 		fmt.Printf("Warning: Failed to get permissions for role %s: %v\n", role.Name, err)
 	}
 
-	token, err := utils.GenerateToken(user, permissions)
+	family, err := s.refreshTokenRepo.CreateFamily(user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to create refresh token family",
+			"details": err.Error(),
+		})
+	}
+
+	sessionID := uuid.New()
+	token, accessClaims, err := utils.GenerateToken(user, sessionID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate token",
@@ -115,7 +264,22 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	refreshToken, err := utils.GenerateRefreshToken(user.ID)
+	if err := s.sessionRepo.Create(&models.UserSession{
+		ID:              sessionID,
+		UserID:          user.ID,
+		FamilyID:        family.ID,
+		UserAgent:       c.Get(fiber.HeaderUserAgent),
+		IPAddress:       c.IP(),
+		AccessJTI:       accessClaims.ID,
+		AccessExpiresAt: accessClaims.ExpiresAt.Time,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to create session",
+			"details": err.Error(),
+		})
+	}
+
+	refreshToken, err := s.issueRefreshToken(user.ID, family.ID, nil)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate refresh token",
@@ -134,9 +298,146 @@ func (s *AuthService) Login(c *fiber.Ctx) error {
 	return c.JSON(response)
 }
 
+func twoFactorChallengeResponse(c *fiber.Ctx, userID uuid.UUID, purpose string) error {
+	challengeToken, err := utils.GenerateTwoFactorChallengeToken(userID, purpose)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate challenge token",
+			"details": err.Error(),
+		})
+	}
+
+	return c.Status(202).JSON(models.TwoFactorChallengeResponse{
+		TwoFactorRequired:      purpose == models.TwoFactorPurposeVerify,
+		TwoFactorSetupRequired: purpose == models.TwoFactorPurposeSetup,
+		ChallengeToken:         challengeToken,
+		ExpiresIn:              int(utils.TwoFactorChallengeTTL.Seconds()),
+	})
+}
+
+// recordLoginAttempt - failureReason kosong berarti login berhasil. Gagal mencatat tidak menggagalkan login.
+func (s *AuthService) recordLoginAttempt(c *fiber.Ctx, userID *uuid.UUID, identifier, failureReason, lockoutReason string) {
+	attempt := &models.LoginAttempt{
+		UserID:     userID,
+		Identifier: identifier,
+		IPAddress:  c.IP(),
+		UserAgent:  c.Get(fiber.HeaderUserAgent),
+		Success:    failureReason == "",
+	}
+	if failureReason != "" {
+		attempt.FailureReason = &failureReason
+	}
+	if lockoutReason != "" {
+		attempt.LockoutReason = &lockoutReason
+	}
+
+	if err := s.loginAttemptRepo.RecordAttempt(attempt); err != nil {
+		log.Printf("Warning: failed to record login attempt for %q: %v", identifier, err)
+	}
+}
+
+// handleFailedLogin - hitung kegagalan (password atau kode 2FA salah) dan kunci akun
+// dengan backoff eksponensial jika melewati batas
+func (s *AuthService) handleFailedLogin(c *fiber.Ctx, user *models.User, identifier, failureReason string, protection config.LoginProtectionSettings) error {
+	lockout, err := s.loginAttemptRepo.RegisterFailure(user.ID)
+	if err != nil {
+		s.recordLoginAttempt(c, &user.ID, identifier, failureReason, "")
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error recording failed login",
+			"details": err.Error(),
+		})
+	}
+
+	if lockout.FailedAttempts < protection.MaxFailedAttempts {
+		s.recordLoginAttempt(c, &user.ID, identifier, failureReason, "")
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid credentials",
+		})
+	}
+
+	duration := lockoutDuration(lockout.LockoutCount, protection)
+	lockedUntil := time.Now().Add(duration)
+	reason := fmt.Sprintf("%d consecutive failed login attempts, locked for %s", lockout.FailedAttempts, duration)
+
+	if err := s.loginAttemptRepo.Lock(user.ID, lockedUntil, reason); err != nil {
+		s.recordLoginAttempt(c, &user.ID, identifier, failureReason, "")
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error locking account",
+			"details": err.Error(),
+		})
+	}
+
+	log.Printf("SECURITY: account %s locked until %s after %d failed logins (ip=%s)",
+		user.ID, lockedUntil.Format(time.RFC3339), lockout.FailedAttempts, c.IP())
+	s.recordLoginAttempt(c, &user.ID, identifier, failureReason, reason)
+
+	return accountLockedResponse(c, lockedUntil)
+}
+
+// ==================== LANGKAH LOGIN (dipakai TwoFactorService & OIDCService) ====================
+
+// RejectLockedAccount - jika akun sedang terkunci, attempt dicatat dan respons error ditulis.
+// locked = true berarti pemanggil langsung mengembalikan err.
+func (s *AuthService) RejectLockedAccount(c *fiber.Ctx, user *models.User, identifier string) (bool, error) {
+	lockout, err := s.loginAttemptRepo.GetLockout(user.ID)
+	if err != nil {
+		return true, c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking account lockout",
+			"details": err.Error(),
+		})
+	}
+	if lockout.IsLocked(time.Now()) {
+		s.recordLoginAttempt(c, &user.ID, identifier, models.LoginFailureAccountLocked, "")
+		return true, accountLockedResponse(c, *lockout.LockedUntil)
+	}
+	return false, nil
+}
+
+// RecordFailedAttempt - catat login gagal yang tidak dihitung untuk lockout (mis. user tidak dikenal)
+func (s *AuthService) RecordFailedAttempt(c *fiber.Ctx, userID *uuid.UUID, identifier, failureReason string) {
+	s.recordLoginAttempt(c, userID, identifier, failureReason, "")
+}
+
+// FailLogin - faktor login salah, dihitung untuk lockout sesuai konfigurasi
+func (s *AuthService) FailLogin(c *fiber.Ctx, user *models.User, identifier, failureReason string) error {
+	return s.handleFailedLogin(c, user, identifier, failureReason, config.LoginProtectionConfig())
+}
+
+// ContinueLogin - faktor pertama lolos, lanjut ke 2FA jika perlu
+func (s *AuthService) ContinueLogin(c *fiber.Ctx, user *models.User, identifier string) error {
+	return s.afterPrimaryAuth(c, user, identifier)
+}
+
+// CompleteLogin - semua faktor lolos, terbitkan token
+func (s *AuthService) CompleteLogin(c *fiber.Ctx, user *models.User, role *models.Role, identifier string) error {
+	return s.completeLogin(c, user, role, identifier)
+}
+
+// lockoutDuration - BaseLockout * 2^lockoutCount, maksimal MaxLockout
+func lockoutDuration(previousLockouts int, protection config.LoginProtectionSettings) time.Duration {
+	duration := protection.BaseLockout
+	for i := 0; i < previousLockouts && duration < protection.MaxLockout; i++ {
+		duration *= 2
+	}
+	if duration > protection.MaxLockout {
+		duration = protection.MaxLockout
+	}
+	return duration
+}
+
+func accountLockedResponse(c *fiber.Ctx, lockedUntil time.Time) error {
+	retryAfter := int(time.Until(lockedUntil).Seconds()) + 1
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return c.Status(423).JSON(fiber.Map{
+		"error":       "Account is temporarily locked due to too many failed login attempts",
+		"lockedUntil": lockedUntil,
+		"retryAfter":  retryAfter,
+	})
+}
+
 // RefreshToken godoc
 // @Summary Refresh access token
-// @Description Get new access token using refresh token
+// @Description Get new access token using refresh token. Refresh tokens are single-use: each call returns a new refresh token and invalidates the old one. Reusing an old refresh token revokes the whole token family
 // @Tags Authentication
 // @Accept json
 // @Produce json
@@ -181,6 +482,40 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
+	// Refresh token harus tercatat di database (token family)
+	storedToken, err := s.refreshTokenRepo.GetToken(claims.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking refresh token",
+			"details": err.Error(),
+		})
+	}
+	if storedToken == nil || storedToken.UserID != userID {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Refresh token not recognized",
+		})
+	}
+
+	family, err := s.refreshTokenRepo.GetFamily(storedToken.FamilyID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking refresh token family",
+			"details": err.Error(),
+		})
+	}
+	if family == nil || family.RevokedAt != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Refresh token has been revoked",
+		})
+	}
+
+	if storedToken.RotatedAt != nil {
+		s.handleRefreshTokenReuse(c, storedToken)
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Refresh token has already been used",
+		})
+	}
+
 	user, err := s.userRepo.GetByID(userID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
@@ -214,7 +549,20 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		permissions = []string{}
 	}
 
-	newToken, err := utils.GenerateToken(user, permissions)
+	session, err := s.sessionRepo.GetByFamilyID(family.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Error checking session",
+			"details": err.Error(),
+		})
+	}
+
+	sessionID := uuid.New()
+	if session != nil {
+		sessionID = session.ID
+	}
+
+	newToken, accessClaims, err := utils.GenerateToken(user, sessionID)
 	if err != nil {
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate new token",
@@ -222,14 +570,39 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 		})
 	}
 
-	newRefreshToken, err := utils.GenerateRefreshToken(user.ID)
+	newRefreshToken, err := s.issueRefreshToken(user.ID, family.ID, &storedToken.JTI)
 	if err != nil {
+		// Request lain merotasi token yang sama lebih dulu
+		if errors.Is(err, repository.ErrRefreshTokenReused) {
+			s.handleRefreshTokenReuse(c, storedToken)
+			return c.Status(401).JSON(fiber.Map{
+				"error": "Refresh token has already been used",
+			})
+		}
 		return c.Status(500).JSON(fiber.Map{
 			"error": "Failed to generate new refresh token",
 			"details": err.Error(),
 		})
 	}
 
+	// Family dari sebelum ada tabel sesi dibuatkan sesi baru
+	if session != nil {
+		err = s.sessionRepo.Touch(session.ID, c.IP(), c.Get(fiber.HeaderUserAgent), accessClaims.ID, accessClaims.ExpiresAt.Time)
+	} else {
+		err = s.sessionRepo.Create(&models.UserSession{
+			ID:              sessionID,
+			UserID:          user.ID,
+			FamilyID:        family.ID,
+			UserAgent:       c.Get(fiber.HeaderUserAgent),
+			IPAddress:       c.IP(),
+			AccessJTI:       accessClaims.ID,
+			AccessExpiresAt: accessClaims.ExpiresAt.Time,
+		})
+	}
+	if err != nil {
+		log.Printf("Warning: failed to update session for family %s: %v", family.ID, err)
+	}
+
 	return c.JSON(fiber.Map{
 		"token":        newToken,
 		"refreshToken": newRefreshToken,
@@ -241,20 +614,108 @@ func (s *AuthService) RefreshToken(c *fiber.Ctx) error {
 
 // Logout godoc
 // @Summary User logout
-// @Description Logout user (client-side token deletion)
+// @Description Logout user. The current access token is revoked server-side; the refresh token is revoked too when sent in the body
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Security BearerAuth
+// @Param request body map[string]interface{} false "Refresh token to revoke" SchemaExample({"refreshToken": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."})
 // @Success 200 {object} map[string]interface{} "Logout successful"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Refresh token belongs to another user"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Not authenticated"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /auth/logout [post]
 func (s *AuthService) Logout(c *fiber.Ctx) error {
-	// Simple logout - client side delete tokens
-	// Untuk production, bisa implement token blacklist atau revoke di sini
+	claims, ok := c.Locals("claims").(*models.JWTClaims)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+	// Body opsional
+	_ = c.BodyParser(&req)
+
+	// Revoke access token yang sedang dipakai (token lama tanpa jti dilewati)
+	if claims.ID != "" {
+		accessExpiresAt := time.Now()
+		if claims.ExpiresAt != nil {
+			accessExpiresAt = claims.ExpiresAt.Time
+		}
+		if err := s.tokenRevocationRepo.RevokeToken(&models.RevokedToken{
+			JTI:       claims.ID,
+			UserID:    userID,
+			TokenType: models.TokenTypeAccess,
+			Reason:    "logout",
+			ExpiresAt: accessExpiresAt,
+		}); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Failed to revoke access token",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// Access token lain dari sesi yang sama (terbit sebelum refresh) ikut ditolak
+	if sessionID, err := uuid.Parse(claims.SessionID); err == nil {
+		if err := s.tokenRevocationRepo.RevokeSession(sessionID, userID, "logout", utils.AccessTokenTTL); err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Failed to revoke session",
+				"details": err.Error(),
+			})
+		}
+	}
+
+	// Revoke refresh token jika dikirim
+	if req.RefreshToken != "" {
+		refreshClaims, err := utils.ValidateRefreshToken(req.RefreshToken)
+		if err == nil && refreshClaims.ID != "" {
+			if refreshClaims.Subject != userID.String() {
+				return c.Status(400).JSON(fiber.Map{
+					"error": "Refresh token does not belong to current user",
+				})
+			}
+
+			refreshExpiresAt := time.Now()
+			if refreshClaims.ExpiresAt != nil {
+				refreshExpiresAt = refreshClaims.ExpiresAt.Time
+			}
+			if err := s.tokenRevocationRepo.RevokeToken(&models.RevokedToken{
+				JTI:       refreshClaims.ID,
+				UserID:    userID,
+				TokenType: models.TokenTypeRefresh,
+				Reason:    "logout",
+				ExpiresAt: refreshExpiresAt,
+			}); err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "Failed to revoke refresh token",
+					"details": err.Error(),
+				})
+			}
+
+			if familyID, err := uuid.Parse(refreshClaims.FamilyID); err == nil {
+				if err := s.refreshTokenRepo.RevokeFamily(familyID, "logout"); err != nil {
+					return c.Status(500).JSON(fiber.Map{
+						"error":   "Failed to revoke refresh token family",
+						"details": err.Error(),
+					})
+				}
+			}
+		}
+	}
 
 	return c.JSON(fiber.Map{
 		"message": "Logged out successfully",
-		"note":    "Please delete tokens on client side",
 	})
 }
 
@@ -425,4 +886,207 @@ func (s *AuthService) ChangePassword(c *fiber.Ctx) error {
 	return c.JSON(fiber.Map{
 		"message": "Password updated successfully",
 	})
-}
\ No newline at end of file
+}
+// forgotPasswordMessage - respons selalu sama supaya tidak bisa dipakai untuk menebak email terdaftar
+const forgotPasswordMessage = "If the email is registered, a password reset link has been sent"
+
+// ForgotPassword godoc
+// @Summary Request password reset
+// @Description Send a single-use password reset link to the user's email. The response is the same whether or not the email is registered.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ForgotPasswordRequest true "Account email"
+// @Success 200 {object} map[string]interface{} "Reset link sent if the email is registered"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Email is required"
+// @Router /auth/forgot-password [post]
+func (s *AuthService) ForgotPassword(c *fiber.Ctx) error {
+	var req models.ForgotPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Email is required",
+		})
+	}
+
+	user, err := s.userRepo.GetByEmail(email)
+	if err != nil {
+		log.Printf("Warning: forgot-password lookup failed: %v", err)
+		return c.JSON(fiber.Map{"message": forgotPasswordMessage})
+	}
+	if user == nil || !user.IsActive {
+		return c.JSON(fiber.Map{"message": forgotPasswordMessage})
+	}
+
+	// Token & email dikirim di background supaya waktu respons tidak membedakan
+	// email terdaftar dan tidak terdaftar. IP disalin karena buffer fiber dipakai ulang.
+	ip := strings.Clone(c.IP())
+	go func() {
+		if err := s.sendPasswordResetEmail(user, ip); err != nil {
+			log.Printf("Warning: failed to send password reset email to user %s: %v", user.ID, err)
+		}
+	}()
+
+	return c.JSON(fiber.Map{"message": forgotPasswordMessage})
+}
+
+// sendPasswordResetEmail - token lama dibatalkan, hanya link terbaru yang berlaku
+func (s *AuthService) sendPasswordResetEmail(user *models.User, ip string) error {
+	cfg := config.PasswordResetConfig()
+
+	token, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepo.InvalidateAllForUser(user.ID); err != nil {
+		return err
+	}
+
+	if err := s.passwordResetRepo.Create(&models.PasswordResetToken{
+		UserID:      user.ID,
+		TokenHash:   utils.HashToken(token),
+		RequestedIP: ip,
+		ExpiresAt:   time.Now().Add(cfg.TTL),
+	}); err != nil {
+		return err
+	}
+
+	separator := "?"
+	if strings.Contains(cfg.URL, "?") {
+		separator = "&"
+	}
+	link := cfg.URL + separator + "token=" + url.QueryEscape(token)
+
+	return s.mailer.Send(utils.MailMessage{
+		To:      user.Email,
+		Subject: "Reset password",
+		Body: fmt.Sprintf(
+			"Halo %s,\n\nKami menerima permintaan reset password untuk akun Anda.\n"+
+				"Buka link berikut untuk membuat password baru (berlaku %d menit, hanya bisa dipakai sekali):\n\n%s\n\n"+
+				"Abaikan email ini jika Anda tidak meminta reset password.\n",
+			user.FullName, int(cfg.TTL.Minutes()), link,
+		),
+	})
+}
+
+// ResetPassword godoc
+// @Summary Reset password with token
+// @Description Set a new password using the token from the reset email. The token is single-use and all existing sessions of the user are revoked.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
+// @Success 200 {object} map[string]interface{} "Password has been reset"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Validation failed or token invalid/expired"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/reset-password [post]
+func (s *AuthService) ResetPassword(c *fiber.Ctx) error {
+	var req models.ResetPasswordRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Token == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Reset token is required",
+		})
+	}
+
+	if req.NewPassword != req.ConfirmPassword {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "New password and confirmation do not match",
+		})
+	}
+
+	if len(req.NewPassword) < 6 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "New password must be at least 6 characters",
+		})
+	}
+
+	resetToken, err := s.passwordResetRepo.GetValidByHash(utils.HashToken(req.Token))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to verify reset token",
+			"details": err.Error(),
+		})
+	}
+	if resetToken == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired reset token",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(resetToken.UserID)
+	if err != nil || user == nil || !user.IsActive {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired reset token",
+		})
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to hash password",
+			"details": err.Error(),
+		})
+	}
+
+	// Tandai terpakai dulu supaya request paralel dengan token yang sama ditolak
+	consumed, err := s.passwordResetRepo.MarkUsed(resetToken.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to consume reset token",
+			"details": err.Error(),
+		})
+	}
+	if !consumed {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired reset token",
+		})
+	}
+
+	if err := s.userRepo.UpdatePassword(user.ID, hashedPassword); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update password",
+			"details": err.Error(),
+		})
+	}
+
+	// Semua sesi lama harus login ulang dengan password baru
+	if err := s.tokenRevocationRepo.RevokeAllForUser(&models.UserTokenRevocation{
+		UserID: user.ID,
+		Reason: "password_reset",
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke existing sessions",
+			"details": err.Error(),
+		})
+	}
+	if err := s.refreshTokenRepo.RevokeAllFamiliesForUser(user.ID, "password_reset"); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke existing sessions",
+			"details": err.Error(),
+		})
+	}
+	if err := s.passwordResetRepo.InvalidateAllForUser(user.ID); err != nil {
+		log.Printf("Warning: failed to invalidate password reset tokens for user %s: %v", user.ID, err)
+	}
+	// Pemilik email sudah terbukti, lockout karena password salah tidak relevan lagi
+	if err := s.loginAttemptRepo.ResetFailures(user.ID); err != nil {
+		log.Printf("Warning: failed to reset login failures for user %s: %v", user.ID, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Password has been reset successfully",
+	})
+}
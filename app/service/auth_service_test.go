@@ -0,0 +1,210 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// fakeRefreshTokenRepo - token family di memori, Rotate menolak token yang sudah dirotasi
+type fakeRefreshTokenRepo struct {
+	repository.RefreshTokenRepository
+	families map[uuid.UUID]*models.RefreshTokenFamily
+	tokens   map[string]*models.RefreshToken
+}
+
+func (r *fakeRefreshTokenRepo) GetFamily(id uuid.UUID) (*models.RefreshTokenFamily, error) {
+	family, ok := r.families[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *family
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepo) RevokeFamily(id uuid.UUID, reason string) error {
+	now := time.Now()
+	r.families[id].RevokedAt = &now
+	r.families[id].RevokeReason = &reason
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) StoreToken(token *models.RefreshToken) error {
+	r.tokens[token.JTI] = token
+	return nil
+}
+
+func (r *fakeRefreshTokenRepo) GetToken(jti string) (*models.RefreshToken, error) {
+	token, ok := r.tokens[jti]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepo) Rotate(oldJTI string, newToken *models.RefreshToken) error {
+	old := r.tokens[oldJTI]
+	if old.RotatedAt != nil {
+		return repository.ErrRefreshTokenReused
+	}
+	now := time.Now()
+	old.RotatedAt = &now
+	r.tokens[newToken.JTI] = newToken
+	return nil
+}
+
+type fakeSessionRepo struct {
+	repository.SessionRepository
+	sessions map[uuid.UUID]*models.UserSession
+}
+
+func (r *fakeSessionRepo) GetByFamilyID(familyID uuid.UUID) (*models.UserSession, error) {
+	for _, session := range r.sessions {
+		if session.FamilyID == familyID {
+			return session, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeSessionRepo) Touch(id uuid.UUID, ip, userAgent, accessJTI string, accessExpiresAt time.Time) error {
+	r.sessions[id].AccessJTI = accessJTI
+	return nil
+}
+
+type fakeTokenRevocationRepo struct {
+	repository.TokenRevocationRepository
+	revokedSessions map[uuid.UUID]bool
+}
+
+func (r *fakeTokenRevocationRepo) RevokeSession(sessionID, userID uuid.UUID, reason string, ttl time.Duration) error {
+	r.revokedSessions[sessionID] = true
+	return nil
+}
+
+func TestRefreshTokenRotationAndReuseDetection(t *testing.T) {
+	role := &models.Role{ID: uuid.New(), Name: "Mahasiswa"}
+	user := &models.User{ID: uuid.New(), RoleID: role.ID, IsActive: true}
+	family := &models.RefreshTokenFamily{ID: uuid.New(), UserID: user.ID}
+	session := &models.UserSession{ID: uuid.New(), UserID: user.ID, FamilyID: family.ID}
+
+	refreshRepo := &fakeRefreshTokenRepo{
+		families: map[uuid.UUID]*models.RefreshTokenFamily{family.ID: family},
+		tokens:   map[string]*models.RefreshToken{},
+	}
+	revocations := &fakeTokenRevocationRepo{revokedSessions: map[uuid.UUID]bool{}}
+	svc := NewAuthService(
+		&fakeUserRepo{users: map[uuid.UUID]*models.User{user.ID: user}},
+		&fakeRoleRepo{roles: map[uuid.UUID]*models.Role{role.ID: role}},
+		nil, nil, revocations, refreshRepo, nil, nil, nil,
+		&fakeSessionRepo{sessions: map[uuid.UUID]*models.UserSession{session.ID: session}},
+		nil,
+	)
+
+	app := fiber.New()
+	app.Post("/auth/refresh", svc.RefreshToken)
+	refresh := func(token string) (int, string) {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refreshToken": "`+token+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		var body struct {
+			RefreshToken string `json:"refreshToken"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp.StatusCode, body.RefreshToken
+	}
+
+	first, err := svc.issueRefreshToken(user.ID, family.ID, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Rotasi normal: token lama ditukar token baru dalam family yang sama
+	status, second := refresh(first)
+	if status != fiber.StatusOK || second == "" || second == first {
+		t.Fatalf("first refresh = %d %q, want 200 with a new token", status, second)
+	}
+	if family.RevokedAt != nil {
+		t.Fatal("family revoked after a normal rotation")
+	}
+
+	// Token yang sudah dirotasi dipakai lagi: family dan sesinya di-revoke
+	if status, _ := refresh(first); status != fiber.StatusUnauthorized {
+		t.Fatalf("reused token status = %d, want 401", status)
+	}
+	if family.RevokedAt == nil {
+		t.Fatal("family not revoked after reuse")
+	}
+	if !revocations.revokedSessions[session.ID] {
+		t.Fatal("session access tokens not revoked after reuse")
+	}
+
+	// Token terbaru dari family yang sudah di-revoke juga ditolak
+	if status, _ := refresh(second); status != fiber.StatusUnauthorized {
+		t.Fatalf("token of revoked family status = %d, want 401", status)
+	}
+}
+
+func TestRefreshTokenRejectsUnknownOrForeignToken(t *testing.T) {
+	user := &models.User{ID: uuid.New(), IsActive: true}
+	familyID := uuid.New()
+	refreshRepo := &fakeRefreshTokenRepo{
+		families: map[uuid.UUID]*models.RefreshTokenFamily{familyID: {ID: familyID, UserID: user.ID}},
+		tokens:   map[string]*models.RefreshToken{},
+	}
+	svc := NewAuthService(
+		&fakeUserRepo{users: map[uuid.UUID]*models.User{user.ID: user}}, &fakeRoleRepo{},
+		nil, nil, nil, refreshRepo, nil, nil, nil, &fakeSessionRepo{}, nil,
+	)
+
+	// Tidak tercatat di database
+	unknown, _, err := utils.GenerateRefreshToken(user.ID, familyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tercatat, tetapi untuk user lain
+	foreign, claims, err := utils.GenerateRefreshToken(user.ID, familyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	refreshRepo.tokens[claims.ID] = &models.RefreshToken{JTI: claims.ID, FamilyID: familyID, UserID: uuid.New()}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not stored", unknown},
+		{"stored for another user", foreign},
+		{"not a jwt", "garbage"},
+	}
+
+	app := fiber.New()
+	app.Post("/auth/refresh", svc.RefreshToken)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/auth/refresh", strings.NewReader(`{"refreshToken": "`+tt.token+`"}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != fiber.StatusUnauthorized {
+				t.Fatalf("status = %d, want 401", resp.StatusCode)
+			}
+		})
+	}
+}
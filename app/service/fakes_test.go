@@ -0,0 +1,369 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/app/schema"
+	"UAS/app/workflow"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Fake repository: hanya method yang dipakai handler yang diimplementasikan,
+// sisanya panic lewat interface yang di-embed (nil).
+
+type fakeRoleRepo struct {
+	repository.RoleRepository
+	roles     map[uuid.UUID]*models.Role
+	removeErr error
+}
+
+func (r *fakeRoleRepo) GetByID(id uuid.UUID) (*models.Role, error) {
+	return r.roles[id], nil
+}
+
+func (r *fakeRoleRepo) GetPermissionNamesByRoleID(roleID uuid.UUID) ([]string, error) {
+	return []string{}, nil
+}
+
+func (r *fakeRoleRepo) RemovePermission(roleID, permissionID uuid.UUID) error {
+	return r.removeErr
+}
+
+type fakePermissionRepo struct {
+	repository.PermissionRepository
+	permissions map[uuid.UUID]*models.Permission
+}
+
+func (r *fakePermissionRepo) GetByID(id uuid.UUID) (*models.Permission, error) {
+	return r.permissions[id], nil
+}
+
+type fakeStudentRepo struct {
+	repository.StudentRepository
+	students map[uuid.UUID]*models.Student
+}
+
+func (r *fakeStudentRepo) GetByID(id uuid.UUID) (*models.Student, error) {
+	return r.students[id], nil
+}
+
+func (r *fakeStudentRepo) GetByUserID(userID uuid.UUID) (*models.Student, error) {
+	for _, student := range r.students {
+		if student.UserID == userID {
+			return student, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeStudentRepo) RemoveAdvisor(studentID uuid.UUID) error {
+	r.students[studentID].AdvisorID = nil
+	return nil
+}
+
+type fakeLecturerRepo struct {
+	repository.LecturerRepository
+}
+
+func (r *fakeLecturerRepo) GetByID(id uuid.UUID) (*models.Lecturer, error) {
+	return nil, nil
+}
+
+func (r *fakeLecturerRepo) GetByUserID(userID uuid.UUID) (*models.Lecturer, error) {
+	return nil, nil
+}
+
+type fakeUserRepo struct {
+	repository.UserRepository
+	users map[uuid.UUID]*models.User
+}
+
+func (r *fakeUserRepo) GetByID(id uuid.UUID) (*models.User, error) {
+	return r.users[id], nil
+}
+
+type fakeUserScopeRepo struct {
+	repository.UserScopeRepository
+}
+
+func (r *fakeUserScopeRepo) GetByUserID(userID uuid.UUID) ([]models.UserScope, error) {
+	return nil, nil
+}
+
+type fakeAchievementRefRepo struct {
+	repository.AchievementReferenceRepository
+	refs map[uuid.UUID]*models.AchievementReference
+
+	// Error yang dikembalikan transisi status (mis. repository.ErrStatusChanged saat kalah race)
+	transitionErr error
+	// Argumen guard terakhir yang diterima transisi
+	maxRevisions  int
+	expectedStage *string
+}
+
+func (r *fakeAchievementRefRepo) GetReferenceByID(id uuid.UUID) (*models.AchievementReference, error) {
+	ref, ok := r.refs[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *ref
+	return &copied, nil
+}
+
+func (r *fakeAchievementRefRepo) GetReferenceByMongoID(mongoID string) (*models.AchievementReference, error) {
+	for _, ref := range r.refs {
+		if ref.MongoAchievementID == mongoID {
+			copied := *ref
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeAchievementRefRepo) ResubmitAchievement(id uuid.UUID, firstStage string, maxRevisions int, change models.StatusChange) error {
+	r.maxRevisions = maxRevisions
+	if r.transitionErr != nil {
+		return r.transitionErr
+	}
+	r.refs[id].Status = models.AchievementStatusSubmitted
+	r.refs[id].Revision++
+	return nil
+}
+
+func (r *fakeAchievementRefRepo) AdvanceStage(id uuid.UUID, currentStage, nextStage string, change models.StatusChange) error {
+	r.expectedStage = &currentStage
+	if r.transitionErr != nil {
+		return r.transitionErr
+	}
+	r.refs[id].WorkflowStage = &nextStage
+	return nil
+}
+
+func (r *fakeAchievementRefRepo) VerifyAchievement(id uuid.UUID, currentStage string, award models.PointsResult, change models.StatusChange) error {
+	r.expectedStage = &currentStage
+	if r.transitionErr != nil {
+		return r.transitionErr
+	}
+	r.refs[id].Status = models.AchievementStatusVerified
+	r.refs[id].WorkflowStage = nil
+	r.refs[id].Points = award.Points
+	return nil
+}
+
+func (r *fakeAchievementRefRepo) RejectAchievement(id uuid.UUID, currentStage string, change models.StatusChange) error {
+	r.expectedStage = &currentStage
+	if r.transitionErr != nil {
+		return r.transitionErr
+	}
+	r.refs[id].Status = models.AchievementStatusRejected
+	r.refs[id].WorkflowStage = nil
+	return nil
+}
+
+func (r *fakeAchievementRefRepo) GetVerifiedReferences() ([]models.AchievementReference, error) {
+	var refs []models.AchievementReference
+	for _, ref := range r.refs {
+		if ref.Status == models.AchievementStatusVerified {
+			refs = append(refs, *ref)
+		}
+	}
+	return refs, nil
+}
+
+func (r *fakeAchievementRefRepo) UpdatePoints(id uuid.UUID, award models.PointsResult) error {
+	r.refs[id].Points = award.Points
+	r.refs[id].PointsRuleVersion = &award.RuleVersion
+	return nil
+}
+
+func (r *fakeAchievementRefRepo) TouchEditable(id uuid.UUID) error {
+	if r.transitionErr != nil {
+		return r.transitionErr
+	}
+	return nil
+}
+
+type fakeVersionRepo struct {
+	repository.AchievementVersionRepository
+	saved   int
+	saveErr error
+}
+
+func (r *fakeVersionRepo) CountVersions(ctx context.Context, achievementID string) (int64, error) {
+	return int64(r.saved), nil
+}
+
+func (r *fakeVersionRepo) SaveVersion(ctx context.Context, achievement *models.Achievement, revision int, savedBy uuid.UUID) (*models.AchievementVersion, error) {
+	if r.saveErr != nil {
+		return nil, r.saveErr
+	}
+	r.saved++
+	return &models.AchievementVersion{}, nil
+}
+
+type fakePointsRuleRepo struct {
+	repository.PointsRuleRepository
+}
+
+// GetLatest - belum ada aturan tersimpan, jadi aturan bawaan yang dipakai
+func (r *fakePointsRuleRepo) GetLatest() (*models.PointsRuleSet, error) {
+	return nil, nil
+}
+
+type fakeAchievementRepo struct {
+	repository.AchievementRepository
+	achievements map[string]*models.Achievement
+
+	updatePointsErr error
+}
+
+func (r *fakeAchievementRepo) GetAchievementByID(ctx context.Context, id string) (*models.Achievement, error) {
+	achievement, ok := r.achievements[id]
+	if !ok {
+		return nil, nil
+	}
+	copied := *achievement
+	return &copied, nil
+}
+
+func (r *fakeAchievementRepo) UpdateAchievement(ctx context.Context, id string, achievement *models.Achievement) error {
+	copied := *achievement
+	r.achievements[id] = &copied
+	return nil
+}
+
+func (r *fakeAchievementRepo) UpdatePoints(ctx context.Context, id string, points int) error {
+	if r.updatePointsErr != nil {
+		return r.updatePointsErr
+	}
+	r.achievements[id].Points = points
+	return nil
+}
+
+// FindDuplicateCandidates - semua prestasi lain; penyaringan dilakukan duplicate.Compare
+func (r *fakeAchievementRepo) FindDuplicateCandidates(ctx context.Context, excludeID primitive.ObjectID, certificationNumber string, hashes []string, eventDate *time.Time) ([]models.Achievement, error) {
+	var candidates []models.Achievement
+	for _, achievement := range r.achievements {
+		if achievement.ID != excludeID {
+			candidates = append(candidates, *achievement)
+		}
+	}
+	return candidates, nil
+}
+
+type fakeAchievementTypeRepo struct {
+	repository.AchievementTypeRepository
+}
+
+func (r *fakeAchievementTypeRepo) GetByCode(code string) (*models.AchievementType, error) {
+	return &models.AchievementType{Code: code, BuiltIn: true}, nil
+}
+
+type fakeMemberRepo struct {
+	repository.AchievementMemberRepository
+	members []models.AchievementMember
+}
+
+func (r *fakeMemberRepo) GetByAchievement(achievementRefID uuid.UUID) ([]models.AchievementMember, error) {
+	return r.members, nil
+}
+
+func (r *fakeMemberRepo) Get(achievementRefID, studentID uuid.UUID) (*models.AchievementMember, error) {
+	for i := range r.members {
+		if r.members[i].StudentID == studentID {
+			copied := r.members[i]
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeMemberRepo) Remove(achievementRefID, studentID uuid.UUID) error {
+	for i := range r.members {
+		if r.members[i].StudentID == studentID {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// achievementFixture - satu mahasiswa pemilik satu prestasi, dengan service yang memakai fake repository
+type achievementFixture struct {
+	svc          *AchievementService
+	refRepo      *fakeAchievementRefRepo
+	achievements *fakeAchievementRepo
+	versions     *fakeVersionRepo
+	ownerUser    *models.User
+	adminUser    *models.User
+	ref          *models.AchievementReference
+	achievement  *models.Achievement
+}
+
+func newAchievementFixture(achievementType string, details models.AchievementDetails) *achievementFixture {
+	studentRole := &models.Role{ID: uuid.New(), Name: "Mahasiswa"}
+	adminRole := &models.Role{ID: uuid.New(), Name: "Admin"}
+	ownerUser := &models.User{ID: uuid.New(), RoleID: studentRole.ID, FullName: "Mahasiswa", IsActive: true}
+	adminUser := &models.User{ID: uuid.New(), RoleID: adminRole.ID, FullName: "Admin", IsActive: true}
+	student := &models.Student{ID: uuid.New(), UserID: ownerUser.ID, StudentID: "2021001"}
+
+	achievement := &models.Achievement{
+		ID:              primitive.NewObjectID(),
+		StudentID:       student.ID,
+		AchievementType: achievementType,
+		Title:           "Prestasi",
+		Details:         details,
+	}
+	ref := &models.AchievementReference{
+		ID:                 uuid.New(),
+		StudentID:          student.ID,
+		MongoAchievementID: achievement.ID.Hex(),
+		Status:             models.AchievementStatusDraft,
+	}
+
+	roleRepo := &fakeRoleRepo{roles: map[uuid.UUID]*models.Role{studentRole.ID: studentRole, adminRole.ID: adminRole}}
+	studentRepo := &fakeStudentRepo{students: map[uuid.UUID]*models.Student{student.ID: student}}
+	lecturerRepo := &fakeLecturerRepo{}
+	userRepo := &fakeUserRepo{users: map[uuid.UUID]*models.User{ownerUser.ID: ownerUser, adminUser.ID: adminUser}}
+	refRepo := &fakeAchievementRefRepo{refs: map[uuid.UUID]*models.AchievementReference{ref.ID: ref}}
+	achievementRepo := &fakeAchievementRepo{achievements: map[string]*models.Achievement{achievement.ID.Hex(): achievement}}
+	versionRepo := &fakeVersionRepo{}
+
+	authorizer := authz.NewAuthorizer(roleRepo, studentRepo, lecturerRepo, &fakeUserScopeRepo{}, &fakeMemberRepo{})
+	svc := NewAchievementService(
+		achievementRepo, refRepo, versionRepo, studentRepo, lecturerRepo, userRepo, roleRepo,
+		authorizer, workflow.DefaultWorkflows(0), &fakeAchievementTypeRepo{}, schema.DefaultRegistry(),
+		NewPointsService(&fakePointsRuleRepo{}, refRepo, achievementRepo),
+	)
+
+	return &achievementFixture{
+		svc:          svc,
+		refRepo:      refRepo,
+		achievements: achievementRepo,
+		versions:     versionRepo,
+		ownerUser:    ownerUser,
+		adminUser:    adminUser,
+		ref:          ref,
+		achievement:  achievement,
+	}
+}
+
+// app - fiber app dengan user (seperti middleware.RequireAuth) dan satu route
+func (f *achievementFixture) app(user *models.User, method, path string, handler fiber.Handler) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		c.Locals("user", user)
+		c.Locals("user_id", user.ID)
+		c.Locals("role_id", user.RoleID)
+		return c.Next()
+	})
+	app.Add(method, path, handler)
+	return app
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"log"
+	"strings"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/config"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type ImpersonationService struct {
+	impersonationRepo   repository.ImpersonationRepository
+	userRepo            repository.UserRepository
+	roleRepo            repository.RoleRepository
+	tokenRevocationRepo repository.TokenRevocationRepository
+}
+
+func NewImpersonationService(
+	impersonationRepo repository.ImpersonationRepository,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	tokenRevocationRepo repository.TokenRevocationRepository,
+) *ImpersonationService {
+	return &ImpersonationService{
+		impersonationRepo:   impersonationRepo,
+		userRepo:            userRepo,
+		roleRepo:            roleRepo,
+		tokenRevocationRepo: tokenRevocationRepo,
+	}
+}
+
+// RecordImpersonatedRequest - dipanggil middleware.RequireAuth untuk setiap request impersonation
+func (s *ImpersonationService) RecordImpersonatedRequest(entry *models.ImpersonationAuditLog) error {
+	return s.impersonationRepo.RecordAudit(entry)
+}
+
+// Start godoc
+// @Summary Impersonate a user
+// @Description Issue a short-lived access token that acts as the target user ("login as"). The token carries an act claim naming the admin, has no refresh token, and every request made with it is audited. Changing password, 2FA, API keys and session revocation are blocked while impersonating. Admins and inactive users cannot be impersonated. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Target user ID (UUID)"
+// @Param request body models.StartImpersonationRequest true "Reason for impersonation"
+// @Success 201 {object} map[string]interface{} "Impersonation token issued"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID or missing reason"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Target cannot be impersonated"
+// @Failure 404 {object} map[string]interface{} "Not Found - User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/impersonate [post]
+func (s *ImpersonationService) Start(c *fiber.Ctx) error {
+	admin, ok := c.Locals("user").(*models.User)
+	if !ok || admin == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	targetID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req models.StartImpersonationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+	req.Reason = strings.TrimSpace(req.Reason)
+	if req.Reason == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Reason is required",
+		})
+	}
+
+	if targetID == admin.ID {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Cannot impersonate yourself",
+		})
+	}
+
+	target, err := s.userRepo.GetByID(targetID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get user",
+			"details": err.Error(),
+		})
+	}
+	if target == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+	if !target.IsActive {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Cannot impersonate an inactive user",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(target.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role",
+			"details": err.Error(),
+		})
+	}
+	roleName := ""
+	if role != nil {
+		roleName = role.Name
+	}
+	// Admin tidak boleh login sebagai admin lain
+	if roleName == "Admin" {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Cannot impersonate an admin",
+		})
+	}
+
+	session := &models.ImpersonationSession{
+		ID:           uuid.New(),
+		AdminID:      admin.ID,
+		TargetUserID: target.ID,
+		Reason:       req.Reason,
+		IPAddress:    c.IP(),
+		UserAgent:    c.Get("User-Agent"),
+	}
+
+	token, claims, err := utils.GenerateImpersonationToken(target, admin, session.ID, config.ImpersonationTTL())
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate token",
+			"details": err.Error(),
+		})
+	}
+	session.TokenJTI = claims.ID
+	session.ExpiresAt = claims.ExpiresAt.Time
+
+	if err := s.impersonationRepo.Create(session); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to start impersonation",
+			"details": err.Error(),
+		})
+	}
+
+	log.Printf("SECURITY: admin %s started impersonating user %s (session %s): %s", admin.ID, target.ID, session.ID, req.Reason)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Impersonation started",
+		"data": fiber.Map{
+			"token":           token,
+			"expiresAt":       session.ExpiresAt,
+			"impersonationId": session.ID,
+			"user": fiber.Map{
+				"id":       target.ID,
+				"username": target.Username,
+				"fullName": target.FullName,
+				"role":     roleName,
+			},
+		},
+	})
+}
+
+// End godoc
+// @Summary End impersonation
+// @Description End the impersonation session of the current token. The token is revoked immediately. Must be called with the impersonation token.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Impersonation ended"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Not an impersonation token"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/impersonation/end [post]
+func (s *ImpersonationService) End(c *fiber.Ctx) error {
+	claims, ok := c.Locals("claims").(*models.JWTClaims)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	sessionID, ok := c.Locals("impersonation_id").(uuid.UUID)
+	if !ok || claims.Act == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Current token is not an impersonation token",
+		})
+	}
+
+	expiresAt := time.Now()
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	if err := s.tokenRevocationRepo.RevokeToken(&models.RevokedToken{
+		JTI:       claims.ID,
+		UserID:    c.Locals("user_id").(uuid.UUID),
+		TokenType: models.TokenTypeAccess,
+		Reason:    "impersonation ended",
+		ExpiresAt: expiresAt,
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke impersonation token",
+			"details": err.Error(),
+		})
+	}
+
+	if err := s.impersonationRepo.End(sessionID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to end impersonation",
+			"details": err.Error(),
+		})
+	}
+
+	log.Printf("SECURITY: admin %s ended impersonation session %s", claims.Act.Subject, sessionID)
+
+	return c.JSON(fiber.Map{
+		"message": "Impersonation ended",
+	})
+}
+
+// List godoc
+// @Summary List impersonation sessions
+// @Description List the most recent impersonation sessions with the number of requests made in each. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Maximum sessions to return" minimum(1) maximum(200) default(50)
+// @Success 200 {object} map[string]interface{} "List of impersonation sessions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/impersonations [get]
+func (s *ImpersonationService) List(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", 50)
+	if limit < 1 || limit > 200 {
+		limit = 50
+	}
+
+	sessions, err := s.impersonationRepo.List(limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get impersonation sessions",
+			"details": err.Error(),
+		})
+	}
+	if sessions == nil {
+		sessions = []models.ImpersonationSession{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": sessions,
+	})
+}
+
+// GetByID godoc
+// @Summary Get impersonation session audit trail
+// @Description Get one impersonation session together with every request made during it. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Impersonation session ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Impersonation session and audit entries"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Session not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/impersonations/{id} [get]
+func (s *ImpersonationService) GetByID(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid impersonation ID",
+		})
+	}
+
+	session, err := s.impersonationRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get impersonation session",
+			"details": err.Error(),
+		})
+	}
+	if session == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Impersonation session not found",
+		})
+	}
+
+	entries, err := s.impersonationRepo.GetAuditBySession(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get impersonation audit log",
+			"details": err.Error(),
+		})
+	}
+	if entries == nil {
+		entries = []models.ImpersonationAuditLog{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"session":  session,
+			"requests": entries,
+		},
+	})
+}
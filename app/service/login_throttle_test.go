@@ -0,0 +1,175 @@
+package service
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/config"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// fakeLoginAttemptRepo - counter gagal per user dan per IP di memori
+type fakeLoginAttemptRepo struct {
+	repository.LoginAttemptRepository
+	ipFailures map[string]int
+	lockouts   map[uuid.UUID]*models.AccountLockout
+	attempts   []models.LoginAttempt
+}
+
+func (r *fakeLoginAttemptRepo) RecordAttempt(attempt *models.LoginAttempt) error {
+	r.attempts = append(r.attempts, *attempt)
+	if !attempt.Success {
+		r.ipFailures[attempt.IPAddress]++
+	}
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) CountRecentFailuresByIP(ip string, since time.Time) (int, error) {
+	return r.ipFailures[ip], nil
+}
+
+func (r *fakeLoginAttemptRepo) lockout(userID uuid.UUID) *models.AccountLockout {
+	if r.lockouts[userID] == nil {
+		r.lockouts[userID] = &models.AccountLockout{UserID: userID}
+	}
+	return r.lockouts[userID]
+}
+
+func (r *fakeLoginAttemptRepo) GetLockout(userID uuid.UUID) (*models.AccountLockout, error) {
+	copied := *r.lockout(userID)
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepo) RegisterFailure(userID uuid.UUID) (*models.AccountLockout, error) {
+	lockout := r.lockout(userID)
+	lockout.FailedAttempts++
+	copied := *lockout
+	return &copied, nil
+}
+
+func (r *fakeLoginAttemptRepo) Lock(userID uuid.UUID, until time.Time, reason string) error {
+	lockout := r.lockout(userID)
+	lockout.LockedUntil = &until
+	lockout.LockReason = &reason
+	lockout.LockoutCount++
+	lockout.FailedAttempts = 0
+	return nil
+}
+
+func (r *fakeLoginAttemptRepo) lastFailureReason() string {
+	last := r.attempts[len(r.attempts)-1]
+	if last.FailureReason == nil {
+		return ""
+	}
+	return *last.FailureReason
+}
+
+func (r *fakeUserRepo) GetByUsername(username string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Username == username {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *fakeUserRepo) GetByEmail(email string) (*models.User, error) {
+	for _, user := range r.users {
+		if user.Email == email {
+			return user, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestLockoutDuration(t *testing.T) {
+	protection := config.LoginProtectionSettings{BaseLockout: 5 * time.Minute, MaxLockout: time.Hour}
+
+	tests := []struct {
+		previousLockouts int
+		want             time.Duration
+	}{
+		{0, 5 * time.Minute},
+		{1, 10 * time.Minute},
+		{2, 20 * time.Minute},
+		{3, 40 * time.Minute},
+		{4, time.Hour},
+		{30, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := lockoutDuration(tt.previousLockouts, protection); got != tt.want {
+			t.Errorf("lockoutDuration(%d) = %s, want %s", tt.previousLockouts, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottling(t *testing.T) {
+	t.Setenv("LOGIN_MAX_FAILED_ATTEMPTS", "3")
+	t.Setenv("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", "5")
+
+	hash, err := utils.HashPassword("correct-password")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := &models.User{ID: uuid.New(), Username: "budi", Email: "budi@example.com", PasswordHash: hash, IsActive: true}
+	attempts := &fakeLoginAttemptRepo{ipFailures: map[string]int{}, lockouts: map[uuid.UUID]*models.AccountLockout{}}
+	svc := NewAuthService(
+		&fakeUserRepo{users: map[uuid.UUID]*models.User{user.ID: user}}, &fakeRoleRepo{},
+		nil, nil, nil, nil, nil, attempts, nil, nil, nil,
+	)
+
+	app := fiber.New()
+	app.Post("/auth/login", svc.Login)
+	login := func(username, password string) *http.Response {
+		t.Helper()
+		req := httptest.NewRequest("POST", "/auth/login", strings.NewReader(`{"username": "`+username+`", "password": "`+password+`"}`))
+		req.Header.Set("Content-Type", "application/json")
+		// bcrypt bisa lebih lama dari timeout default app.Test
+		resp, err := app.Test(req, -1)
+		if err != nil {
+			t.Fatalf("request failed: %v", err)
+		}
+		return resp
+	}
+
+	steps := []struct {
+		name       string
+		username   string
+		password   string
+		wantStatus int
+		wantReason string
+	}{
+		{"first wrong password", "budi", "wrong", fiber.StatusUnauthorized, models.LoginFailureInvalidPassword},
+		{"second wrong password", "budi", "wrong", fiber.StatusUnauthorized, models.LoginFailureInvalidPassword},
+		{"third wrong password locks the account", "budi", "wrong", fiber.StatusLocked, models.LoginFailureInvalidPassword},
+		{"correct password while locked", "budi", "correct-password", fiber.StatusLocked, models.LoginFailureAccountLocked},
+		{"unknown user", "nobody", "x", fiber.StatusUnauthorized, models.LoginFailureUnknownUser},
+		{"ip limit reached", "budi", "correct-password", fiber.StatusTooManyRequests, models.LoginFailureIPRateLimited},
+	}
+
+	for _, step := range steps {
+		resp := login(step.username, step.password)
+		if resp.StatusCode != step.wantStatus {
+			t.Fatalf("%s: status = %d, want %d", step.name, resp.StatusCode, step.wantStatus)
+		}
+		if got := attempts.lastFailureReason(); got != step.wantReason {
+			t.Fatalf("%s: recorded failure = %q, want %q", step.name, got, step.wantReason)
+		}
+		if step.wantStatus != fiber.StatusUnauthorized && resp.Header.Get(fiber.HeaderRetryAfter) == "" {
+			t.Fatalf("%s: missing Retry-After", step.name)
+		}
+	}
+
+	if lockout := attempts.lockouts[user.ID]; lockout.LockoutCount != 1 || lockout.LockedUntil == nil {
+		t.Fatalf("lockout = %+v, want one lockout", lockout)
+	}
+}
@@ -0,0 +1,423 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/config"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const oidcStateTTL = 10 * time.Minute
+
+var errOIDCAccountConflict = errors.New("an account with this email already exists but the identity provider did not verify the email")
+
+type OIDCService struct {
+	authService  *AuthService
+	userRepo     repository.UserRepository
+	roleRepo     repository.RoleRepository
+	studentRepo  repository.StudentRepository
+	lecturerRepo repository.LecturerRepository
+	oidcRepo     repository.OIDCRepository
+	provider     *utils.OIDCProvider
+	cfg          config.OIDCSettings
+}
+
+func NewOIDCService(
+	authService *AuthService,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	studentRepo repository.StudentRepository,
+	lecturerRepo repository.LecturerRepository,
+	oidcRepo repository.OIDCRepository,
+	cfg config.OIDCSettings,
+) *OIDCService {
+	return &OIDCService{
+		authService:  authService,
+		userRepo:     userRepo,
+		roleRepo:     roleRepo,
+		studentRepo:  studentRepo,
+		lecturerRepo: lecturerRepo,
+		oidcRepo:     oidcRepo,
+		provider:     utils.NewOIDCProvider(cfg),
+		cfg:          cfg,
+	}
+}
+
+// Login godoc
+// @Summary Start single sign-on login
+// @Description Redirect to the campus identity provider (OIDC authorization code + PKCE). Use redirect=false to get the authorization URL as JSON instead.
+// @Tags Authentication
+// @Produce json
+// @Param redirect query bool false "Redirect to the identity provider (default true)"
+// @Success 200 {object} map[string]interface{} "Authorization URL (redirect=false)"
+// @Success 302 {string} string "Redirect to identity provider"
+// @Failure 404 {object} map[string]interface{} "Not Found - SSO is not configured"
+// @Failure 502 {object} map[string]interface{} "Bad Gateway - Identity provider unavailable"
+// @Router /auth/oidc/login [get]
+func (s *OIDCService) Login(c *fiber.Ctx) error {
+	if !s.provider.Enabled() {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Single sign-on is not configured",
+		})
+	}
+
+	state, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate state",
+			"details": err.Error(),
+		})
+	}
+	nonce, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate nonce",
+			"details": err.Error(),
+		})
+	}
+	verifier, challenge, err := utils.GeneratePKCE()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate PKCE challenge",
+			"details": err.Error(),
+		})
+	}
+
+	authURL, err := s.provider.AuthCodeURL(state, nonce, challenge)
+	if err != nil {
+		return c.Status(502).JSON(fiber.Map{
+			"error":   "Identity provider unavailable",
+			"details": err.Error(),
+		})
+	}
+
+	if err := s.oidcRepo.SaveState(&models.OIDCLoginState{
+		StateHash:    utils.HashToken(state),
+		CodeVerifier: verifier,
+		Nonce:        nonce,
+		ExpiresAt:    time.Now().Add(oidcStateTTL),
+	}); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to save login state",
+			"details": err.Error(),
+		})
+	}
+
+	if !c.QueryBool("redirect", true) {
+		return c.JSON(fiber.Map{
+			"authorizationUrl": authURL,
+		})
+	}
+
+	return c.Redirect(authURL, fiber.StatusFound)
+}
+
+// Callback godoc
+// @Summary Single sign-on callback
+// @Description Redirect target of the identity provider. Validates state, exchanges the code (with PKCE verifier), verifies the ID token and returns the usual JWT pair. Users are matched by linked identity, NIM/NIP claim or verified email, and provisioned just-in-time when enabled.
+// @Tags Authentication
+// @Produce json
+// @Param code query string true "Authorization code"
+// @Param state query string true "State from /auth/oidc/login"
+// @Success 200 {object} models.LoginResponse "Login successful"
+// @Success 202 {object} models.TwoFactorChallengeResponse "Two-factor code or enrollment required"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid or expired state"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Identity provider rejected login or invalid ID token"
+// @Failure 403 {object} map[string]interface{} "Forbidden - No linked account or account inactive"
+// @Failure 409 {object} map[string]interface{} "Conflict - Email belongs to another account"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/oidc/callback [get]
+func (s *OIDCService) Callback(c *fiber.Ctx) error {
+	if !s.provider.Enabled() {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Single sign-on is not configured",
+		})
+	}
+
+	if idpErr := c.Query("error"); idpErr != "" {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Identity provider rejected the login",
+			"details": strings.TrimSpace(idpErr + " " + c.Query("error_description")),
+		})
+	}
+
+	code := c.Query("code")
+	state := c.Query("state")
+	if code == "" || state == "" {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "code and state are required",
+		})
+	}
+
+	loginState, err := s.oidcRepo.ConsumeState(utils.HashToken(state))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check login state",
+			"details": err.Error(),
+		})
+	}
+	if loginState == nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid or expired login state",
+		})
+	}
+
+	tokens, err := s.provider.Exchange(code, loginState.CodeVerifier)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Failed to exchange authorization code",
+			"details": err.Error(),
+		})
+	}
+
+	idToken, err := s.provider.VerifyIDToken(tokens.IDToken, loginState.Nonce)
+	if err != nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error":   "Invalid ID token",
+			"details": err.Error(),
+		})
+	}
+
+	identifier := "oidc:" + idToken.Subject
+	if idToken.Email != "" {
+		identifier = "oidc:" + idToken.Email
+	}
+
+	user, err := s.resolveUser(idToken)
+	if err != nil {
+		if errors.Is(err, errOIDCAccountConflict) {
+			return c.Status(409).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to resolve user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		s.authService.RecordFailedAttempt(c, nil, identifier, models.LoginFailureUnknownUser)
+		return c.Status(403).JSON(fiber.Map{
+			"error": "No account is linked to this identity",
+		})
+	}
+
+	if !user.IsActive {
+		s.authService.RecordFailedAttempt(c, &user.ID, identifier, models.LoginFailureAccountInactive)
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Account is inactive",
+		})
+	}
+
+	return s.authService.ContinueLogin(c, user, identifier)
+}
+
+// resolveUser - urutan pencocokan: identitas yang sudah terhubung, NIM, NIP, lalu email terverifikasi.
+// Jika tidak ada yang cocok dan JIT provisioning aktif, user baru dibuat.
+func (s *OIDCService) resolveUser(idToken *utils.OIDCIDToken) (*models.User, error) {
+	identity, err := s.oidcRepo.GetIdentity(idToken.Issuer, idToken.Subject)
+	if err != nil {
+		return nil, err
+	}
+	if identity != nil {
+		if err := s.oidcRepo.TouchIdentity(identity.ID); err != nil {
+			log.Printf("Warning: failed to update identity %s: %v", identity.ID, err)
+		}
+		return s.userRepo.GetByID(identity.UserID)
+	}
+
+	user, err := s.matchExistingUser(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	if user == nil {
+		if !s.cfg.JITProvisioning {
+			return nil, nil
+		}
+		user, err = s.provisionUser(idToken)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.oidcRepo.CreateIdentity(&models.UserIdentity{
+		UserID:  user.ID,
+		Issuer:  idToken.Issuer,
+		Subject: idToken.Subject,
+		Email:   idToken.Email,
+	}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *OIDCService) matchExistingUser(idToken *utils.OIDCIDToken) (*models.User, error) {
+	if nim := idToken.StringClaim(s.cfg.StudentIDClaim); nim != "" {
+		student, err := s.studentRepo.GetByStudentID(nim)
+		if err != nil {
+			return nil, err
+		}
+		if student != nil {
+			return s.userRepo.GetByID(student.UserID)
+		}
+	}
+
+	if nip := idToken.StringClaim(s.cfg.LecturerIDClaim); nip != "" {
+		lecturer, err := s.lecturerRepo.GetByLecturerID(nip)
+		if err != nil {
+			return nil, err
+		}
+		if lecturer != nil {
+			return s.userRepo.GetByID(lecturer.UserID)
+		}
+	}
+
+	// Email yang tidak diverifikasi IdP tidak boleh dipakai untuk mengambil alih akun
+	if idToken.Email != "" && idToken.EmailVerified {
+		return s.userRepo.GetByEmail(idToken.Email)
+	}
+
+	return nil, nil
+}
+
+func (s *OIDCService) provisionUser(idToken *utils.OIDCIDToken) (*models.User, error) {
+	if idToken.Email == "" {
+		return nil, nil
+	}
+
+	existing, err := s.userRepo.GetByEmail(idToken.Email)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, errOIDCAccountConflict
+	}
+
+	role, err := s.roleRepo.GetByName(s.cfg.DefaultRole)
+	if err != nil {
+		return nil, err
+	}
+	if role == nil {
+		return nil, fmt.Errorf("OIDC default role not found: %s", s.cfg.DefaultRole)
+	}
+
+	username, err := s.availableUsername(idToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// User SSO tidak punya password lokal yang diketahui siapa pun
+	randomPassword, err := utils.GenerateSecureToken(32)
+	if err != nil {
+		return nil, err
+	}
+	passwordHash, err := utils.HashPassword(randomPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	fullName := idToken.Name
+	if fullName == "" {
+		fullName = username
+	}
+
+	user := &models.User{
+		ID:           uuid.New(),
+		Username:     username,
+		Email:        idToken.Email,
+		PasswordHash: passwordHash,
+		FullName:     fullName,
+		RoleID:       role.ID,
+		IsActive:     true,
+	}
+	if _, err := s.userRepo.Create(user); err != nil {
+		return nil, err
+	}
+
+	if err := s.provisionProfile(user, role.Name, idToken); err != nil {
+		if delErr := s.userRepo.HardDelete(user.ID); delErr != nil {
+			log.Printf("Warning: failed to roll back provisioned user %s: %v", user.ID, delErr)
+		}
+		return nil, err
+	}
+
+	log.Printf("OIDC: provisioned user %s (%s) with role %s", user.ID, user.Email, role.Name)
+	return user, nil
+}
+
+func (s *OIDCService) provisionProfile(user *models.User, roleName string, idToken *utils.OIDCIDToken) error {
+	switch roleName {
+	case "Mahasiswa":
+		nim := idToken.StringClaim(s.cfg.StudentIDClaim)
+		if nim == "" {
+			return nil
+		}
+		_, err := s.studentRepo.Create(models.Student{
+			ID:           uuid.New(),
+			UserID:       user.ID,
+			StudentID:    nim,
+			ProgramStudy: idToken.StringClaim("program_study"),
+			AcademicYear: idToken.StringClaim("academic_year"),
+			CreatedAt:    time.Now(),
+		})
+		return err
+
+	case "Dosen Wali":
+		nip := idToken.StringClaim(s.cfg.LecturerIDClaim)
+		if nip == "" {
+			return nil
+		}
+		_, err := s.lecturerRepo.Create(models.Lecturer{
+			ID:         uuid.New(),
+			UserID:     user.ID,
+			LecturerID: nip,
+			Department: idToken.StringClaim("department"),
+			CreatedAt:  time.Now(),
+		})
+		return err
+	}
+
+	return nil
+}
+
+// availableUsername - preferred_username atau bagian lokal email, diberi akhiran jika sudah dipakai
+func (s *OIDCService) availableUsername(idToken *utils.OIDCIDToken) (string, error) {
+	base := idToken.PreferredUsername
+	if base == "" {
+		base = strings.SplitN(idToken.Email, "@", 2)[0]
+	}
+	base = strings.ToLower(strings.TrimSpace(base))
+	if base == "" {
+		base = "user"
+	}
+
+	candidate := base
+	for i := 0; i < 10; i++ {
+		existing, err := s.userRepo.GetByUsername(candidate)
+		if err != nil {
+			return "", err
+		}
+		if existing == nil {
+			return candidate, nil
+		}
+
+		suffix, err := utils.GenerateSecureToken(3)
+		if err != nil {
+			return "", err
+		}
+		candidate = base + "_" + strings.ToLower(suffix)
+	}
+
+	return "", errors.New("could not find an available username")
+}
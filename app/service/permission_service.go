@@ -0,0 +1,71 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"UAS/app/repository"
+
+	"github.com/google/uuid"
+)
+
+// permissionCacheTTL - batas atas keterlambatan jika permission diubah dari instance lain
+// (di instance yang sama perubahan langsung berlaku lewat Invalidate*).
+const permissionCacheTTL = 30 * time.Second
+
+type cachedPermissions struct {
+	permissions []string
+	loadedAt    time.Time
+}
+
+// PermissionService - resolve permission per role setiap request, di-cache di memory.
+// Dipakai middleware.RequireAuth menggantikan permission yang dulu dibekukan di JWT.
+type PermissionService struct {
+	roleRepo repository.RoleRepository
+
+	mu    sync.RWMutex
+	cache map[uuid.UUID]cachedPermissions
+}
+
+func NewPermissionService(roleRepo repository.RoleRepository) *PermissionService {
+	return &PermissionService{
+		roleRepo: roleRepo,
+		cache:    make(map[uuid.UUID]cachedPermissions),
+	}
+}
+
+func (s *PermissionService) GetPermissions(roleID uuid.UUID) ([]string, error) {
+	s.mu.RLock()
+	cached, ok := s.cache[roleID]
+	s.mu.RUnlock()
+	if ok && time.Since(cached.loadedAt) < permissionCacheTTL {
+		return cached.permissions, nil
+	}
+
+	permissions, err := s.roleRepo.GetPermissionNamesByRoleID(roleID)
+	if err != nil {
+		return nil, err
+	}
+	if permissions == nil {
+		permissions = []string{}
+	}
+
+	s.mu.Lock()
+	s.cache[roleID] = cachedPermissions{permissions: permissions, loadedAt: time.Now()}
+	s.mu.Unlock()
+
+	return permissions, nil
+}
+
+// InvalidateRole - panggil setelah permission sebuah role diubah
+func (s *PermissionService) InvalidateRole(roleID uuid.UUID) {
+	s.mu.Lock()
+	delete(s.cache, roleID)
+	s.mu.Unlock()
+}
+
+func (s *PermissionService) InvalidateAll() {
+	s.mu.Lock()
+	s.cache = make(map[uuid.UUID]cachedPermissions)
+	s.mu.Unlock()
+}
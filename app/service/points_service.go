@@ -0,0 +1,292 @@
+package service
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"strings"
+
+	"UAS/app/models"
+	"UAS/app/points"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type PointsService struct {
+	ruleRepo           repository.PointsRuleRepository
+	achievementRefRepo repository.AchievementReferenceRepository
+	achievementRepo    repository.AchievementRepository
+}
+
+func NewPointsService(
+	ruleRepo repository.PointsRuleRepository,
+	achievementRefRepo repository.AchievementReferenceRepository,
+	achievementRepo repository.AchievementRepository,
+) *PointsService {
+	return &PointsService{
+		ruleRepo:           ruleRepo,
+		achievementRefRepo: achievementRefRepo,
+		achievementRepo:    achievementRepo,
+	}
+}
+
+// Active - versi aturan terbaru, atau aturan bawaan (versi 0) jika admin belum menyimpan aturan
+func (s *PointsService) Active() (*models.PointsRuleSet, error) {
+	set, err := s.ruleRepo.GetLatest()
+	if err != nil {
+		return nil, err
+	}
+	if set == nil {
+		set = &models.PointsRuleSet{Version: 0, Rules: points.DefaultRules(), Note: "Built-in default rules"}
+	}
+	return set, nil
+}
+
+// Calculate - poin achievement dengan aturan aktif
+func (s *PointsService) Calculate(achievement *models.Achievement) (models.PointsResult, error) {
+	set, err := s.Active()
+	if err != nil {
+		return models.PointsResult{}, err
+	}
+	return points.Calculate(set, achievement), nil
+}
+
+// Recalculate - hitung ulang semua prestasi verified dengan aturan aktif. Prestasi yang
+// sudah dihitung dengan versi aktif dilewati. Dipakai endpoint admin dan flag -recalculate-points.
+func (s *PointsService) Recalculate(ctx context.Context, dryRun bool) (*models.PointsRecalculation, error) {
+	set, err := s.Active()
+	if err != nil {
+		return nil, err
+	}
+	refs, err := s.achievementRefRepo.GetVerifiedReferences()
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &models.PointsRecalculation{RuleVersion: set.Version, DryRun: dryRun, Changes: []models.PointsChange{}}
+	for _, ref := range refs {
+		if ref.PointsRuleVersion != nil && *ref.PointsRuleVersion == set.Version {
+			continue
+		}
+		summary.Checked++
+
+		achievement, err := s.achievementRepo.GetAchievementByID(ctx, ref.MongoAchievementID)
+		if err != nil || achievement == nil {
+			log.Printf("Warning: cannot recalculate points of achievement %s: details not found", ref.ID)
+			summary.Failed++
+			continue
+		}
+
+		result := points.Calculate(set, achievement)
+		if result.Points != ref.Points {
+			summary.Changes = append(summary.Changes, models.PointsChange{
+				AchievementID:  ref.ID,
+				OldPoints:      ref.Points,
+				NewPoints:      result.Points,
+				OldRuleVersion: ref.PointsRuleVersion,
+			})
+		}
+		if dryRun {
+			continue
+		}
+
+		// Salinan MongoDB ditulis dulu: kalau gagal, item dihitung gagal dan versi aturannya
+		// belum dicatat, jadi ikut dihitung ulang lagi di run berikutnya
+		if err := s.achievementRepo.UpdatePoints(ctx, ref.MongoAchievementID, result.Points); err != nil {
+			log.Printf("Warning: failed to update points of achievement %s in MongoDB: %v", ref.ID, err)
+			summary.Failed++
+			continue
+		}
+		// Versi tetap dicatat walaupun poinnya sama, supaya tidak dihitung ulang lagi
+		if err := s.achievementRefRepo.UpdatePoints(ref.ID, result); err != nil {
+			log.Printf("Warning: failed to update points of achievement %s: %v", ref.ID, err)
+			summary.Failed++
+			continue
+		}
+		summary.Updated++
+	}
+
+	return summary, nil
+}
+
+// GetRules godoc
+// @Summary Get active points rules
+// @Description Rules used to calculate achievement points. Points are added for every matching rule; within a group only the first matching rule counts. Version 0 means the built-in defaults
+// @Tags Points Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Active rule set"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /points-rules [get]
+func (s *PointsService) GetRules(c *fiber.Ctx) error {
+	set, err := s.Active()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get points rules",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    set,
+	})
+}
+
+// UpdateRules godoc
+// @Summary Update points rules
+// @Description Save a new version of the points rules. Achievements verified from now on use the new version; run /points-rules/recalculate to apply it to past achievements. Admin only.
+// @Tags Points Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.UpdatePointsRulesRequest true "Rules"
+// @Success 201 {object} map[string]interface{} "New rule version"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid rules"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /points-rules [put]
+func (s *PointsService) UpdateRules(c *fiber.Ctx) error {
+	var req models.UpdatePointsRulesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid request body"})
+	}
+
+	if err := points.Validate(req.Rules); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	userID := c.Locals("user_id").(uuid.UUID)
+	set := &models.PointsRuleSet{
+		Rules:     req.Rules,
+		Note:      strings.TrimSpace(req.Note),
+		CreatedBy: &userID,
+	}
+	if err := s.ruleRepo.Create(set); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to save points rules",
+			"details": err.Error(),
+		})
+	}
+
+	log.Printf("SECURITY: admin %s saved points rules version %d", userID, set.Version)
+
+	return c.Status(201).JSON(fiber.Map{
+		"success": true,
+		"message": "Points rules saved as version " + strconv.Itoa(set.Version),
+		"data":    set,
+	})
+}
+
+// GetVersions godoc
+// @Summary List points rules versions
+// @Description All saved versions of the points rules, newest first, without the rules themselves. Admin only.
+// @Tags Points Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Versions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /points-rules/versions [get]
+func (s *PointsService) GetVersions(c *fiber.Ctx) error {
+	sets, err := s.ruleRepo.List()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get points rules versions",
+			"details": err.Error(),
+		})
+	}
+	if sets == nil {
+		sets = []models.PointsRuleSet{}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    sets,
+	})
+}
+
+// GetVersion godoc
+// @Summary Get points rules version
+// @Description One saved version of the points rules. Version 0 is the built-in default. Admin only.
+// @Tags Points Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param version path int true "Version"
+// @Success 200 {object} map[string]interface{} "Rule set"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid version"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /points-rules/versions/{version} [get]
+func (s *PointsService) GetVersion(c *fiber.Ctx) error {
+	version, err := strconv.Atoi(c.Params("version"))
+	if err != nil || version < 0 {
+		return c.Status(400).JSON(fiber.Map{"error": "Invalid version"})
+	}
+
+	if version == 0 {
+		return c.JSON(fiber.Map{
+			"success": true,
+			"data":    models.PointsRuleSet{Version: 0, Rules: points.DefaultRules(), Note: "Built-in default rules"},
+		})
+	}
+
+	set, err := s.ruleRepo.GetByVersion(version)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get points rules",
+			"details": err.Error(),
+		})
+	}
+	if set == nil {
+		return c.Status(404).JSON(fiber.Map{"error": "Points rules version not found"})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    set,
+	})
+}
+
+// RecalculatePoints godoc
+// @Summary Recalculate points of verified achievements
+// @Description Apply the active points rules to every verified achievement that was scored with another version. Use dry_run=true to only see what would change. Admin only.
+// @Tags Points Rules
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "Only report changes"
+// @Success 200 {object} map[string]interface{} "Recalculation summary"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /points-rules/recalculate [post]
+func (s *PointsService) RecalculatePoints(c *fiber.Ctx) error {
+	summary, err := s.Recalculate(c.UserContext(), c.QueryBool("dry_run", false))
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to recalculate points",
+			"details": err.Error(),
+		})
+	}
+
+	if !summary.DryRun {
+		userID, _ := c.Locals("user_id").(uuid.UUID)
+		log.Printf("SECURITY: admin %s recalculated points with rules version %d (%d updated)", userID, summary.RuleVersion, summary.Updated)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"data":    summary,
+	})
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"UAS/app/models"
+)
+
+func TestRecalculateCountsFailedMongoCopy(t *testing.T) {
+	f := newAchievementFixture("competition", models.AchievementDetails{CompetitionName: "Gemastik", CompetitionLevel: "national", Rank: 1})
+	f.ref.Status = models.AchievementStatusVerified
+	svc := NewPointsService(&fakePointsRuleRepo{}, f.refRepo, f.achievements)
+
+	f.achievements.updatePointsErr = errors.New("server selection timeout")
+	summary, err := svc.Recalculate(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Updated != 0 || summary.Failed != 1 {
+		t.Fatalf("updated = %d, failed = %d; want 0, 1", summary.Updated, summary.Failed)
+	}
+	// Versi aturan belum dicatat, jadi run berikutnya mencoba lagi
+	if f.ref.PointsRuleVersion != nil {
+		t.Fatalf("rule version recorded despite the failed MongoDB copy")
+	}
+
+	f.achievements.updatePointsErr = nil
+	summary, err = svc.Recalculate(context.Background(), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Updated != 1 || summary.Failed != 0 {
+		t.Fatalf("retry: updated = %d, failed = %d; want 1, 0", summary.Updated, summary.Failed)
+	}
+	if f.achievement.Points != f.ref.Points {
+		t.Fatalf("MongoDB points = %d, PostgreSQL points = %d", f.achievement.Points, f.ref.Points)
+	}
+}
@@ -4,7 +4,7 @@ import (
 	"context"
 	"time"
 
-	"UAS/app/models"
+	"UAS/app/authz"
 	"UAS/app/repository"
 
 	"github.com/gofiber/fiber/v2"
@@ -17,6 +17,7 @@ type ReportService struct {
 	studentRepo repository.StudentRepository
 	lecturerRepo repository.LecturerRepository
 	roleRepo    repository.RoleRepository
+	authorizer  *authz.Authorizer
 }
 
 func NewReportService(
@@ -25,6 +26,7 @@ func NewReportService(
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
 	roleRepo repository.RoleRepository,
+	authorizer *authz.Authorizer,
 ) *ReportService {
 	return &ReportService{
 		reportRepo:  reportRepo,
@@ -32,6 +34,7 @@ func NewReportService(
 		studentRepo: studentRepo,
 		lecturerRepo: lecturerRepo,
 		roleRepo:    roleRepo,
+		authorizer:  authorizer,
 	}
 }
 
@@ -51,37 +54,31 @@ func NewReportService(
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /reports/statistics [get]
 func (s *ReportService) GetStatistics(c *fiber.Ctx) error {
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err == authz.ErrNoActor {
 		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
 	}
-
-	role, err := s.roleRepo.GetByID(currentUser.RoleID)
-	if err != nil || role == nil {
+	if err != nil {
 		return c.Status(403).JSON(fiber.Map{"error": "invalid role"})
 	}
 
 	var scope string
 	var actorID uuid.UUID
 
-	switch role.Name {
-	case "Admin":
+	grants := s.authorizer.Grants(actor, authz.ActionReportStatistics)
+	switch {
+	case grants.Has(authz.RelationAny):
 		scope = "all"
-		actorID = currentUser.ID
-	case "Dosen Wali":
-		lecturer, err := s.lecturerRepo.GetByUserID(currentUser.ID)
-		if err != nil || lecturer == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "lecturer not found"})
-		}
+		actorID = actor.User.ID
+	case grants.Has(authz.RelationScope):
+		scope = "scoped"
+		actorID = actor.User.ID
+	case grants.Has(authz.RelationAdvisor):
 		scope = "lecturer"
-		actorID = lecturer.ID
-	case "Mahasiswa":
-		student, err := s.studentRepo.GetByUserID(currentUser.ID)
-		if err != nil || student == nil {
-			return c.Status(403).JSON(fiber.Map{"error": "student not found"})
-		}
+		actorID = *actor.LecturerID
+	case grants.Has(authz.RelationOwner):
 		scope = "student"
-		actorID = student.ID
+		actorID = *actor.StudentID
 	default:
 		return c.Status(403).JSON(fiber.Map{"error": "access denied"})
 	}
@@ -137,35 +134,20 @@ func (s *ReportService) GetStudentReport(c *fiber.Ctx) error {
 		return c.Status(400).JSON(fiber.Map{"error": "invalid student id"})
 	}
 
-	currentUser, ok := c.Locals("user").(*models.User)
-	if !ok {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err == authz.ErrNoActor {
 		return c.Status(401).JSON(fiber.Map{"error": "unauthorized"})
 	}
-
-	role, err := s.roleRepo.GetByID(currentUser.RoleID)
-	if err != nil || role == nil {
+	if err != nil {
 		return c.Status(403).JSON(fiber.Map{"error": "invalid role"})
 	}
 
-	authorized := false
-
-	switch role.Name {
-	case "Admin":
-		authorized = true
-	case "Dosen Wali":
-		lecturer, _ := s.lecturerRepo.GetByUserID(currentUser.ID)
-		student, _ := s.studentRepo.GetByID(studentID)
-		if lecturer != nil && student != nil && student.AdvisorID != nil {
-			authorized = *student.AdvisorID == lecturer.ID
-		}
-	case "Mahasiswa":
-		student, _ := s.studentRepo.GetByID(studentID)
-		if student != nil {
-			authorized = student.UserID == currentUser.ID
-		}
+	resource, err := s.authorizer.StudentResourceByID(studentID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
 	}
 
-	if !authorized {
+	if !s.authorizer.Can(actor, authz.ActionReportStudent, resource) {
 		return c.Status(403).JSON(fiber.Map{"error": "access denied"})
 	}
 
@@ -0,0 +1,668 @@
+package service
+
+import (
+	"errors"
+	"log"
+	"regexp"
+	"strings"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// resource:action, huruf kecil, contoh: achievement:verify
+var permissionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]*:[a-z][a-z0-9_-]*$`)
+
+type RoleService struct {
+	roleRepo          repository.RoleRepository
+	permissionRepo    repository.PermissionRepository
+	permissionService *PermissionService
+}
+
+func NewRoleService(
+	roleRepo repository.RoleRepository,
+	permissionRepo repository.PermissionRepository,
+	permissionService *PermissionService,
+) *RoleService {
+	return &RoleService{
+		roleRepo:          roleRepo,
+		permissionRepo:    permissionRepo,
+		permissionService: permissionService,
+	}
+}
+
+// GetAll godoc
+// @Summary Get all roles
+// @Description Get list of roles with pagination. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" minimum(1) default(1)
+// @Param limit query int false "Items per page" minimum(1) maximum(100) default(10)
+// @Success 200 {object} map[string]interface{} "List of roles with pagination"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles [get]
+func (s *RoleService) GetAll(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	roles, total, err := s.roleRepo.GetAll(page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get roles",
+			"details": err.Error(),
+		})
+	}
+	if roles == nil {
+		roles = []models.Role{}
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return c.JSON(fiber.Map{
+		"data": roles,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    page < totalPages,
+			"has_prev":    page > 1,
+		},
+	})
+}
+
+// GetByID godoc
+// @Summary Get role by ID
+// @Description Get a role together with its permissions. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Role with permissions"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid role ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Role not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles/{id} [get]
+func (s *RoleService) GetByID(c *fiber.Ctx) error {
+	role, status, resp := s.findRole(c)
+	if role == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	permissions, err := s.roleRepo.GetPermissionsByRoleID(role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role permissions",
+			"details": err.Error(),
+		})
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+
+	userCount, err := s.roleRepo.CountUsers(role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to count role users",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"data": fiber.Map{
+			"role":        role,
+			"permissions": permissions,
+			"user_count":  userCount,
+			"built_in":    models.IsBuiltInRole(role.Name),
+		},
+	})
+}
+
+// Create godoc
+// @Summary Create role
+// @Description Create a new role without permissions. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreateRoleRequest true "Role data"
+// @Success 201 {object} map[string]interface{} "Role created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid input"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 409 {object} map[string]interface{} "Conflict - Role name already exists"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles [post]
+func (s *RoleService) Create(c *fiber.Ctx) error {
+	var req models.CreateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.Name = strings.TrimSpace(req.Name)
+	if len(req.Name) < 3 || len(req.Name) > 50 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Role name must be between 3 and 50 characters",
+		})
+	}
+
+	existing, err := s.roleRepo.GetByName(req.Name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check role name",
+			"details": err.Error(),
+		})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Role name already exists",
+		})
+	}
+
+	role := models.Role{
+		Name:             req.Name,
+		Description:      strings.TrimSpace(req.Description),
+		RequireTwoFactor: req.RequireTwoFactor,
+	}
+	id, err := s.roleRepo.Create(role)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create role",
+			"details": err.Error(),
+		})
+	}
+
+	created, err := s.roleRepo.GetByID(id)
+	if err != nil || created == nil {
+		return c.Status(201).JSON(fiber.Map{
+			"message": "Role created successfully",
+			"data":    fiber.Map{"id": id},
+		})
+	}
+
+	s.logChange(c, "created role %s (%s)", created.Name, created.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Role created successfully",
+		"data":    created,
+	})
+}
+
+// Update godoc
+// @Summary Update role
+// @Description Rename a role or change its description / 2FA requirement. Built-in roles cannot be renamed. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID (UUID)"
+// @Param request body models.UpdateRoleRequest true "Fields to update"
+// @Success 200 {object} map[string]interface{} "Role updated successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid input"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Role not found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Built-in role or duplicate name"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles/{id} [put]
+func (s *RoleService) Update(c *fiber.Ctx) error {
+	role, status, resp := s.findRole(c)
+	if role == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	var req models.UpdateRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if len(name) < 3 || len(name) > 50 {
+			return c.Status(400).JSON(fiber.Map{
+				"error": "Role name must be between 3 and 50 characters",
+			})
+		}
+
+		if name != role.Name {
+			// Nama role bawaan dipakai langsung di kode, rename = fitur role tersebut hilang
+			if models.IsBuiltInRole(role.Name) {
+				return c.Status(409).JSON(fiber.Map{
+					"error": "Built-in role cannot be renamed",
+				})
+			}
+
+			existing, err := s.roleRepo.GetByName(name)
+			if err != nil {
+				return c.Status(500).JSON(fiber.Map{
+					"error":   "Failed to check role name",
+					"details": err.Error(),
+				})
+			}
+			if existing != nil {
+				return c.Status(409).JSON(fiber.Map{
+					"error": "Role name already exists",
+				})
+			}
+			role.Name = name
+		}
+	}
+	if req.Description != nil {
+		role.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.RequireTwoFactor != nil {
+		role.RequireTwoFactor = *req.RequireTwoFactor
+	}
+
+	if err := s.roleRepo.Update(*role); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to update role",
+			"details": err.Error(),
+		})
+	}
+
+	s.logChange(c, "updated role %s (%s)", role.Name, role.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Role updated successfully",
+		"data":    role,
+	})
+}
+
+// Delete godoc
+// @Summary Delete role
+// @Description Delete a custom role. Built-in roles and roles still assigned to users cannot be deleted. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Role deleted successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid role ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Role not found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Role is built-in, in use or the last user manager"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles/{id} [delete]
+func (s *RoleService) Delete(c *fiber.Ctx) error {
+	role, status, resp := s.findRole(c)
+	if role == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	if models.IsBuiltInRole(role.Name) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Built-in role cannot be deleted",
+		})
+	}
+
+	userCount, err := s.roleRepo.CountUsers(role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to count role users",
+			"details": err.Error(),
+		})
+	}
+	if userCount > 0 {
+		return c.Status(409).JSON(fiber.Map{
+			"error":      "Role is still assigned to users",
+			"user_count": userCount,
+		})
+	}
+
+	lastManager, err := s.isLastUserManager(role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check role permissions",
+			"details": err.Error(),
+		})
+	}
+	if lastManager {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Role is the last role holding user:manage",
+		})
+	}
+
+	if err := s.roleRepo.Delete(role.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to delete role",
+			"details": err.Error(),
+		})
+	}
+
+	s.permissionService.InvalidateRole(role.ID)
+	s.logChange(c, "deleted role %s (%s)", role.Name, role.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Role deleted successfully",
+	})
+}
+
+// AssignPermission godoc
+// @Summary Attach permission to role
+// @Description Attach an existing permission to a role. Takes effect on the next request of affected users. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID (UUID)"
+// @Param request body models.RolePermissionRequest true "Permission to attach"
+// @Success 200 {object} map[string]interface{} "Permission attached successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid input"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Role or permission not found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Permission already attached"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles/{id}/permissions [post]
+func (s *RoleService) AssignPermission(c *fiber.Ctx) error {
+	role, status, resp := s.findRole(c)
+	if role == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	var req models.RolePermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	permissionID, err := uuid.Parse(req.PermissionID)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid permission ID",
+		})
+	}
+
+	permission, err := s.permissionRepo.GetByID(permissionID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check permission",
+			"details": err.Error(),
+		})
+	}
+	if permission == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Permission not found",
+		})
+	}
+
+	current, err := s.roleRepo.GetPermissionNamesByRoleID(role.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get role permissions",
+			"details": err.Error(),
+		})
+	}
+	if contains(current, permission.Name) {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Permission already attached to role",
+		})
+	}
+
+	if err := s.roleRepo.AssignPermission(role.ID, permission.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to attach permission",
+			"details": err.Error(),
+		})
+	}
+
+	s.permissionService.InvalidateRole(role.ID)
+	s.logChange(c, "attached permission %s to role %s (%s)", permission.Name, role.Name, role.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Permission attached successfully",
+		"data": fiber.Map{
+			"role_id":    role.ID,
+			"permission": permission,
+		},
+	})
+}
+
+// RemovePermission godoc
+// @Summary Detach permission from role
+// @Description Detach a permission from a role. The Admin role and the last role holding user:manage keep user:manage. Admin only.
+// @Tags Roles
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Role ID (UUID)"
+// @Param permissionId path string true "Permission ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Permission detached successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Role or permission not found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Permission is required by the role"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /roles/{id}/permissions/{permissionId} [delete]
+func (s *RoleService) RemovePermission(c *fiber.Ctx) error {
+	role, status, resp := s.findRole(c)
+	if role == nil {
+		return c.Status(status).JSON(resp)
+	}
+
+	permissionID, err := uuid.Parse(c.Params("permissionId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid permission ID",
+		})
+	}
+
+	permission, err := s.permissionRepo.GetByID(permissionID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check permission",
+			"details": err.Error(),
+		})
+	}
+	if permission == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Permission not found",
+		})
+	}
+
+	if permission.Name == models.PermissionUserManage {
+		// AdminOnly mengecek nama role "Admin" + user:manage untuk API key,
+		// jadi role Admin tidak boleh kehilangan permission ini
+		if role.Name == "Admin" {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "Admin role cannot be stripped of user:manage",
+			})
+		}
+		lastManager, err := s.isLastUserManager(role.ID)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Failed to check role permissions",
+				"details": err.Error(),
+			})
+		}
+		if lastManager {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "Role is the last role holding user:manage",
+			})
+		}
+	}
+
+	if err := s.roleRepo.RemovePermission(role.ID, permission.ID); err != nil {
+		if errors.Is(err, repository.ErrRolePermissionNotFound) {
+			return c.Status(404).JSON(fiber.Map{
+				"error": "Permission is not attached to role",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to detach permission",
+			"details": err.Error(),
+		})
+	}
+
+	s.permissionService.InvalidateRole(role.ID)
+	s.logChange(c, "detached permission %s from role %s (%s)", permission.Name, role.Name, role.ID)
+
+	return c.JSON(fiber.Map{
+		"message": "Permission detached successfully",
+	})
+}
+
+// GetPermissions godoc
+// @Summary Get all permissions
+// @Description Get list of all permissions. Admin only.
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "List of permissions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /permissions [get]
+func (s *RoleService) GetPermissions(c *fiber.Ctx) error {
+	permissions, err := s.permissionRepo.GetAll()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get permissions",
+			"details": err.Error(),
+		})
+	}
+	if permissions == nil {
+		permissions = []models.Permission{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": permissions,
+	})
+}
+
+// CreatePermission godoc
+// @Summary Create permission
+// @Description Create a new permission named resource:action (lowercase). Admin only.
+// @Tags Permissions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.CreatePermissionRequest true "Permission data"
+// @Success 201 {object} map[string]interface{} "Permission created successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid permission name"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 409 {object} map[string]interface{} "Conflict - Permission already exists"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /permissions [post]
+func (s *RoleService) CreatePermission(c *fiber.Ctx) error {
+	var req models.CreatePermissionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if len(name) > 100 || !permissionNamePattern.MatchString(name) {
+		return c.Status(400).JSON(fiber.Map{
+			"error":   "Invalid permission name",
+			"details": "permission name must be in resource:action format, e.g. achievement:verify",
+		})
+	}
+	parts := strings.SplitN(name, ":", 2)
+	if len(parts[0]) > 50 || len(parts[1]) > 50 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Resource and action must be at most 50 characters",
+		})
+	}
+
+	existing, err := s.permissionRepo.GetByName(name)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check permission",
+			"details": err.Error(),
+		})
+	}
+	if existing != nil {
+		return c.Status(409).JSON(fiber.Map{
+			"error": "Permission already exists",
+		})
+	}
+
+	permission := models.Permission{
+		Name:        name,
+		Resource:    parts[0],
+		Action:      parts[1],
+		Description: strings.TrimSpace(req.Description),
+	}
+	id, err := s.permissionRepo.Create(permission)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to create permission",
+			"details": err.Error(),
+		})
+	}
+	permission.ID = id
+
+	s.logChange(c, "created permission %s (%s)", permission.Name, permission.ID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Permission created successfully",
+		"data":    permission,
+	})
+}
+
+func (s *RoleService) findRole(c *fiber.Ctx) (*models.Role, int, fiber.Map) {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return nil, 400, fiber.Map{"error": "Invalid role ID"}
+	}
+
+	role, err := s.roleRepo.GetByID(id)
+	if err != nil {
+		return nil, 500, fiber.Map{"error": "Failed to get role", "details": err.Error()}
+	}
+	if role == nil {
+		return nil, 404, fiber.Map{"error": "Role not found"}
+	}
+	return role, 0, nil
+}
+
+// isLastUserManager - minimal harus ada satu role yang tetap punya user:manage
+func (s *RoleService) isLastUserManager(roleID uuid.UUID) (bool, error) {
+	permissions, err := s.roleRepo.GetPermissionNamesByRoleID(roleID)
+	if err != nil {
+		return false, err
+	}
+	if !contains(permissions, models.PermissionUserManage) {
+		return false, nil
+	}
+
+	count, err := s.roleRepo.CountRolesWithPermission(models.PermissionUserManage)
+	if err != nil {
+		return false, err
+	}
+	return count <= 1, nil
+}
+
+func (s *RoleService) logChange(c *fiber.Ctx, format string, args ...interface{}) {
+	adminID, _ := c.Locals("user_id").(uuid.UUID)
+	log.Printf("SECURITY: admin %s "+format, append([]interface{}{adminID}, args...)...)
+}
@@ -0,0 +1,49 @@
+package service
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestRemovePermissionErrorStatus(t *testing.T) {
+	role := &models.Role{ID: uuid.New(), Name: "Dosen Wali"}
+	permission := &models.Permission{ID: uuid.New(), Name: "achievement:read"}
+
+	tests := []struct {
+		name       string
+		removeErr  error
+		wantStatus int
+	}{
+		{"not attached", repository.ErrRolePermissionNotFound, fiber.StatusNotFound},
+		{"database error", errors.New("connection refused"), fiber.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := NewRoleService(
+				&fakeRoleRepo{roles: map[uuid.UUID]*models.Role{role.ID: role}, removeErr: tt.removeErr},
+				&fakePermissionRepo{permissions: map[uuid.UUID]*models.Permission{permission.ID: permission}},
+				nil,
+			)
+
+			app := fiber.New()
+			app.Delete("/roles/:id/permissions/:permissionId", svc.RemovePermission)
+
+			req := httptest.NewRequest("DELETE", "/roles/"+role.ID.String()+"/permissions/"+permission.ID.String(), nil)
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
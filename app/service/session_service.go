@@ -0,0 +1,229 @@
+package service
+
+import (
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SessionService struct {
+	sessionRepo         repository.SessionRepository
+	refreshTokenRepo    repository.RefreshTokenRepository
+	tokenRevocationRepo repository.TokenRevocationRepository
+	userRepo            repository.UserRepository
+}
+
+func NewSessionService(
+	sessionRepo repository.SessionRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	tokenRevocationRepo repository.TokenRevocationRepository,
+	userRepo repository.UserRepository,
+) *SessionService {
+	return &SessionService{
+		sessionRepo:         sessionRepo,
+		refreshTokenRepo:    refreshTokenRepo,
+		tokenRevocationRepo: tokenRevocationRepo,
+		userRepo:            userRepo,
+	}
+}
+
+// GetMySessions godoc
+// @Summary List my active sessions
+// @Description List devices where the authenticated user is currently logged in. The session of the current request is marked with current=true.
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "List of active sessions"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Not authenticated"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/sessions [get]
+func (s *SessionService) GetMySessions(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	return s.listSessions(c, userID)
+}
+
+// RevokeMySession godoc
+// @Summary Revoke one of my sessions
+// @Description Log out a device. Its refresh token family and current access token are revoked immediately.
+// @Tags Sessions
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Session ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Session revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid session ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Not authenticated"
+// @Failure 404 {object} map[string]interface{} "Not Found - Session not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/sessions/{id} [delete]
+func (s *SessionService) RevokeMySession(c *fiber.Ctx) error {
+	userID, ok := c.Locals("user_id").(uuid.UUID)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	return s.revokeSession(c, userID, "revoked by user")
+}
+
+// GetUserSessions godoc
+// @Summary List active sessions of a user
+// @Description List devices where the user is currently logged in. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} map[string]interface{} "List of active sessions"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid user ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/sessions [get]
+func (s *SessionService) GetUserSessions(c *fiber.Ctx) error {
+	userID, err := s.targetUserID(c)
+	if err != nil {
+		return err
+	}
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	return s.listSessions(c, userID)
+}
+
+// RevokeUserSession godoc
+// @Summary Revoke a session of a user
+// @Description Kick a single (e.g. compromised) session of the user. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Param sessionId path string true "Session ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Session revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Session not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/sessions/{sessionId} [delete]
+func (s *SessionService) RevokeUserSession(c *fiber.Ctx) error {
+	userID, err := s.targetUserID(c)
+	if err != nil {
+		return err
+	}
+	if userID == uuid.Nil {
+		return nil
+	}
+
+	return s.revokeSession(c, userID, "revoked by admin")
+}
+
+// targetUserID - uuid.Nil berarti respons error sudah dikirim
+func (s *SessionService) targetUserID(c *fiber.Ctx) (uuid.UUID, error) {
+	userID, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return uuid.Nil, c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return uuid.Nil, c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		return uuid.Nil, c.Status(404).JSON(fiber.Map{
+			"error": "User not found",
+		})
+	}
+
+	return userID, nil
+}
+
+func (s *SessionService) listSessions(c *fiber.Ctx, userID uuid.UUID) error {
+	sessions, err := s.sessionRepo.GetActiveByUserID(userID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get sessions",
+			"details": err.Error(),
+		})
+	}
+
+	if claims, ok := c.Locals("claims").(*models.JWTClaims); ok && claims.SessionID != "" {
+		for i := range sessions {
+			sessions[i].Current = sessions[i].ID.String() == claims.SessionID
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": sessions,
+	})
+}
+
+// revokeSession - sesi ":sessionId" (admin) atau ":id" (user sendiri) harus milik ownerID
+func (s *SessionService) revokeSession(c *fiber.Ctx, ownerID uuid.UUID, reason string) error {
+	param := c.Params("sessionId")
+	if param == "" {
+		param = c.Params("id")
+	}
+
+	sessionID, err := uuid.Parse(param)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid session ID",
+		})
+	}
+
+	session, err := s.sessionRepo.GetByID(sessionID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get session",
+			"details": err.Error(),
+		})
+	}
+	if session == nil || session.UserID != ownerID {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Session not found",
+		})
+	}
+
+	if err := s.refreshTokenRepo.RevokeFamily(session.FamilyID, reason); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke session",
+			"details": err.Error(),
+		})
+	}
+
+	// Semua access token sesi ini (klaim sid) langsung ditolak, termasuk yang terbit
+	// sebelum refresh terakhir
+	if err := s.tokenRevocationRepo.RevokeSession(session.ID, session.UserID, reason, utils.AccessTokenTTL); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to revoke session access tokens",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Session revoked successfully",
+		"data": fiber.Map{
+			"session_id": session.ID,
+		},
+	})
+}
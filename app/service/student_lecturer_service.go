@@ -3,6 +3,7 @@ package service
 import (
 	"context"
 
+	"UAS/app/authz"
 	"UAS/app/models"
 	"UAS/app/repository"
 
@@ -17,6 +18,7 @@ type StudentLecturerService struct {
 	roleRepo           repository.RoleRepository
 	achievementRepo    repository.AchievementRepository
 	achievementRefRepo repository.AchievementReferenceRepository
+	authorizer         *authz.Authorizer
 }
 
 func NewStudentLecturerService(
@@ -26,6 +28,7 @@ func NewStudentLecturerService(
 	roleRepo repository.RoleRepository,
 	achievementRepo repository.AchievementRepository,
 	achievementRefRepo repository.AchievementReferenceRepository,
+	authorizer *authz.Authorizer,
 ) *StudentLecturerService {
 	return &StudentLecturerService{
 		studentRepo:        studentRepo,
@@ -34,9 +37,31 @@ func NewStudentLecturerService(
 		roleRepo:           roleRepo,
 		achievementRepo:    achievementRepo,
 		achievementRefRepo: achievementRefRepo,
+		authorizer:         authorizer,
 	}
 }
 
+// checkStudentAccess - checkAccess dengan resource berupa mahasiswa
+func (s *StudentLecturerService) checkStudentAccess(c *fiber.Ctx, action authz.Action, student *models.Student) (int, fiber.Map) {
+	resource, err := s.authorizer.StudentResource(student)
+	if err != nil {
+		return 500, fiber.Map{"error": "Failed to check access"}
+	}
+	return s.checkAccess(c, action, resource)
+}
+
+// checkAccess - cek policy untuk actor saat ini; status 0 berarti diizinkan
+func (s *StudentLecturerService) checkAccess(c *fiber.Ctx, action authz.Action, resource authz.Resource) (int, fiber.Map) {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return 500, fiber.Map{"error": "Failed to check access"}
+	}
+	if !s.authorizer.Can(actor, action, resource) {
+		return 403, fiber.Map{"error": "Access denied"}
+	}
+	return 0, nil
+}
+
 
 // 1. GET /api/v1/students
 // GetAllStudents godoc
@@ -52,6 +77,16 @@ func NewStudentLecturerService(
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /students [get]
 func (s *StudentLecturerService) GetAllStudents(c *fiber.Ctx) error {
+	actor, err := s.authorizer.ActorFromContext(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to check access"})
+	}
+
+	grants := s.authorizer.Grants(actor, authz.ActionStudentList)
+	if !grants.Has(authz.RelationAny) && !grants.Has(authz.RelationScope) {
+		return c.Status(403).JSON(fiber.Map{"error": "Access denied"})
+	}
+
 	// Get all students dari repository
 	students, err := s.studentRepo.GetAll()
 	if err != nil {
@@ -61,6 +96,29 @@ func (s *StudentLecturerService) GetAllStudents(c *fiber.Ctx) error {
 		})
 	}
 
+	// Kaprodi / pemegang scope hanya melihat mahasiswa dalam scope-nya
+	if !grants.Has(authz.RelationAny) {
+		scopedIDs, err := s.authorizer.ScopedStudentIDs(actor)
+		if err != nil {
+			return c.Status(500).JSON(fiber.Map{
+				"error":   "Failed to get scoped students",
+				"details": err.Error(),
+			})
+		}
+		allowed := make(map[uuid.UUID]bool, len(scopedIDs))
+		for _, id := range scopedIDs {
+			allowed[id] = true
+		}
+
+		var scoped []models.Student
+		for _, student := range students {
+			if allowed[student.ID] {
+				scoped = append(scoped, student)
+			}
+		}
+		students = scoped
+	}
+
 	// Enrich dengan data user
 	var enrichedStudents []models.StudentResponse
 	for _, student := range students {
@@ -139,6 +197,10 @@ func (s *StudentLecturerService) GetStudentByID(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Student not found"})
 	}
 
+	if status, resp := s.checkStudentAccess(c, authz.ActionStudentRead, student); status != 0 {
+		return c.Status(status).JSON(resp)
+	}
+
 	// Get user details
 	user, err := s.userRepo.GetByID(student.UserID)
 	if err != nil {
@@ -221,6 +283,10 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Student not found"})
 	}
 
+	if status, resp := s.checkStudentAccess(c, authz.ActionAchievementList, student); status != 0 {
+		return c.Status(status).JSON(resp)
+	}
+
 	// Get query parameters
 	status := c.Query("status", "")
 	page := c.QueryInt("page", 1)
@@ -287,7 +353,7 @@ func (s *StudentLecturerService) GetStudentAchievements(c *fiber.Ctx) error {
 			"description":     achievement.Description,
 			"achievement_type": achievement.AchievementType,
 			"status":          ref.Status,
-			"points":          achievement.Points,
+			"points":          displayPoints(&ref, achievement),
 			"submitted_at":    ref.SubmittedAt,
 			"verified_at":     ref.VerifiedAt,
 			"verified_by": fiber.Map{
@@ -365,6 +431,10 @@ func (s *StudentLecturerService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Student not found"})
 	}
 
+	if status, resp := s.checkStudentAccess(c, authz.ActionStudentAssign, student); status != 0 {
+		return c.Status(status).JSON(resp)
+	}
+
 	var advisorID *uuid.UUID
 	var advisorName string
 
@@ -462,6 +532,10 @@ func (s *StudentLecturerService) UpdateStudentAdvisor(c *fiber.Ctx) error {
 // @Failure 500 {object} map[string]interface{} "Internal Server Error"
 // @Router /lecturers [get]
 func (s *StudentLecturerService) GetAllLecturers(c *fiber.Ctx) error {
+	if status, resp := s.checkAccess(c, authz.ActionLecturerList, authz.Resource{}); status != 0 {
+		return c.Status(status).JSON(resp)
+	}
+
 	// Get query parameters
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
@@ -592,6 +666,10 @@ func (s *StudentLecturerService) GetLecturerAdvisees(c *fiber.Ctx) error {
 		return c.Status(404).JSON(fiber.Map{"error": "Lecturer not found"})
 	}
 
+	if status, resp := s.checkAccess(c, authz.ActionLecturerAdvisees, authz.LecturerResource(lecturer.ID)); status != 0 {
+		return c.Status(status).JSON(resp)
+	}
+
 	// Get query parameters
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
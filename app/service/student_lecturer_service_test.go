@@ -0,0 +1,74 @@
+package service
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestUpdateStudentAdvisorRespectsAPIKeyPermissions(t *testing.T) {
+	adminRole := &models.Role{ID: uuid.New(), Name: "Admin"}
+	admin := &models.User{ID: uuid.New(), RoleID: adminRole.ID, FullName: "Admin", IsActive: true}
+	studentUser := &models.User{ID: uuid.New(), FullName: "Mahasiswa"}
+	advisorID := uuid.New()
+	student := &models.Student{ID: uuid.New(), UserID: studentUser.ID, StudentID: "2021001", AdvisorID: &advisorID}
+
+	roleRepo := &fakeRoleRepo{roles: map[uuid.UUID]*models.Role{adminRole.ID: adminRole}}
+	studentRepo := &fakeStudentRepo{students: map[uuid.UUID]*models.Student{student.ID: student}}
+	lecturerRepo := &fakeLecturerRepo{}
+	userRepo := &fakeUserRepo{users: map[uuid.UUID]*models.User{admin.ID: admin, studentUser.ID: studentUser}}
+
+	authorizer := authz.NewAuthorizer(roleRepo, studentRepo, lecturerRepo, &fakeUserScopeRepo{}, nil)
+	svc := NewStudentLecturerService(studentRepo, lecturerRepo, userRepo, roleRepo, nil, nil, authorizer)
+
+	tests := []struct {
+		name        string
+		apiKey      bool
+		permissions []string
+		wantStatus  int
+	}{
+		{"admin login", false, []string{"achievement:read", "user:manage"}, fiber.StatusOK},
+		{"read-only api key", true, []string{"achievement:read"}, fiber.StatusForbidden},
+		{"api key without permissions", true, []string{}, fiber.StatusForbidden},
+		{"api key with user:manage", true, []string{"user:manage"}, fiber.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			student.AdvisorID = &advisorID
+
+			app := fiber.New()
+			// Meniru middleware.RequireAuth
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("user", admin)
+				c.Locals("user_id", admin.ID)
+				c.Locals("role_id", admin.RoleID)
+				c.Locals("permissions", tt.permissions)
+				if tt.apiKey {
+					c.Locals("api_key", &models.APIKey{ID: uuid.New(), UserID: admin.ID})
+				}
+				return c.Next()
+			})
+			app.Put("/students/:id/advisor", svc.UpdateStudentAdvisor)
+
+			req := httptest.NewRequest("PUT", "/students/"+student.ID.String()+"/advisor", strings.NewReader(`{"advisor_id": null}`))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus == fiber.StatusForbidden && student.AdvisorID == nil {
+				t.Fatal("advisor was removed despite 403")
+			}
+		})
+	}
+}
@@ -0,0 +1,438 @@
+package service
+
+import (
+	"errors"
+	"time"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+	"UAS/config"
+	"UAS/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const recoveryCodeCount = 10
+
+type TwoFactorService struct {
+	authService   *AuthService
+	userRepo      repository.UserRepository
+	roleRepo      repository.RoleRepository
+	twoFactorRepo repository.TwoFactorRepository
+}
+
+func NewTwoFactorService(
+	authService *AuthService,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+) *TwoFactorService {
+	return &TwoFactorService{
+		authService:   authService,
+		userRepo:      userRepo,
+		roleRepo:      roleRepo,
+		twoFactorRepo: twoFactorRepo,
+	}
+}
+
+// Enroll godoc
+// @Summary Start two-factor enrollment
+// @Description Generate a new TOTP secret, otpauth URI and recovery codes. 2FA is not active until the first code is confirmed via /auth/2fa/verify. Recovery codes are only shown once.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} models.TwoFactorEnrollResponse "Secret, otpauth URI and recovery codes"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Not authenticated"
+// @Failure 409 {object} map[string]interface{} "Conflict - 2FA already enabled"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/2fa/enroll [post]
+func (s *TwoFactorService) Enroll(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	return s.beginEnrollment(c, user)
+}
+
+// Verify godoc
+// @Summary Confirm two-factor enrollment
+// @Description Activate 2FA by submitting the first code from the authenticator app
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication enabled"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid code or no pending enrollment"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Not authenticated"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/2fa/verify [post]
+func (s *TwoFactorService) Verify(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	if err := s.confirmEnrollment(user.ID, req.Code); err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor authentication enabled",
+	})
+}
+
+// Disable godoc
+// @Summary Disable two-factor authentication
+// @Description Disable 2FA after confirming the password and a TOTP or recovery code. Not allowed when the user's role requires 2FA.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body models.TwoFactorDisableRequest true "Password and TOTP/recovery code"
+// @Success 200 {object} map[string]interface{} "Two-factor authentication disabled"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid password or code"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Not authenticated"
+// @Failure 403 {object} map[string]interface{} "Forbidden - 2FA is required for this role"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/2fa/disable [post]
+func (s *TwoFactorService) Disable(c *fiber.Ctx) error {
+	user, ok := c.Locals("user").(*models.User)
+	if !ok {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "User not authenticated",
+		})
+	}
+
+	var req models.TwoFactorDisableRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	role, err := s.roleRepo.GetByID(user.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error getting role information",
+			"details": err.Error(),
+		})
+	}
+	if role != nil && role.RequireTwoFactor {
+		return c.Status(403).JSON(fiber.Map{
+			"error": "Two-factor authentication is required for role " + role.Name,
+		})
+	}
+
+	if !utils.CheckPasswordHash(req.Password, user.PasswordHash) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Password is incorrect",
+		})
+	}
+
+	twoFactor, err := s.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking two-factor status",
+			"details": err.Error(),
+		})
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Two-factor authentication is not enabled",
+		})
+	}
+
+	valid, err := s.checkCode(twoFactor, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error verifying code",
+			"details": err.Error(),
+		})
+	}
+	if !valid {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid two-factor code",
+		})
+	}
+
+	if err := s.twoFactorRepo.Disable(user.ID); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to disable two-factor authentication",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "Two-factor authentication disabled",
+	})
+}
+
+// Login godoc
+// @Summary Complete login with two-factor code
+// @Description Second login step: exchange the challenge token from /auth/login plus a TOTP or recovery code for access and refresh tokens
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Challenge token and TOTP/recovery code"
+// @Success 200 {object} models.LoginResponse "Login successful"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid request body"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Invalid challenge token or code"
+// @Failure 423 {object} map[string]interface{} "Locked - Too many failed attempts"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/2fa/login [post]
+func (s *TwoFactorService) Login(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := s.challengeUser(req.ChallengeToken, models.TwoFactorPurposeVerify)
+	if err != nil || user == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid or expired challenge token",
+		})
+	}
+
+	if locked, err := s.authService.RejectLockedAccount(c, user, user.Username); locked {
+		return err
+	}
+
+	twoFactor, err := s.twoFactorRepo.Get(user.ID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error checking two-factor status",
+			"details": err.Error(),
+		})
+	}
+	if twoFactor == nil || !twoFactor.Enabled {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid or expired challenge token",
+		})
+	}
+
+	valid, err := s.checkCode(twoFactor, req.Code)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error verifying code",
+			"details": err.Error(),
+		})
+	}
+	if !valid {
+		return s.authService.FailLogin(c, user, user.Username, models.LoginFailureInvalidTwoFactor)
+	}
+
+	return s.completeLogin(c, user)
+}
+
+// SetupEnroll godoc
+// @Summary Start forced two-factor enrollment during login
+// @Description For users whose role requires 2FA but who have not enrolled yet. Uses the setup challenge token returned by /auth/login.
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Param request body map[string]interface{} true "Setup challenge token" SchemaExample({"challengeToken": "eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."})
+// @Success 200 {object} models.TwoFactorEnrollResponse "Secret, otpauth URI and recovery codes"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Invalid challenge token"
+// @Failure 409 {object} map[string]interface{} "Conflict - 2FA already enabled"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/2fa/setup [post]
+func (s *TwoFactorService) SetupEnroll(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := s.challengeUser(req.ChallengeToken, models.TwoFactorPurposeSetup)
+	if err != nil || user == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid or expired challenge token",
+		})
+	}
+
+	return s.beginEnrollment(c, user)
+}
+
+// SetupVerify godoc
+// @Summary Confirm forced two-factor enrollment and finish login
+// @Description Activate 2FA with the first TOTP code and receive access and refresh tokens
+// @Tags Two-Factor Authentication
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "Setup challenge token and TOTP code"
+// @Success 200 {object} models.LoginResponse "Login successful"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid code"
+// @Failure 401 {object} map[string]interface{} "Unauthorized - Invalid challenge token"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /auth/2fa/setup/verify [post]
+func (s *TwoFactorService) SetupVerify(c *fiber.Ctx) error {
+	var req models.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	user, err := s.challengeUser(req.ChallengeToken, models.TwoFactorPurposeSetup)
+	if err != nil || user == nil {
+		return c.Status(401).JSON(fiber.Map{
+			"error": "Invalid or expired challenge token",
+		})
+	}
+
+	if err := s.confirmEnrollment(user.ID, req.Code); err != nil {
+		return twoFactorErrorResponse(c, err)
+	}
+
+	return s.completeLogin(c, user)
+}
+
+var (
+	errNoPendingEnrollment = errors.New("no pending two-factor enrollment")
+	errInvalidTwoFactor    = errors.New("invalid two-factor code")
+)
+
+func (s *TwoFactorService) beginEnrollment(c *fiber.Ctx, user *models.User) error {
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate secret",
+			"details": err.Error(),
+		})
+	}
+
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to generate recovery codes",
+			"details": err.Error(),
+		})
+	}
+
+	hashes := make([]string, len(codes))
+	for i, code := range codes {
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(code))
+	}
+
+	if err := s.twoFactorRepo.SaveEnrollment(user.ID, secret, hashes); err != nil {
+		if errors.Is(err, repository.ErrTwoFactorAlreadyEnabled) {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "Two-factor authentication is already enabled",
+			})
+		}
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to save two-factor enrollment",
+			"details": err.Error(),
+		})
+	}
+
+	issuer := config.GetEnv("TOTP_ISSUER", "UAS Achievement System")
+
+	return c.JSON(models.TwoFactorEnrollResponse{
+		Secret:        secret,
+		OTPAuthURI:    utils.TOTPURI(issuer, user.Username, secret),
+		RecoveryCodes: codes,
+	})
+}
+
+// confirmEnrollment - enrollment hanya aktif setelah kode pertama dari aplikasi authenticator cocok
+func (s *TwoFactorService) confirmEnrollment(userID uuid.UUID, code string) error {
+	twoFactor, err := s.twoFactorRepo.Get(userID)
+	if err != nil {
+		return err
+	}
+	if twoFactor == nil || twoFactor.Enabled {
+		return errNoPendingEnrollment
+	}
+
+	step, ok := utils.ValidateTOTP(twoFactor.Secret, code, time.Now())
+	if !ok {
+		return errInvalidTwoFactor
+	}
+
+	used, err := s.twoFactorRepo.MarkStepUsed(userID, step)
+	if err != nil {
+		return err
+	}
+	if !used {
+		return errInvalidTwoFactor
+	}
+
+	return s.twoFactorRepo.Enable(userID)
+}
+
+// checkCode - terima kode TOTP (tidak boleh dipakai ulang) atau recovery code sekali pakai
+func (s *TwoFactorService) checkCode(twoFactor *models.UserTwoFactor, code string) (bool, error) {
+	if step, ok := utils.ValidateTOTP(twoFactor.Secret, code, time.Now()); ok {
+		return s.twoFactorRepo.MarkStepUsed(twoFactor.UserID, step)
+	}
+
+	normalized := utils.NormalizeRecoveryCode(code)
+	if normalized == "" {
+		return false, nil
+	}
+
+	return s.twoFactorRepo.UseRecoveryCode(twoFactor.UserID, utils.HashToken(normalized))
+}
+
+func (s *TwoFactorService) challengeUser(challengeToken, purpose string) (*models.User, error) {
+	userID, err := utils.ValidateTwoFactorChallengeToken(challengeToken, purpose)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil || !user.IsActive {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (s *TwoFactorService) completeLogin(c *fiber.Ctx, user *models.User) error {
+	role, err := s.roleRepo.GetByID(user.RoleID)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Error getting role information",
+			"details": err.Error(),
+		})
+	}
+
+	return s.authService.CompleteLogin(c, user, role, user.Username)
+}
+
+func twoFactorErrorResponse(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, errNoPendingEnrollment):
+		return c.Status(400).JSON(fiber.Map{
+			"error": "No pending two-factor enrollment, call enroll first",
+		})
+	case errors.Is(err, errInvalidTwoFactor):
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid two-factor code",
+		})
+	}
+
+	return c.Status(500).JSON(fiber.Map{
+		"error":   "Failed to verify two-factor code",
+		"details": err.Error(),
+	})
+}
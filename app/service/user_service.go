@@ -3,6 +3,8 @@ package service
 import (
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
 	"UAS/app/models"
@@ -14,10 +16,13 @@ import (
 )
 
 type UserService struct {
-	userRepo     repository.UserRepository
-	roleRepo     repository.RoleRepository
-	studentRepo  repository.StudentRepository
-	lecturerRepo repository.LecturerRepository
+	userRepo            repository.UserRepository
+	roleRepo            repository.RoleRepository
+	studentRepo         repository.StudentRepository
+	lecturerRepo        repository.LecturerRepository
+	tokenRevocationRepo repository.TokenRevocationRepository
+	loginAttemptRepo    repository.LoginAttemptRepository
+	userScopeRepo       repository.UserScopeRepository
 }
 
 func NewUserService(
@@ -25,12 +30,18 @@ func NewUserService(
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	tokenRevocationRepo repository.TokenRevocationRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	userScopeRepo repository.UserScopeRepository,
 ) *UserService {
 	return &UserService{
-		userRepo:     userRepo,
-		roleRepo:     roleRepo,
-		studentRepo:  studentRepo,
-		lecturerRepo: lecturerRepo,
+		userRepo:            userRepo,
+		roleRepo:            roleRepo,
+		studentRepo:         studentRepo,
+		lecturerRepo:        lecturerRepo,
+		tokenRevocationRepo: tokenRevocationRepo,
+		loginAttemptRepo:    loginAttemptRepo,
+		userScopeRepo:       userScopeRepo,
 	}
 }
 
@@ -645,4 +656,375 @@ func (s *UserService) SearchByName(c *fiber.Ctx) error {
 			"has_prev":    hasPrev,
 		},
 	})
-}
\ No newline at end of file
+}
+
+// RevokeSessions godoc
+// @Summary Revoke all sessions of a user
+// @Description Revoke every access and refresh token issued to the user so far. The user has to log in again. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Param request body map[string]interface{} false "Revocation reason" SchemaExample({"reason": "Account compromised"})
+// @Success 200 {object} map[string]interface{} "Sessions revoked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid user ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/revoke-sessions [post]
+func (s *UserService) RevokeSessions(c *fiber.Ctx) error {
+	idStr := c.Params("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req struct {
+		Reason string `json:"reason"`
+	}
+	_ = c.BodyParser(&req)
+	if req.Reason == "" {
+		req.Reason = "revoked by admin"
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found or inactive",
+		})
+	}
+
+	var revokedBy *uuid.UUID
+	if adminID, ok := c.Locals("user_id").(uuid.UUID); ok {
+		revokedBy = &adminID
+	}
+
+	revocation := &models.UserTokenRevocation{
+		UserID:        id,
+		RevokedBefore: time.Now(),
+		RevokedBy:     revokedBy,
+		Reason:        req.Reason,
+	}
+	if err := s.tokenRevocationRepo.RevokeAllForUser(revocation); err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error": "Failed to revoke sessions",
+			"details": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "All sessions revoked successfully",
+		"data": fiber.Map{
+			"user_id":        id,
+			"revoked_before": revocation.RevokedBefore,
+			"reason":         revocation.Reason,
+		},
+	})
+}
+// GetLockedAccounts godoc
+// @Summary Get locked accounts
+// @Description Get accounts that are currently locked because of too many failed login attempts. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number" minimum(1) default(1)
+// @Param limit query int false "Items per page" minimum(1) maximum(100) default(10)
+// @Success 200 {object} map[string]interface{} "List of locked accounts"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/locked [get]
+func (s *UserService) GetLockedAccounts(c *fiber.Ctx) error {
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	if page < 1 {
+		page = 1
+	}
+	if limit < 1 || limit > 100 {
+		limit = 10
+	}
+
+	accounts, total, err := s.loginAttemptRepo.GetLockedAccounts(page, limit)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get locked accounts",
+			"details": err.Error(),
+		})
+	}
+
+	totalPages := (total + limit - 1) / limit
+
+	return c.JSON(fiber.Map{
+		"data": accounts,
+		"pagination": fiber.Map{
+			"page":        page,
+			"limit":       limit,
+			"total":       total,
+			"total_pages": totalPages,
+			"has_next":    page < totalPages,
+			"has_prev":    page > 1,
+		},
+	})
+}
+
+// UnlockAccount godoc
+// @Summary Unlock a locked account
+// @Description Remove the login lockout of a user and reset the failed attempt counter. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Account unlocked successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid user ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Account is not locked"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/unlock [post]
+func (s *UserService) UnlockAccount(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	unlocked, err := s.loginAttemptRepo.Unlock(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to unlock account",
+			"details": err.Error(),
+		})
+	}
+	if !unlocked {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Account is not locked",
+		})
+	}
+
+	adminID, _ := c.Locals("user_id").(uuid.UUID)
+	log.Printf("SECURITY: account %s unlocked by admin %s", id, adminID)
+
+	return c.JSON(fiber.Map{
+		"message": "Account unlocked successfully",
+		"data": fiber.Map{
+			"user_id": id,
+		},
+	})
+}
+
+// GetUserScopes godoc
+// @Summary Get user access scopes
+// @Description Get program study / department scopes assigned to a user (e.g. Kaprodi). Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Success 200 {object} map[string]interface{} "List of scopes"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid user ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - User not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/scopes [get]
+func (s *UserService) GetUserScopes(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found or inactive",
+		})
+	}
+
+	scopes, err := s.userScopeRepo.GetByUserID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get user scopes",
+			"details": err.Error(),
+		})
+	}
+	if scopes == nil {
+		scopes = []models.UserScope{}
+	}
+
+	return c.JSON(fiber.Map{
+		"data": scopes,
+	})
+}
+
+// AddUserScope godoc
+// @Summary Assign access scope to user
+// @Description Let a user see, report on and verify achievements of all students in a program study, or whose advisor belongs to a department. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Param request body models.CreateUserScopeRequest true "Scope (scopeType: program_study | department)"
+// @Success 201 {object} map[string]interface{} "Scope assigned successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid scope"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - User not found"
+// @Failure 409 {object} map[string]interface{} "Conflict - Scope already assigned"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/scopes [post]
+func (s *UserService) AddUserScope(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	var req models.CreateUserScopeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid request body",
+		})
+	}
+
+	req.ScopeValue = strings.TrimSpace(req.ScopeValue)
+	if !contains(models.ValidScopeTypes, req.ScopeType) {
+		return c.Status(400).JSON(fiber.Map{
+			"error": fmt.Sprintf("Invalid scope type. Valid types: %v", models.ValidScopeTypes),
+		})
+	}
+	if req.ScopeValue == "" || len(req.ScopeValue) > 100 {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Scope value must be between 1 and 100 characters",
+		})
+	}
+
+	user, err := s.userRepo.GetByID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to check user",
+			"details": err.Error(),
+		})
+	}
+	if user == nil {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "User not found or inactive",
+		})
+	}
+
+	existing, err := s.userScopeRepo.GetByUserID(id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to get user scopes",
+			"details": err.Error(),
+		})
+	}
+	for _, scope := range existing {
+		if scope.ScopeType == req.ScopeType && scope.ScopeValue == req.ScopeValue {
+			return c.Status(409).JSON(fiber.Map{
+				"error": "Scope already assigned to user",
+			})
+		}
+	}
+
+	scope := models.UserScope{
+		UserID:     id,
+		ScopeType:  req.ScopeType,
+		ScopeValue: req.ScopeValue,
+	}
+	adminID, ok := c.Locals("user_id").(uuid.UUID)
+	if ok {
+		scope.CreatedBy = &adminID
+	}
+
+	scope.ID, err = s.userScopeRepo.Create(scope)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to assign scope",
+			"details": err.Error(),
+		})
+	}
+	scope.CreatedAt = time.Now()
+
+	log.Printf("SECURITY: scope %s=%s assigned to user %s by admin %s", scope.ScopeType, scope.ScopeValue, id, adminID)
+
+	return c.Status(201).JSON(fiber.Map{
+		"message": "Scope assigned successfully",
+		"data":    scope,
+	})
+}
+
+// RemoveUserScope godoc
+// @Summary Remove access scope from user
+// @Description Remove a program study / department scope from a user. Admin only.
+// @Tags Users
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "User ID (UUID)"
+// @Param scopeId path string true "Scope ID (UUID)"
+// @Success 200 {object} map[string]interface{} "Scope removed successfully"
+// @Failure 400 {object} map[string]interface{} "Bad Request - Invalid ID"
+// @Failure 401 {object} map[string]interface{} "Unauthorized"
+// @Failure 403 {object} map[string]interface{} "Forbidden - Admin access required"
+// @Failure 404 {object} map[string]interface{} "Not Found - Scope not found"
+// @Failure 500 {object} map[string]interface{} "Internal Server Error"
+// @Router /users/{id}/scopes/{scopeId} [delete]
+func (s *UserService) RemoveUserScope(c *fiber.Ctx) error {
+	id, err := uuid.Parse(c.Params("id"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid user ID",
+		})
+	}
+
+	scopeID, err := uuid.Parse(c.Params("scopeId"))
+	if err != nil {
+		return c.Status(400).JSON(fiber.Map{
+			"error": "Invalid scope ID",
+		})
+	}
+
+	deleted, err := s.userScopeRepo.Delete(scopeID, id)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{
+			"error":   "Failed to remove scope",
+			"details": err.Error(),
+		})
+	}
+	if !deleted {
+		return c.Status(404).JSON(fiber.Map{
+			"error": "Scope not found",
+		})
+	}
+
+	adminID, _ := c.Locals("user_id").(uuid.UUID)
+	log.Printf("SECURITY: scope %s removed from user %s by admin %s", scopeID, id, adminID)
+
+	return c.JSON(fiber.Map{
+		"message": "Scope removed successfully",
+	})
+}
@@ -0,0 +1,159 @@
+package workflow
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+)
+
+// AnyType - definisi default untuk tipe prestasi yang tidak punya definisi sendiri
+const AnyType = "*"
+
+// Condition - stage hanya berlaku jika salah satu kondisi terpenuhi.
+// Stage tanpa kondisi selalu berlaku.
+type Condition struct {
+	CompetitionLevels []string `json:"competition_levels,omitempty"`
+	MinPoints         int      `json:"min_points,omitempty"`
+}
+
+func (c *Condition) Matches(achievement *models.Achievement) bool {
+	if c == nil {
+		return true
+	}
+	for _, level := range c.CompetitionLevels {
+		if achievement.Details.CompetitionLevel == level {
+			return true
+		}
+	}
+	return c.MinPoints > 0 && achievement.Points >= c.MinPoints
+}
+
+// Stage - satu tahap verifikasi. Yang boleh approve/reject: role di Roles,
+// atau user yang punya salah satu Relations terhadap mahasiswa pemilik (advisor / scope).
+type Stage struct {
+	Name      string           `json:"name"`
+	Roles     []string         `json:"roles,omitempty"`
+	Relations []authz.Relation `json:"relations,omitempty"`
+	When      *Condition       `json:"when,omitempty"`
+}
+
+// Definition - stage berurutan untuk satu tipe prestasi
+type Definition struct {
+	Stages []Stage `json:"stages"`
+}
+
+// Workflows - tipe prestasi -> Definition, dengan fallback AnyType
+type Workflows map[string]Definition
+
+// DefaultWorkflows - dosen wali atau pemegang scope prodi / departemen (mis. Kaprodi) untuk
+// semua prestasi; prestasi tingkat nasional / internasional atau dengan poin >= facultyMinPoints
+// juga harus disetujui reviewer fakultas (role "Reviewer Fakultas" atau user dengan scope
+// departemen/prodi) lalu admin.
+func DefaultWorkflows(facultyMinPoints int) Workflows {
+	facultyReview := &Condition{
+		CompetitionLevels: []string{"national", "international"},
+		MinPoints:         facultyMinPoints,
+	}
+	return Workflows{
+		AnyType: {
+			Stages: []Stage{
+				{Name: "advisor", Roles: []string{"Admin"}, Relations: []authz.Relation{authz.RelationAdvisor, authz.RelationScope}},
+				{Name: "faculty", Roles: []string{"Admin", "Reviewer Fakultas"}, Relations: []authz.Relation{authz.RelationScope}, When: facultyReview},
+				{Name: "final", Roles: []string{"Admin"}, When: facultyReview},
+			},
+		},
+	}
+}
+
+// Load - baca definisi dari file JSON ({"<tipe>": {"stages": [...]}}), atau default jika path kosong
+func Load(path string, facultyMinPoints int) (Workflows, error) {
+	if path == "" {
+		return DefaultWorkflows(facultyMinPoints), nil
+	}
+
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read workflow file: %w", err)
+	}
+
+	var workflows Workflows
+	if err := json.Unmarshal(raw, &workflows); err != nil {
+		return nil, fmt.Errorf("invalid workflow file: %w", err)
+	}
+	if _, ok := workflows[AnyType]; !ok {
+		workflows[AnyType] = DefaultWorkflows(facultyMinPoints)[AnyType]
+	}
+	if err := workflows.Validate(); err != nil {
+		return nil, err
+	}
+	return workflows, nil
+}
+
+func (w Workflows) Validate() error {
+	for achievementType, def := range w {
+		if len(def.Stages) == 0 {
+			return fmt.Errorf("workflow %q has no stages", achievementType)
+		}
+		// Stage pertama tanpa kondisi supaya setiap prestasi punya minimal satu stage
+		if def.Stages[0].When != nil {
+			return fmt.Errorf("first stage of workflow %q must not have a condition", achievementType)
+		}
+		seen := map[string]bool{}
+		for _, stage := range def.Stages {
+			if stage.Name == "" {
+				return fmt.Errorf("workflow %q has a stage without name", achievementType)
+			}
+			if seen[stage.Name] {
+				return fmt.Errorf("workflow %q has duplicate stage %q", achievementType, stage.Name)
+			}
+			seen[stage.Name] = true
+			if len(stage.Roles) == 0 && len(stage.Relations) == 0 {
+				return fmt.Errorf("stage %q of workflow %q has nobody responsible", stage.Name, achievementType)
+			}
+		}
+	}
+	return nil
+}
+
+func (w Workflows) For(achievementType string) Definition {
+	if def, ok := w[achievementType]; ok {
+		return def
+	}
+	return w[AnyType]
+}
+
+// Stages - stage yang berlaku untuk achievement ini, sesuai urutan definisi
+func (w Workflows) Stages(achievement *models.Achievement) []Stage {
+	var stages []Stage
+	for _, stage := range w.For(achievement.AchievementType).Stages {
+		if stage.When.Matches(achievement) {
+			stages = append(stages, stage)
+		}
+	}
+	return stages
+}
+
+// Current - stage dengan nama tersebut; nama kosong / tidak dikenal (data sebelum
+// workflow ada) dianggap stage pertama
+func Current(stages []Stage, name string) (*Stage, int) {
+	for i := range stages {
+		if stages[i].Name == name {
+			return &stages[i], i
+		}
+	}
+	if len(stages) == 0 {
+		return nil, -1
+	}
+	return &stages[0], 0
+}
+
+// Next - stage setelah index, nil jika sudah stage terakhir
+func Next(stages []Stage, index int) *Stage {
+	if index+1 < len(stages) {
+		return &stages[index+1]
+	}
+	return nil
+}
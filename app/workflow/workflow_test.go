@@ -0,0 +1,202 @@
+package workflow
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"UAS/app/authz"
+	"UAS/app/models"
+)
+
+func stageNames(stages []Stage) []string {
+	names := make([]string, len(stages))
+	for i, stage := range stages {
+		names[i] = stage.Name
+	}
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestDefaultWorkflowStages(t *testing.T) {
+	workflows := DefaultWorkflows(50)
+
+	tests := []struct {
+		name        string
+		achievement models.Achievement
+		want        []string
+	}{
+		{"local competition", models.Achievement{AchievementType: "competition", Details: models.AchievementDetails{CompetitionLevel: "local"}}, []string{"advisor"}},
+		{"national competition", models.Achievement{AchievementType: "competition", Details: models.AchievementDetails{CompetitionLevel: "national"}}, []string{"advisor", "faculty", "final"}},
+		{"international competition", models.Achievement{AchievementType: "competition", Details: models.AchievementDetails{CompetitionLevel: "international"}}, []string{"advisor", "faculty", "final"}},
+		{"below point threshold", models.Achievement{AchievementType: "academic", Points: 49}, []string{"advisor"}},
+		{"at point threshold", models.Achievement{AchievementType: "academic", Points: 50}, []string{"advisor", "faculty", "final"}},
+		{"custom type uses default", models.Achievement{AchievementType: "hackathon"}, []string{"advisor"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := stageNames(workflows.Stages(&tt.achievement))
+			if !equalNames(got, tt.want) {
+				t.Fatalf("Stages = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDefaultWorkflowWithoutPointThreshold(t *testing.T) {
+	// facultyMinPoints 0 = hanya tingkat kompetisi yang memicu review fakultas
+	achievement := &models.Achievement{AchievementType: "academic", Points: 1000}
+	if got := stageNames(DefaultWorkflows(0).Stages(achievement)); !equalNames(got, []string{"advisor"}) {
+		t.Fatalf("Stages = %v, want [advisor]", got)
+	}
+}
+
+func TestDefaultWorkflowReviewers(t *testing.T) {
+	authorizer := authz.NewAuthorizer(nil, nil, nil, nil, nil)
+	stages := DefaultWorkflows(0)[AnyType].Stages
+	informatika := authz.Resource{ProgramStudy: "Informatika"}
+	kaprodi := &authz.Actor{RoleName: "Kaprodi", Scopes: []models.UserScope{{ScopeType: models.ScopeTypeProgramStudy, ScopeValue: "Informatika"}}}
+	otherKaprodi := &authz.Actor{RoleName: "Kaprodi", Scopes: []models.UserScope{{ScopeType: models.ScopeTypeProgramStudy, ScopeValue: "Sistem Informasi"}}}
+
+	tests := []struct {
+		name  string
+		stage string
+		actor *authz.Actor
+		want  bool
+	}{
+		{"scope holder approves advisor stage", "advisor", kaprodi, true},
+		{"scope holder outside scope", "advisor", otherKaprodi, false},
+		{"scope holder approves faculty stage", "faculty", kaprodi, true},
+		{"scope holder cannot approve final stage", "final", kaprodi, false},
+		{"admin approves final stage", "final", &authz.Actor{RoleName: "Admin"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, _ := Current(stages, tt.stage)
+			if got := authorizer.Satisfies(tt.actor, stage.Roles, stage.Relations, informatika); got != tt.want {
+				t.Fatalf("Satisfies = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCurrentAndNext(t *testing.T) {
+	stages := DefaultWorkflows(0)[AnyType].Stages
+
+	tests := []struct {
+		name      string
+		stored    string
+		wantStage string
+		wantIndex int
+		wantNext  string
+	}{
+		{"first stage", "advisor", "advisor", 0, "faculty"},
+		{"middle stage", "faculty", "faculty", 1, "final"},
+		{"last stage", "final", "final", 2, ""},
+		{"no stage stored", "", "advisor", 0, "faculty"},
+		{"stage removed from workflow", "dean", "advisor", 0, "faculty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stage, index := Current(stages, tt.stored)
+			if stage == nil || stage.Name != tt.wantStage || index != tt.wantIndex {
+				t.Fatalf("Current(%q) = %v, %d; want %s, %d", tt.stored, stage, index, tt.wantStage, tt.wantIndex)
+			}
+			next := Next(stages, index)
+			if tt.wantNext == "" {
+				if next != nil {
+					t.Fatalf("Next = %s, want nil", next.Name)
+				}
+				return
+			}
+			if next == nil || next.Name != tt.wantNext {
+				t.Fatalf("Next = %v, want %s", next, tt.wantNext)
+			}
+		})
+	}
+
+	if stage, index := Current(nil, "advisor"); stage != nil || index != -1 {
+		t.Fatalf("Current(nil) = %v, %d; want nil, -1", stage, index)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	advisor := Stage{Name: "advisor", Relations: []authz.Relation{authz.RelationAdvisor}}
+
+	tests := []struct {
+		name      string
+		workflows Workflows
+		wantErr   bool
+	}{
+		{"default", DefaultWorkflows(50), false},
+		{"no stages", Workflows{AnyType: {}}, true},
+		{"conditional first stage", Workflows{AnyType: {Stages: []Stage{{Name: "advisor", Roles: []string{"Admin"}, When: &Condition{MinPoints: 1}}}}}, true},
+		{"stage without name", Workflows{AnyType: {Stages: []Stage{advisor, {Roles: []string{"Admin"}}}}}, true},
+		{"duplicate stage", Workflows{AnyType: {Stages: []Stage{advisor, advisor}}}, true},
+		{"nobody responsible", Workflows{AnyType: {Stages: []Stage{advisor, {Name: "final"}}}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.workflows.Validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	// Tipe tanpa definisi sendiri tetap memakai default
+	path := write("publication.json", `{"publication": {"stages": [
+		{"name": "advisor", "relations": ["advisor"]},
+		{"name": "library", "roles": ["Pustakawan"]}
+	]}}`)
+	workflows, err := Load(path, 50)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	publication := &models.Achievement{AchievementType: "publication"}
+	if got := stageNames(workflows.Stages(publication)); !equalNames(got, []string{"advisor", "library"}) {
+		t.Fatalf("publication stages = %v", got)
+	}
+	competition := &models.Achievement{AchievementType: "competition", Details: models.AchievementDetails{CompetitionLevel: "national"}}
+	if got := stageNames(workflows.Stages(competition)); !equalNames(got, []string{"advisor", "faculty", "final"}) {
+		t.Fatalf("competition stages = %v", got)
+	}
+
+	if _, err := Load(write("invalid.json", `{"publication": {"stages": []}}`), 50); err == nil {
+		t.Fatal("Load accepted a workflow without stages")
+	}
+	if _, err := Load(write("broken.json", `{`), 50); err == nil {
+		t.Fatal("Load accepted invalid JSON")
+	}
+	if _, err := Load(filepath.Join(dir, "missing.json"), 50); err == nil {
+		t.Fatal("Load accepted a missing file")
+	}
+	if workflows, err := Load("", 50); err != nil || len(workflows) != 1 {
+		t.Fatalf("Load(\"\") = %v, %v; want default workflows", workflows, err)
+	}
+}
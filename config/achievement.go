@@ -0,0 +1,42 @@
+package config
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaxResubmissions - berapa kali prestasi yang ditolak boleh diajukan ulang.
+// Bisa di-override per tipe, mis. ACHIEVEMENT_MAX_RESUBMISSIONS_COMPETITION=1.
+// Nilai 0 berarti prestasi yang ditolak tidak bisa diajukan ulang.
+func MaxResubmissions(achievementType string) int {
+	limit := envNonNegativeInt("ACHIEVEMENT_MAX_RESUBMISSIONS", 3)
+	if achievementType == "" {
+		return limit
+	}
+	return envNonNegativeInt("ACHIEVEMENT_MAX_RESUBMISSIONS_"+strings.ToUpper(achievementType), limit)
+}
+
+func envNonNegativeInt(key string, fallback int) int {
+	value, err := strconv.Atoi(GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value < 0 {
+		return fallback
+	}
+	return value
+}
+
+// AchievementWorkflowFile - file JSON definisi workflow verifikasi per tipe prestasi (opsional)
+func AchievementWorkflowFile() string {
+	return GetEnv("ACHIEVEMENT_WORKFLOW_FILE", "")
+}
+
+// FacultyReviewMinPoints - prestasi dengan poin >= nilai ini wajib melewati reviewer fakultas
+// (dipakai workflow default)
+func FacultyReviewMinPoints() int {
+	return envInt("ACHIEVEMENT_FACULTY_MIN_POINTS", 50)
+}
+
+// CommentEditWindow - berapa lama setelah diposting komentar masih bisa diedit penulisnya
+func CommentEditWindow() time.Duration {
+	return time.Duration(envInt("COMMENT_EDIT_WINDOW_MINUTES", 15)) * time.Minute
+}
@@ -0,0 +1,8 @@
+package config
+
+import "time"
+
+// ImpersonationTTL - umur token "login as"; sengaja singkat dan tanpa refresh token
+func ImpersonationTTL() time.Duration {
+	return time.Duration(envInt("IMPERSONATION_TTL_MINUTES", 15)) * time.Minute
+}
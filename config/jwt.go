@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type JWTKeyConfig struct {
+	// HS256 (default), RS256 atau EdDSA
+	Algorithm string
+	// kid untuk key yang dipakai menandatangani token baru
+	KeyID string
+	// Secret HS256. Jika algoritma asimetris, secret tetap dipakai untuk verifikasi token lama tanpa kid
+	Secret string
+	// Private key PEM (isi langsung atau path file) untuk RS256/EdDSA
+	PrivateKeyPEM  string
+	PrivateKeyFile string
+	// Key tambahan yang hanya dipakai untuk verifikasi (rotasi), format: kid=path.pem,kid2=path2.pem
+	VerificationKeyFiles map[string]string
+}
+
+func JWTConfig() JWTKeyConfig {
+	cfg := JWTKeyConfig{
+		Algorithm:            strings.ToUpper(GetEnv("JWT_ALGORITHM", "HS256")),
+		KeyID:                os.Getenv("JWT_KEY_ID"),
+		Secret:               os.Getenv("JWT_SECRET"),
+		PrivateKeyPEM:        os.Getenv("JWT_PRIVATE_KEY"),
+		PrivateKeyFile:       os.Getenv("JWT_PRIVATE_KEY_FILE"),
+		VerificationKeyFiles: map[string]string{},
+	}
+
+	// EdDSA ditulis dengan huruf kecil "dsa" oleh library jwt
+	if cfg.Algorithm == "EDDSA" {
+		cfg.Algorithm = "EdDSA"
+	}
+
+	for _, entry := range strings.Split(os.Getenv("JWT_VERIFICATION_KEY_FILES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		cfg.VerificationKeyFiles[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+	}
+
+	return cfg
+}
@@ -10,9 +10,9 @@ import (
 func LoggerConfig() logger.Config {
 	var format string
 	if os.Getenv("APP_ENV") == "production" {
-		format = `{"time":"${time}", "status":${status}, "method":"${method}", "path":"${path}", "latency":"${latency}", "ip":"${ip}", "user_agent":"${ua}", "error":"${error}"}` + "\n"
+		format = `{"time":"${time}", "status":${status}, "method":"${method}", "path":"${path}", "latency":"${latency}", "ip":"${ip}", "user_agent":"${ua}", "error":"${error}", "request_id":"${locals:requestid}"}` + "\n"
 	} else {
-		format = "[${time}] ${status} - ${method} ${path} (${latency}) | IP: ${ip} | UA: ${ua}\n"
+		format = "[${time}] ${status} - ${method} ${path} (${latency}) | IP: ${ip} | UA: ${ua} | ReqID: ${locals:requestid}\n"
 		
 	}
 	
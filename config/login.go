@@ -0,0 +1,35 @@
+package config
+
+import (
+	"strconv"
+	"time"
+)
+
+type LoginProtectionSettings struct {
+	// Gagal berturut-turut sebelum akun dikunci
+	MaxFailedAttempts int
+	// Gagal dari satu IP dalam IPWindow sebelum IP ditolak sementara
+	MaxFailedAttemptsPerIP int
+	IPWindow               time.Duration
+	// Durasi lockout pertama, berlipat dua setiap lockout berikutnya sampai MaxLockout
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+func LoginProtectionConfig() LoginProtectionSettings {
+	return LoginProtectionSettings{
+		MaxFailedAttempts:      envInt("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+		MaxFailedAttemptsPerIP: envInt("LOGIN_MAX_FAILED_ATTEMPTS_PER_IP", 20),
+		IPWindow:               time.Duration(envInt("LOGIN_IP_WINDOW_MINUTES", 15)) * time.Minute,
+		BaseLockout:            time.Duration(envInt("LOGIN_LOCKOUT_BASE_MINUTES", 5)) * time.Minute,
+		MaxLockout:             time.Duration(envInt("LOGIN_LOCKOUT_MAX_MINUTES", 1440)) * time.Minute,
+	}
+}
+
+func envInt(key string, fallback int) int {
+	value, err := strconv.Atoi(GetEnv(key, strconv.Itoa(fallback)))
+	if err != nil || value <= 0 {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type MailSettings struct {
+	// smtp atau log (default)
+	Driver   string
+	From     string
+	Host     string
+	Port     int
+	Username string
+	Password string
+	// File tujuan untuk driver log, kosong = stdout
+	LogFile string
+}
+
+func MailConfig() MailSettings {
+	port, err := strconv.Atoi(GetEnv("SMTP_PORT", "587"))
+	if err != nil {
+		port = 587
+	}
+
+	return MailSettings{
+		Driver:   strings.ToLower(GetEnv("MAIL_DRIVER", "log")),
+		From:     GetEnv("MAIL_FROM", "no-reply@localhost"),
+		Host:     os.Getenv("SMTP_HOST"),
+		Port:     port,
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		LogFile:  os.Getenv("MAIL_LOG_FILE"),
+	}
+}
+
+type PasswordResetSettings struct {
+	// URL halaman frontend, token ditambahkan sebagai query ?token=
+	URL string
+	TTL time.Duration
+}
+
+func PasswordResetConfig() PasswordResetSettings {
+	return PasswordResetSettings{
+		URL: GetEnv("PASSWORD_RESET_URL", "http://localhost:3000/reset-password"),
+		TTL: time.Duration(envInt("PASSWORD_RESET_TTL_MINUTES", 30)) * time.Minute,
+	}
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"os"
+	"strings"
+)
+
+type OIDCSettings struct {
+	// Kosong = login SSO dimatikan
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string
+	Scopes       []string
+
+	// Role untuk user baru hasil just-in-time provisioning
+	DefaultRole     string
+	JITProvisioning bool
+
+	// Nama claim di ID token yang berisi NIM mahasiswa / NIP dosen
+	StudentIDClaim  string
+	LecturerIDClaim string
+}
+
+func (s OIDCSettings) Enabled() bool {
+	return s.Issuer != "" && s.ClientID != ""
+}
+
+func OIDCConfig() OIDCSettings {
+	cfg := OIDCSettings{
+		Issuer:          strings.TrimSuffix(os.Getenv("OIDC_ISSUER"), "/"),
+		ClientID:        os.Getenv("OIDC_CLIENT_ID"),
+		ClientSecret:    os.Getenv("OIDC_CLIENT_SECRET"),
+		RedirectURL:     GetEnv("OIDC_REDIRECT_URL", "http://localhost:3000/uas/api/auth/oidc/callback"),
+		DefaultRole:     GetEnv("OIDC_DEFAULT_ROLE", "Mahasiswa"),
+		JITProvisioning: strings.ToLower(GetEnv("OIDC_JIT_PROVISIONING", "true")) == "true",
+		StudentIDClaim:  GetEnv("OIDC_STUDENT_ID_CLAIM", "nim"),
+		LecturerIDClaim: GetEnv("OIDC_LECTURER_ID_CLAIM", "nip"),
+	}
+
+	for _, scope := range strings.Split(GetEnv("OIDC_SCOPES", "openid,email,profile"), ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			cfg.Scopes = append(cfg.Scopes, scope)
+		}
+	}
+
+	return cfg
+}
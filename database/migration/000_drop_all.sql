@@ -1,3 +1,25 @@
+DROP TABLE IF EXISTS achievement_members CASCADE;
+DROP TABLE IF EXISTS points_rule_sets CASCADE;
+DROP TABLE IF EXISTS achievement_type_fields CASCADE;
+DROP TABLE IF EXISTS achievement_types CASCADE;
+DROP TABLE IF EXISTS achievement_comments CASCADE;
+DROP TABLE IF EXISTS achievement_status_events CASCADE;
+DROP TABLE IF EXISTS impersonation_audit_logs CASCADE;
+DROP TABLE IF EXISTS impersonation_sessions CASCADE;
+DROP TABLE IF EXISTS user_scopes CASCADE;
+DROP TABLE IF EXISTS api_keys CASCADE;
+DROP TABLE IF EXISTS user_identities CASCADE;
+DROP TABLE IF EXISTS oidc_login_states CASCADE;
+DROP TABLE IF EXISTS user_sessions CASCADE;
+DROP TABLE IF EXISTS two_factor_recovery_codes CASCADE;
+DROP TABLE IF EXISTS user_two_factor CASCADE;
+DROP TABLE IF EXISTS account_lockouts CASCADE;
+DROP TABLE IF EXISTS login_attempts CASCADE;
+DROP TABLE IF EXISTS password_reset_tokens CASCADE;
+DROP TABLE IF EXISTS refresh_tokens CASCADE;
+DROP TABLE IF EXISTS refresh_token_families CASCADE;
+DROP TABLE IF EXISTS user_token_revocations CASCADE;
+DROP TABLE IF EXISTS revoked_tokens CASCADE;
 DROP TABLE IF EXISTS achievement_references CASCADE;
 DROP TABLE IF EXISTS students CASCADE;
 DROP TABLE IF EXISTS lecturers CASCADE;
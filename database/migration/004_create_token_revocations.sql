@@ -0,0 +1,19 @@
+-- 7. Revoked tokens (blacklist per JWT jti)
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    token_type VARCHAR(20) NOT NULL,
+    reason TEXT,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- 8. Revoke semua token user yang diterbitkan sebelum waktu tertentu
+CREATE TABLE IF NOT EXISTS user_token_revocations (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    revoked_before TIMESTAMP NOT NULL,
+    revoked_by UUID REFERENCES users(id),
+    reason TEXT
+);
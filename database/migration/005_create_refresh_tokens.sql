@@ -0,0 +1,23 @@
+-- 9. Refresh token families (satu family per login)
+CREATE TABLE IF NOT EXISTS refresh_token_families (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    revoked_at TIMESTAMP,
+    revoke_reason TEXT
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_token_families_user_id ON refresh_token_families(user_id);
+
+-- 10. Refresh tokens (setiap hasil rotasi)
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    family_id UUID REFERENCES refresh_token_families(id) ON DELETE CASCADE,
+    user_id UUID REFERENCES users(id),
+    parent_jti VARCHAR(64),
+    issued_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    rotated_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
@@ -0,0 +1,12 @@
+-- 11. Password reset tokens (hanya hash yang disimpan, sekali pakai)
+CREATE TABLE IF NOT EXISTS password_reset_tokens (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    requested_ip VARCHAR(64),
+    expires_at TIMESTAMP NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_reset_tokens_user_id ON password_reset_tokens(user_id);
@@ -0,0 +1,26 @@
+-- 12. Riwayat percobaan login (berhasil maupun gagal)
+CREATE TABLE IF NOT EXISTS login_attempts (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    identifier VARCHAR(100),
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    lockout_reason TEXT,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_login_attempts_ip_created_at ON login_attempts(ip_address, created_at);
+CREATE INDEX IF NOT EXISTS idx_login_attempts_user_id ON login_attempts(user_id);
+
+-- 13. Status lockout per akun
+CREATE TABLE IF NOT EXISTS account_lockouts (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    failed_attempts INTEGER NOT NULL DEFAULT 0,
+    lockout_count INTEGER NOT NULL DEFAULT 0,
+    locked_until TIMESTAMP,
+    lock_reason TEXT,
+    last_failed_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT NOW()
+);
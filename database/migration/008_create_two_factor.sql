@@ -0,0 +1,26 @@
+-- 14. Kebijakan 2FA per role (Admin wajib)
+ALTER TABLE roles ADD COLUMN IF NOT EXISTS require_two_factor BOOLEAN NOT NULL DEFAULT false;
+
+UPDATE roles SET require_two_factor = true WHERE name = 'Admin';
+
+-- 15. Secret TOTP per user (enabled=false selama enrollment belum dikonfirmasi)
+CREATE TABLE IF NOT EXISTS user_two_factor (
+    user_id UUID PRIMARY KEY REFERENCES users(id),
+    secret VARCHAR(64) NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT false,
+    enabled_at TIMESTAMP,
+    last_used_step BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 16. Recovery code sekali pakai (hanya hash yang disimpan)
+CREATE TABLE IF NOT EXISTS two_factor_recovery_codes (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_two_factor_recovery_codes_user_id ON two_factor_recovery_codes(user_id);
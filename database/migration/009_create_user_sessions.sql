@@ -0,0 +1,14 @@
+-- 17. Sesi login aktif (satu baris per refresh token family)
+CREATE TABLE IF NOT EXISTS user_sessions (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    family_id UUID UNIQUE REFERENCES refresh_token_families(id),
+    user_agent TEXT,
+    ip_address VARCHAR(64),
+    access_jti VARCHAR(64),
+    access_expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_seen_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_sessions_user_id ON user_sessions(user_id);
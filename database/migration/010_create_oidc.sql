@@ -0,0 +1,22 @@
+-- 18. State login OIDC (sekali pakai, berlaku beberapa menit)
+CREATE TABLE IF NOT EXISTS oidc_login_states (
+    state_hash VARCHAR(64) PRIMARY KEY,
+    code_verifier VARCHAR(128) NOT NULL,
+    nonce VARCHAR(128) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- 19. Identitas SSO yang terhubung ke user
+CREATE TABLE IF NOT EXISTS user_identities (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id),
+    issuer VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    email VARCHAR(100),
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_login_at TIMESTAMP,
+    UNIQUE (issuer, subject)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_identities_user_id ON user_identities(user_id);
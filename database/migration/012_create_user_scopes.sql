@@ -0,0 +1,12 @@
+-- 21. Scope akses tambahan per user (mis. Kaprodi: semua mahasiswa satu prodi / departemen)
+CREATE TABLE IF NOT EXISTS user_scopes (
+    id UUID PRIMARY KEY,
+    user_id UUID REFERENCES users(id) ON DELETE CASCADE,
+    scope_type VARCHAR(30) NOT NULL,
+    scope_value VARCHAR(100) NOT NULL,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (user_id, scope_type, scope_value)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_scopes_user_id ON user_scopes(user_id);
@@ -0,0 +1,32 @@
+-- 22. Sesi impersonation admin ("login as")
+CREATE TABLE IF NOT EXISTS impersonation_sessions (
+    id UUID PRIMARY KEY,
+    admin_id UUID REFERENCES users(id),
+    target_user_id UUID REFERENCES users(id),
+    reason TEXT NOT NULL,
+    token_jti VARCHAR(64) NOT NULL,
+    ip_address VARCHAR(64),
+    user_agent TEXT,
+    started_at TIMESTAMP DEFAULT NOW(),
+    expires_at TIMESTAMP NOT NULL,
+    ended_at TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_admin_id ON impersonation_sessions(admin_id);
+CREATE INDEX IF NOT EXISTS idx_impersonation_sessions_target_user_id ON impersonation_sessions(target_user_id);
+
+-- 23. Audit setiap request yang dibuat dengan token impersonation
+CREATE TABLE IF NOT EXISTS impersonation_audit_logs (
+    id UUID PRIMARY KEY,
+    session_id UUID REFERENCES impersonation_sessions(id) ON DELETE CASCADE,
+    admin_id UUID REFERENCES users(id),
+    target_user_id UUID REFERENCES users(id),
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    status_code INT NOT NULL,
+    blocked BOOLEAN DEFAULT false,
+    ip_address VARCHAR(64),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_impersonation_audit_logs_session_id ON impersonation_audit_logs(session_id);
@@ -0,0 +1,49 @@
+-- 24. Log transisi status prestasi (ditulis dalam transaksi yang sama dengan perubahan status)
+CREATE TABLE IF NOT EXISTS achievement_status_events (
+    id UUID PRIMARY KEY,
+    achievement_ref_id UUID REFERENCES achievement_references(id) ON DELETE CASCADE,
+    from_status achievement_status,
+    to_status achievement_status NOT NULL,
+    changed_by UUID REFERENCES users(id),
+    note TEXT,
+    request_id VARCHAR(64),
+    -- TRUE untuk baris hasil backfill di bawah (bukan transisi yang benar-benar tercatat)
+    backfilled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Database yang sudah menjalankan versi awal migration ini
+ALTER TABLE achievement_status_events ADD COLUMN IF NOT EXISTS backfilled BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE achievement_status_events
+SET changed_by = NULL, backfilled = TRUE
+WHERE note = 'backfilled' AND request_id IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_achievement_status_events_ref_id ON achievement_status_events(achievement_ref_id, created_at);
+
+-- Backfill dari kolom submitted_at / verified_at untuk data lama.
+-- Riwayat sebelum log ini ada tidak bisa dipulihkan lebih detail dari ini: siapa yang
+-- membuat / submit / menghapus tidak diketahui, jadi changed_by dibiarkan NULL
+-- (hanya verified_by yang memang tercatat).
+INSERT INTO achievement_status_events (id, achievement_ref_id, from_status, to_status, changed_by, note, backfilled, created_at)
+SELECT gen_random_uuid(), ar.id, NULL, 'draft', NULL, 'backfilled', TRUE, ar.created_at
+FROM achievement_references ar
+WHERE NOT EXISTS (SELECT 1 FROM achievement_status_events e WHERE e.achievement_ref_id = ar.id);
+
+INSERT INTO achievement_status_events (id, achievement_ref_id, from_status, to_status, changed_by, note, backfilled, created_at)
+SELECT gen_random_uuid(), ar.id, 'draft', 'submitted', NULL, 'backfilled', TRUE, ar.submitted_at
+FROM achievement_references ar
+WHERE ar.submitted_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM achievement_status_events e WHERE e.achievement_ref_id = ar.id AND e.to_status = 'submitted');
+
+INSERT INTO achievement_status_events (id, achievement_ref_id, from_status, to_status, changed_by, note, backfilled, created_at)
+SELECT gen_random_uuid(), ar.id, 'submitted', ar.status, ar.verified_by,
+       CASE WHEN ar.status = 'rejected' THEN ar.rejection_note END, TRUE, ar.verified_at
+FROM achievement_references ar
+WHERE ar.status IN ('verified', 'rejected') AND ar.verified_at IS NOT NULL
+  AND NOT EXISTS (SELECT 1 FROM achievement_status_events e WHERE e.achievement_ref_id = ar.id AND e.to_status = ar.status);
+
+INSERT INTO achievement_status_events (id, achievement_ref_id, from_status, to_status, changed_by, note, backfilled, created_at)
+SELECT gen_random_uuid(), ar.id, 'draft', 'deleted', NULL, 'backfilled', TRUE, ar.updated_at
+FROM achievement_references ar
+WHERE ar.status = 'deleted'
+  AND NOT EXISTS (SELECT 1 FROM achievement_status_events e WHERE e.achievement_ref_id = ar.id AND e.to_status = 'deleted');
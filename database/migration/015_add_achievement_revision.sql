@@ -0,0 +1,2 @@
+-- 25. Revisi prestasi: bertambah setiap kali prestasi yang ditolak diajukan ulang
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS revision INT NOT NULL DEFAULT 0;
@@ -0,0 +1,4 @@
+-- 26. Stage verifikasi bertingkat (advisor -> faculty -> final).
+-- NULL saat status submitted berarti stage pertama dari workflow yang berlaku.
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS workflow_stage VARCHAR(50);
+ALTER TABLE achievement_status_events ADD COLUMN IF NOT EXISTS stage VARCHAR(50);
@@ -0,0 +1,30 @@
+-- 27. Diskusi per prestasi antara mahasiswa, dosen wali dan admin
+CREATE TABLE IF NOT EXISTS achievement_comments (
+    id UUID PRIMARY KEY,
+    achievement_ref_id UUID REFERENCES achievement_references(id) ON DELETE CASCADE,
+    author_id UUID REFERENCES users(id),
+    body TEXT NOT NULL,
+    change_requested BOOLEAN DEFAULT false,
+    fields TEXT[] DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW(),
+    deleted_at TIMESTAMP,
+    deleted_by UUID REFERENCES users(id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_comments_ref_id ON achievement_comments(achievement_ref_id, created_at);
+
+-- Menulis komentar butuh permission sendiri, supaya API key yang hanya achievement:read
+-- tidak bisa membuat / mengubah / menghapus komentar
+INSERT INTO permissions (id, name, resource, action, description)
+VALUES (gen_random_uuid(), 'achievement:comment', 'achievement', 'comment', 'Komentar di diskusi prestasi')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT
+    r.id AS role_id,
+    p.id AS permission_id
+FROM roles r, permissions p
+WHERE r.name IN ('Admin', 'Mahasiswa', 'Dosen Wali')
+AND p.name = 'achievement:comment'
+ON CONFLICT DO NOTHING;
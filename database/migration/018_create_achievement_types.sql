@@ -0,0 +1,46 @@
+-- 28. Tipe prestasi yang dikelola admin beserta definisi custom field per tipe
+CREATE TABLE IF NOT EXISTS achievement_types (
+    id UUID PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name_id VARCHAR(100) NOT NULL,
+    name_en VARCHAR(100) NOT NULL,
+    description TEXT,
+    built_in BOOLEAN DEFAULT false,
+    retired_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    updated_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Field yang dihapus dari definisi hanya ditandai retired supaya data lama tetap valid
+CREATE TABLE IF NOT EXISTS achievement_type_fields (
+    id UUID PRIMARY KEY,
+    type_id UUID REFERENCES achievement_types(id) ON DELETE CASCADE,
+    key VARCHAR(50) NOT NULL,
+    label_id VARCHAR(100) NOT NULL,
+    label_en VARCHAR(100) NOT NULL,
+    field_type VARCHAR(20) NOT NULL CHECK (field_type IN ('text', 'number', 'date', 'enum', 'url')),
+    required BOOLEAN DEFAULT false,
+    options TEXT[] DEFAULT '{}',
+    sort_order INT DEFAULT 0,
+    retired BOOLEAN DEFAULT false,
+    UNIQUE (type_id, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_type_fields_type_id ON achievement_type_fields(type_id, sort_order);
+
+INSERT INTO achievement_types (id, code, name_id, name_en, description, built_in)
+VALUES
+    (gen_random_uuid(), 'academic', 'Akademik', 'Academic', 'Prestasi akademik', true),
+    (gen_random_uuid(), 'competition', 'Kompetisi', 'Competition', 'Lomba / kompetisi', true),
+    (gen_random_uuid(), 'organization', 'Organisasi', 'Organization', 'Kepengurusan organisasi', true),
+    (gen_random_uuid(), 'publication', 'Publikasi', 'Publication', 'Publikasi ilmiah', true),
+    (gen_random_uuid(), 'certification', 'Sertifikasi', 'Certification', 'Sertifikasi profesi / kompetensi', true),
+    (gen_random_uuid(), 'other', 'Lainnya', 'Other', 'Prestasi lainnya', true)
+ON CONFLICT (code) DO NOTHING;
+
+-- Indeksasi publikasi, dipakai aturan poin "Indexed in Scopus / WoS" dan "Indexed in SINTA 1-2"
+INSERT INTO achievement_type_fields (id, type_id, key, label_id, label_en, field_type, required, options, sort_order)
+SELECT gen_random_uuid(), id, 'indexing', 'Indeksasi', 'Indexing', 'enum', false, ARRAY['scopus', 'wos', 'sinta1', 'sinta2'], 0
+FROM achievement_types
+WHERE code = 'publication'
+ON CONFLICT (type_id, key) DO NOTHING;
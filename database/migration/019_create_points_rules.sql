@@ -0,0 +1,15 @@
+-- 29. Aturan perhitungan poin. Setiap perubahan disimpan sebagai versi baru,
+-- versi terbesar adalah yang aktif. Tanpa baris sama sekali dipakai aturan bawaan (versi 0).
+CREATE TABLE IF NOT EXISTS points_rule_sets (
+    version INT PRIMARY KEY,
+    rules JSONB NOT NULL,
+    note TEXT,
+    created_by UUID REFERENCES users(id),
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+-- Poin final dihitung saat verifikasi, beserta versi aturan yang dipakai
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS points INT DEFAULT 0;
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS points_rule_version INT;
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS points_breakdown JSONB;
+ALTER TABLE achievement_references ADD COLUMN IF NOT EXISTS points_calculated_at TIMESTAMP;
@@ -0,0 +1,26 @@
+-- 30. Anggota tim prestasi. Pemilik (achievement_references.student_id) juga tercatat sebagai
+-- anggota; verifikasinya mengikuti workflow prestasi sehingga verification_status-nya NULL.
+-- Anggota lain diverifikasi masing-masing oleh dosen walinya sendiri.
+CREATE TABLE IF NOT EXISTS achievement_members (
+    id UUID PRIMARY KEY,
+    achievement_ref_id UUID REFERENCES achievement_references(id) ON DELETE CASCADE,
+    student_id UUID REFERENCES students(id) ON DELETE CASCADE,
+    role VARCHAR(10) NOT NULL DEFAULT 'member' CHECK (role IN ('leader', 'member')),
+    status VARCHAR(10) NOT NULL DEFAULT 'invited' CHECK (status IN ('invited', 'accepted', 'declined')),
+    verification_status VARCHAR(10) CHECK (verification_status IN ('pending', 'verified', 'rejected')),
+    verified_by UUID REFERENCES users(id),
+    verified_at TIMESTAMP,
+    rejection_note TEXT,
+    invited_by UUID REFERENCES users(id),
+    responded_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    UNIQUE (achievement_ref_id, student_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_achievement_members_student_id ON achievement_members(student_id, status);
+
+-- Pemilik prestasi yang sudah ada menjadi leader
+INSERT INTO achievement_members (id, achievement_ref_id, student_id, role, status, responded_at, created_at)
+SELECT gen_random_uuid(), ar.id, ar.student_id, 'leader', 'accepted', ar.created_at, ar.created_at
+FROM achievement_references ar
+ON CONFLICT (achievement_ref_id, student_id) DO NOTHING;
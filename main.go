@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 
@@ -8,10 +9,14 @@ import (
 	"github.com/gofiber/fiber/v2/middleware/cors"
 	"github.com/gofiber/fiber/v2/middleware/logger"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 
+	"UAS/app/repository"
+	"UAS/app/service"
 	"UAS/config"
 	"UAS/database"
 	"UAS/route"
+	"UAS/utils"
 	_"UAS/docs"
 
 )
@@ -35,12 +40,17 @@ import (
 // @securityDefinitions.apikey BearerAuth
 // @in header
 // @name Authorization
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
 func main() {
 	if err := config.LoadConfig(); err != nil {
 		log.Println("Warning: .env file not found")
 	}
 
 	migrateFlag := flag.Bool("migrate", false, "Run database migrations")
+	recalculatePointsFlag := flag.Bool("recalculate-points", false, "Recalculate points of verified achievements with the active points rules")
 	flag.Parse()
 
 	database.ConnectDB()
@@ -53,8 +63,30 @@ func main() {
 		return
 	}
 
+	if *recalculatePointsFlag {
+		log.Println("Recalculating achievement points...")
+		pointsService := service.NewPointsService(
+			repository.NewPointsRuleRepository(database.PgDB),
+			repository.NewAchievementReferenceRepository(database.PgDB),
+			repository.NewAchievementRepository(database.MongoDB.Collection("achievements")),
+		)
+		summary, err := pointsService.Recalculate(context.Background(), false)
+		if err != nil {
+			log.Fatal("Failed to recalculate points: ", err)
+		}
+		log.Printf("Points recalculated with rules version %d: %d checked, %d updated, %d changed, %d failed",
+			summary.RuleVersion, summary.Checked, summary.Updated, len(summary.Changes), summary.Failed)
+		return
+	}
+
+	if err := utils.LoadJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys: ", err)
+	}
+
 	app := fiber.New(config.FiberConfig())
 	app.Use(recover.New())
+	// X-Request-ID dipakai ulang jika dikirim client, dicatat di log & achievement_status_events
+	app.Use(requestid.New())
 	app.Use(cors.New())
 	app.Use(logger.New(config.LoggerConfig()))
 
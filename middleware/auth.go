@@ -3,6 +3,7 @@ package middleware
 import (
 	"strings"
 
+	"UAS/app/models"
 	"UAS/app/repository"
 	"UAS/utils"
 
@@ -10,8 +11,37 @@ import (
 	"github.com/google/uuid"
 )
 
+// APIKeyAuthenticator - validasi API key dan kembalikan permission efektifnya (diimplementasikan oleh APIKeyService)
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(rawKey string) (*models.APIKey, []string, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+// PermissionResolver - permission diambil dari role user saat ini, bukan dari isi JWT
+// (diimplementasikan oleh PermissionService)
+type PermissionResolver interface {
+	GetPermissions(roleID uuid.UUID) ([]string, error)
+}
+
+var permissionResolver PermissionResolver
+
+func SetPermissionResolver(resolver PermissionResolver) {
+	permissionResolver = resolver
+}
+
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// RequireAuth - terima "Authorization: Bearer <jwt>", atau API key lewat
+// "Authorization: ApiKey <key>" / header "X-API-Key"
 func RequireAuth(userRepo repository.UserRepository) fiber.Handler {
 	return func(c *fiber.Ctx) error {
+		if rawKey := apiKeyFromRequest(c); rawKey != "" {
+			return authenticateAPIKey(c, userRepo, rawKey)
+		}
+
 		authHeader := c.Get("Authorization")
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
@@ -52,15 +82,86 @@ func RequireAuth(userRepo repository.UserRepository) fiber.Handler {
 			})
 		}
 
+		permissions, err := resolvePermissions(user.RoleID)
+		if err != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"message": "failed to resolve permissions",
+				"error":   err.Error(),
+			})
+		}
+
 		c.Locals("user_id", user.ID)
 		c.Locals("user", user)
 		c.Locals("role_id", user.RoleID)
-		c.Locals("permissions", claims.Permissions)
+		c.Locals("permissions", permissions)
+		c.Locals("claims", claims)
+
+		if claims.Act != nil {
+			return impersonate(c, userRepo, claims, user)
+		}
 
 		return c.Next()
 	}
 }
 
+func resolvePermissions(roleID uuid.UUID) ([]string, error) {
+	if permissionResolver == nil {
+		return []string{}, nil
+	}
+	return permissionResolver.GetPermissions(roleID)
+}
+
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	if key := c.Get("X-API-Key"); key != "" {
+		return key
+	}
+
+	parts := strings.SplitN(c.Get("Authorization"), " ", 2)
+	if len(parts) == 2 && parts[0] == "ApiKey" {
+		return strings.TrimSpace(parts[1])
+	}
+	return ""
+}
+
+func authenticateAPIKey(c *fiber.Ctx, userRepo repository.UserRepository, rawKey string) error {
+	if apiKeyAuthenticator == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "api key authentication is not available",
+		})
+	}
+
+	apiKey, permissions, err := apiKeyAuthenticator.AuthenticateAPIKey(rawKey)
+	if err != nil || apiKey == nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "invalid or expired api key",
+		})
+	}
+
+	user, err := userRepo.GetByID(apiKey.UserID)
+	if err != nil || user == nil || !user.IsActive {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "user not found or inactive",
+		})
+	}
+
+	c.Locals("user_id", user.ID)
+	c.Locals("user", user)
+	c.Locals("role_id", user.RoleID)
+	c.Locals("permissions", permissions)
+	c.Locals("api_key", apiKey)
+
+	return c.Next()
+}
+
+func hasPermission(permissions []string, permission string) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
 func RequirePermission(permission string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		permissions, ok := c.Locals("permissions").([]string)
@@ -111,6 +212,17 @@ func AdminOnly(roleRepo repository.RoleRepository) fiber.Handler {
 			})
 		}
 
+		// API key milik admin hanya boleh akses endpoint admin jika diberi user:manage
+		if _, isAPIKey := c.Locals("api_key").(*models.APIKey); isAPIKey {
+			permissions, _ := c.Locals("permissions").([]string)
+			if !hasPermission(permissions, "user:manage") {
+				return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+					"message": "api key lacks required permission",
+					"required": "user:manage",
+				})
+			}
+		}
+
 		return c.Next()
 	}
 }
@@ -0,0 +1,94 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+
+	"UAS/app/models"
+	"UAS/app/repository"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// ImpersonationAuditor - simpan jejak setiap request yang dibuat dengan token impersonation
+// (diimplementasikan oleh ImpersonationService)
+type ImpersonationAuditor interface {
+	RecordImpersonatedRequest(entry *models.ImpersonationAuditLog) error
+}
+
+var impersonationAuditor ImpersonationAuditor
+
+func SetImpersonationAuditor(auditor ImpersonationAuditor) {
+	impersonationAuditor = auditor
+}
+
+// impersonate - dipanggil RequireAuth untuk token dengan klaim "act". Admin aslinya harus
+// masih aktif, dan request dicatat sebagai admin yang bertindak atas nama user.
+func impersonate(c *fiber.Ctx, userRepo repository.UserRepository, claims *models.JWTClaims, user *models.User) error {
+	adminID, err := uuid.Parse(claims.Act.Subject)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "invalid impersonation token",
+		})
+	}
+	sessionID, err := uuid.Parse(claims.Act.ImpersonationID)
+	if err != nil {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "invalid impersonation token",
+		})
+	}
+
+	admin, err := userRepo.GetByID(adminID)
+	if err != nil || admin == nil || !admin.IsActive {
+		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+			"message": "impersonating admin not found or inactive",
+		})
+	}
+
+	c.Locals("impersonator_id", adminID)
+	c.Locals("impersonation_id", sessionID)
+
+	err = c.Next()
+
+	status := c.Response().StatusCode()
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		status = fiberErr.Code
+	} else if err != nil {
+		status = fiber.StatusInternalServerError
+	}
+	blocked, _ := c.Locals("impersonation_blocked").(bool)
+
+	log.Printf("IMPERSONATION: admin %s acting as user %s: %s %s -> %d", adminID, user.ID, c.Method(), c.Path(), status)
+
+	if impersonationAuditor != nil {
+		entry := &models.ImpersonationAuditLog{
+			SessionID:    sessionID,
+			AdminID:      adminID,
+			TargetUserID: user.ID,
+			Method:       c.Method(),
+			Path:         c.Path(),
+			StatusCode:   status,
+			Blocked:      blocked,
+			IPAddress:    c.IP(),
+		}
+		if auditErr := impersonationAuditor.RecordImpersonatedRequest(entry); auditErr != nil {
+			log.Printf("ERROR: failed to record impersonation audit for session %s: %v", sessionID, auditErr)
+		}
+	}
+
+	return err
+}
+
+// DenyImpersonation - tolak aksi destruktif (ganti password, 2FA, API key, revoke sesi,
+// hapus prestasi, terima/keluar dari tim) jika request dibuat dengan token impersonation
+func DenyImpersonation(c *fiber.Ctx) error {
+	if _, ok := c.Locals("impersonator_id").(uuid.UUID); ok {
+		c.Locals("impersonation_blocked", true)
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"message": "action not allowed while impersonating a user",
+		})
+	}
+	return c.Next()
+}
@@ -0,0 +1,43 @@
+package middleware
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+func TestDenyImpersonation(t *testing.T) {
+	tests := []struct {
+		name          string
+		impersonating bool
+		wantStatus    int
+	}{
+		{"own session", false, fiber.StatusNoContent},
+		{"impersonation token", true, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.impersonating {
+					c.Locals("impersonator_id", uuid.New())
+				}
+				return c.Next()
+			})
+			app.Delete("/achievements/:id", DenyImpersonation, func(c *fiber.Ctx) error {
+				return c.SendStatus(fiber.StatusNoContent)
+			})
+
+			resp, err := app.Test(httptest.NewRequest("DELETE", "/achievements/"+uuid.NewString(), nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -1,8 +1,11 @@
 package route
 
 import (
+    "UAS/app/authz"
     "UAS/app/repository"
+    "UAS/app/schema"
     "UAS/app/service"
+    "UAS/app/workflow"
     "UAS/middleware"
     "UAS/database"
 
@@ -15,33 +18,73 @@ func SetupAchievementRoutes(router fiber.Router,
     roleRepo repository.RoleRepository,
     studentRepo repository.StudentRepository,
     lecturerRepo repository.LecturerRepository,
-    mongoDB *mongo.Database) {
+    mongoDB *mongo.Database,
+    authorizer *authz.Authorizer,
+    workflows workflow.Workflows,
+    achievementTypeRepo repository.AchievementTypeRepository,
+    schemas schema.Registry,
+    pointsService *service.PointsService,
+    memberRepo repository.AchievementMemberRepository) {
 
     // Inisialisasi repositories
     achievementRefRepo := repository.NewAchievementReferenceRepository(database.PgDB)
     achievementRepo := repository.NewAchievementRepository(mongoDB.Collection("achievements"))
+    achievementVersionRepo := repository.NewAchievementVersionRepository(mongoDB.Collection("achievement_versions"))
+    commentRepo := repository.NewAchievementCommentRepository(database.PgDB)
     
     achievementService := service.NewAchievementService(
         achievementRepo,
         achievementRefRepo,
+        achievementVersionRepo,
         studentRepo,
         lecturerRepo,
         userRepo,
         roleRepo,
+        authorizer,
+        workflows,
+        achievementTypeRepo,
+        schemas,
+        pointsService,
     )
 
+    commentService := service.NewAchievementCommentService(commentRepo, achievementRefRepo, authorizer)
+    memberService := service.NewAchievementMemberService(memberRepo, achievementRefRepo, studentRepo, authorizer)
+
     achievementRoutes := router.Group("/achievements")
     achievementRoutes.Use(middleware.RequireAuth(userRepo))
 
-    achievementRoutes.Get("/", achievementService.GetAllAchievements,middleware.RequirePermission("achievement:read"))
-    achievementRoutes.Get("/:id", achievementService.GetAchievementByID, middleware.RequirePermission("achievement:read"))
-    achievementRoutes.Post("/", achievementService.CreateAchievement, middleware.RequirePermission("achievement:create"))
-    achievementRoutes.Put("/:id", achievementService.UpdateAchievement, middleware.RequirePermission("achievement:update"))
-    achievementRoutes.Delete("/:id", achievementService.DeleteAchievement, middleware.RequirePermission("achievement:delete"))
-    achievementRoutes.Post("/:id/submit", achievementService.SubmitAchievement, middleware.RequirePermission("achievement:update"))
+    achievementRoutes.Get("/", middleware.RequirePermission("achievement:read"), achievementService.GetAllAchievements)
+    // Didaftarkan sebelum /:id supaya "invitations" tidak dianggap ID
+    achievementRoutes.Get("/invitations", middleware.RequirePermission("achievement:read"), memberService.GetInvitations)
+    achievementRoutes.Get("/:id", middleware.RequirePermission("achievement:read"), achievementService.GetAchievementByID)
+    achievementRoutes.Post("/", middleware.RequirePermission("achievement:create"), achievementService.CreateAchievement)
+    // Didaftarkan sebelum /:id supaya "bulk" tidak dianggap ID
+    achievementRoutes.Post("/bulk/verify", middleware.RequirePermission("achievement:verify"), achievementService.BulkVerifyAchievements)
+    achievementRoutes.Post("/bulk/reject", middleware.RequirePermission("achievement:verify"), achievementService.BulkRejectAchievements)
+    achievementRoutes.Put("/:id", middleware.RequirePermission("achievement:update"), achievementService.UpdateAchievement)
+    achievementRoutes.Delete("/:id", middleware.RequirePermission("achievement:delete"), middleware.DenyImpersonation, achievementService.DeleteAchievement)
+    achievementRoutes.Post("/:id/submit", middleware.RequirePermission("achievement:update"), achievementService.SubmitAchievement)
+    achievementRoutes.Post("/:id/resubmit", middleware.RequirePermission("achievement:update"), achievementService.ResubmitAchievement)
     achievementRoutes.Post("/:id/verify", middleware.RequirePermission("achievement:verify"), achievementService.VerifyAchievement)
     achievementRoutes.Post("/:id/reject", middleware.RequirePermission("achievement:verify"), achievementService.RejectAchievement)
-    achievementRoutes.Get("/:id/history", achievementService.GetAchievementHistory, middleware.RequirePermission("achievement:read"))
-    achievementRoutes.Post("/:id/attachments", achievementService.UploadAttachment, middleware.RequirePermission("achievement:update"))
+    achievementRoutes.Get("/:id/history", middleware.RequirePermission("achievement:read"), achievementService.GetAchievementHistory)
+    achievementRoutes.Get("/:id/versions", middleware.RequirePermission("achievement:read"), achievementService.GetAchievementVersions)
+    achievementRoutes.Get("/:id/versions/:a/diff/:b", middleware.RequirePermission("achievement:read"), achievementService.DiffAchievementVersions)
+    achievementRoutes.Post("/:id/attachments", middleware.RequirePermission("achievement:update"), achievementService.UploadAttachment)
+
+    // Thread diskusi; siapa yang boleh menulis diatur policy ActionAchievementComment
+    achievementRoutes.Get("/:id/comments", middleware.RequirePermission("achievement:read"), commentService.GetComments)
+    achievementRoutes.Post("/:id/comments", middleware.RequirePermission("achievement:comment"), commentService.CreateComment)
+    achievementRoutes.Put("/:id/comments/:commentId", middleware.RequirePermission("achievement:comment"), commentService.UpdateComment)
+    achievementRoutes.Delete("/:id/comments/:commentId", middleware.RequirePermission("achievement:comment"), commentService.DeleteComment)
+
+    // Prestasi tim; verifikasi anggota oleh dosen wali masing-masing anggota
+    achievementRoutes.Get("/:id/members", middleware.RequirePermission("achievement:read"), memberService.GetMembers)
+    achievementRoutes.Post("/:id/members", middleware.RequirePermission("achievement:update"), memberService.InviteMember)
+    achievementRoutes.Post("/:id/members/respond", middleware.RequirePermission("achievement:update"), middleware.DenyImpersonation, memberService.RespondInvitation)
+    achievementRoutes.Put("/:id/members/:studentId", middleware.RequirePermission("achievement:update"), memberService.UpdateMember)
+    achievementRoutes.Delete("/:id/members/:studentId", middleware.RequirePermission("achievement:update"), middleware.DenyImpersonation, memberService.RemoveMember)
+    achievementRoutes.Post("/:id/members/:studentId/verify", middleware.RequirePermission("achievement:verify"), memberService.VerifyMember)
+    achievementRoutes.Post("/:id/members/:studentId/reject", middleware.RequirePermission("achievement:verify"), memberService.RejectMember)
 
 }
\ No newline at end of file
@@ -0,0 +1,25 @@
+package route
+
+import (
+	"UAS/app/repository"
+	"UAS/app/service"
+	"UAS/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupAchievementTypeRoutes(
+	router fiber.Router,
+	achievementTypeService *service.AchievementTypeService,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+) {
+	typeRoutes := router.Group("/achievement-types", middleware.RequireAuth(userRepo))
+
+	typeRoutes.Get("/", middleware.RequirePermission("achievement:read"), achievementTypeService.GetAll)
+	typeRoutes.Get("/:code", middleware.RequirePermission("achievement:read"), achievementTypeService.GetByCode)
+	typeRoutes.Post("/", middleware.AdminOnly(roleRepo), achievementTypeService.Create)
+	typeRoutes.Put("/:code", middleware.AdminOnly(roleRepo), achievementTypeService.Update)
+	typeRoutes.Delete("/:code", middleware.AdminOnly(roleRepo), achievementTypeService.Retire)
+	typeRoutes.Post("/:code/restore", middleware.AdminOnly(roleRepo), achievementTypeService.Restore)
+}
@@ -0,0 +1,21 @@
+package route
+
+import (
+	"UAS/app/repository"
+	"UAS/app/service"
+	"UAS/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupAPIKeyRoutes(
+	router fiber.Router,
+	apiKeyService *service.APIKeyService,
+	userRepo repository.UserRepository,
+) {
+	apiKeyRoutes := router.Group("/api-keys", middleware.RequireAuth(userRepo))
+
+	apiKeyRoutes.Get("/", apiKeyService.List)
+	apiKeyRoutes.Post("/", middleware.DenyImpersonation, apiKeyService.Create)
+	apiKeyRoutes.Delete("/:id", middleware.DenyImpersonation, apiKeyService.Revoke)
+}
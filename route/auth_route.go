@@ -1,9 +1,11 @@
 package route
 
 import (
+	"UAS/config"
 	"UAS/middleware"
 	"UAS/app/repository"
 	"UAS/app/service"
+	"UAS/utils"
 	
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,15 +16,43 @@ func setupAuthRoutes(
 	roleRepo repository.RoleRepository,
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
+	tokenRevocationRepo repository.TokenRevocationRepository,
+	refreshTokenRepo repository.RefreshTokenRepository,
+	passwordResetRepo repository.PasswordResetRepository,
+	loginAttemptRepo repository.LoginAttemptRepository,
+	twoFactorRepo repository.TwoFactorRepository,
+	sessionRepo repository.SessionRepository,
+	oidcRepo repository.OIDCRepository,
+	mailer utils.Mailer,
+	sessionService *service.SessionService,
+	impersonationService *service.ImpersonationService,
 ) {
-	authService := service.NewAuthService(userRepo, roleRepo, studentRepo, lecturerRepo)
+	authService := service.NewAuthService(userRepo, roleRepo, studentRepo, lecturerRepo, tokenRevocationRepo, refreshTokenRepo, passwordResetRepo, loginAttemptRepo, twoFactorRepo, sessionRepo, mailer)
+	twoFactorService := service.NewTwoFactorService(authService, userRepo, roleRepo, twoFactorRepo)
+	oidcService := service.NewOIDCService(authService, userRepo, roleRepo, studentRepo, lecturerRepo, oidcRepo, config.OIDCConfig())
 	
 	authRoutes := router.Group("/auth")
 	
 	authRoutes.Post("/login", authService.Login)
 	authRoutes.Post("/refresh", authService.RefreshToken)
-	authRoutes.Post("/logout", authService.Logout)
+	authRoutes.Post("/forgot-password", authService.ForgotPassword)
+	authRoutes.Post("/reset-password", authService.ResetPassword)
+	authRoutes.Post("/logout", middleware.RequireAuth(userRepo), authService.Logout)
 	
 	authRoutes.Get("/profile", middleware.RequireAuth(userRepo),authService.Profile,)
-	authRoutes.Post("/change-password",middleware.RequireAuth(userRepo),authService.ChangePassword,)
-}
\ No newline at end of file
+	authRoutes.Post("/change-password",middleware.RequireAuth(userRepo),middleware.DenyImpersonation,authService.ChangePassword,)
+	authRoutes.Get("/sessions", middleware.RequireAuth(userRepo), sessionService.GetMySessions)
+	authRoutes.Delete("/sessions/:id", middleware.RequireAuth(userRepo), middleware.DenyImpersonation, sessionService.RevokeMySession)
+	authRoutes.Post("/impersonation/end", middleware.RequireAuth(userRepo), impersonationService.End)
+
+	authRoutes.Get("/oidc/login", oidcService.Login)
+	authRoutes.Get("/oidc/callback", oidcService.Callback)
+
+	twoFactorRoutes := authRoutes.Group("/2fa")
+	twoFactorRoutes.Post("/login", twoFactorService.Login)
+	twoFactorRoutes.Post("/setup", twoFactorService.SetupEnroll)
+	twoFactorRoutes.Post("/setup/verify", twoFactorService.SetupVerify)
+	twoFactorRoutes.Post("/enroll", middleware.RequireAuth(userRepo), middleware.DenyImpersonation, twoFactorService.Enroll)
+	twoFactorRoutes.Post("/verify", middleware.RequireAuth(userRepo), middleware.DenyImpersonation, twoFactorService.Verify)
+	twoFactorRoutes.Post("/disable", middleware.RequireAuth(userRepo), middleware.DenyImpersonation, twoFactorService.Disable)
+}
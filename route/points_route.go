@@ -0,0 +1,24 @@
+package route
+
+import (
+	"UAS/app/repository"
+	"UAS/app/service"
+	"UAS/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupPointsRoutes(
+	router fiber.Router,
+	pointsService *service.PointsService,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+) {
+	pointsRoutes := router.Group("/points-rules", middleware.RequireAuth(userRepo))
+
+	pointsRoutes.Get("/", middleware.RequirePermission("achievement:read"), pointsService.GetRules)
+	pointsRoutes.Put("/", middleware.AdminOnly(roleRepo), pointsService.UpdateRules)
+	pointsRoutes.Get("/versions", middleware.AdminOnly(roleRepo), pointsService.GetVersions)
+	pointsRoutes.Get("/versions/:version", middleware.AdminOnly(roleRepo), pointsService.GetVersion)
+	pointsRoutes.Post("/recalculate", middleware.AdminOnly(roleRepo), pointsService.RecalculatePoints)
+}
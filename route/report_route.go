@@ -1,6 +1,7 @@
 package route
 
 import (
+	"UAS/app/authz"
 	"UAS/app/repository"
 	"UAS/app/service"
 	"UAS/middleware"
@@ -15,6 +16,7 @@ func SetupReportRoutes(
 	lecturerRepo repository.LecturerRepository,
 	roleRepo repository.RoleRepository,
 	reportRepo repository.ReportRepository,
+	authorizer *authz.Authorizer,
 ) {
 	reportService := service.NewReportService(
 		reportRepo,
@@ -22,6 +24,7 @@ func SetupReportRoutes(
 		studentRepo,
 		lecturerRepo,
 		roleRepo,
+		authorizer,
 	)
 
 	router.Get("/reports/statistics", middleware.RequireAuth(userRepo), reportService.GetStatistics)
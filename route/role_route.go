@@ -0,0 +1,31 @@
+package route
+
+import (
+	"UAS/app/repository"
+	"UAS/app/service"
+	"UAS/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func setupRoleRoutes(
+	router fiber.Router,
+	roleService *service.RoleService,
+	userRepo repository.UserRepository,
+	roleRepo repository.RoleRepository,
+) {
+	roleRoutes := router.Group("/roles", middleware.RequireAuth(userRepo), middleware.AdminOnly(roleRepo))
+
+	roleRoutes.Get("/", roleService.GetAll)
+	roleRoutes.Post("/", roleService.Create)
+	roleRoutes.Get("/:id", roleService.GetByID)
+	roleRoutes.Put("/:id", roleService.Update)
+	roleRoutes.Delete("/:id", roleService.Delete)
+	roleRoutes.Post("/:id/permissions", roleService.AssignPermission)
+	roleRoutes.Delete("/:id/permissions/:permissionId", roleService.RemovePermission)
+
+	permissionRoutes := router.Group("/permissions", middleware.RequireAuth(userRepo), middleware.AdminOnly(roleRepo))
+
+	permissionRoutes.Get("/", roleService.GetPermissions)
+	permissionRoutes.Post("/", roleService.CreatePermission)
+}
@@ -1,9 +1,18 @@
 package route
 
 import (
+	"log"
+	"time"
+
+	"UAS/config"
 	"UAS/database"
+	"UAS/app/authz"
 	"UAS/app/repository"
+	"UAS/app/schema"
 	"UAS/app/service"
+	"UAS/app/workflow"
+	"UAS/middleware"
+	"UAS/utils"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
@@ -17,13 +26,69 @@ func SetupRoutes(app *fiber.App) {
 	studentRepo := repository.NewStudentRepository(db)
 	lecturerRepo := repository.NewLecturerRepository(db)
 	reportRepo := repository.NewReportRepository()
+	tokenRevocationRepo := repository.NewTokenRevocationRepository(db)
+	refreshTokenRepo := repository.NewRefreshTokenRepository(db)
+	passwordResetRepo := repository.NewPasswordResetRepository(db)
+	loginAttemptRepo := repository.NewLoginAttemptRepository(db)
+	twoFactorRepo := repository.NewTwoFactorRepository(db)
+	sessionRepo := repository.NewSessionRepository(db)
+	oidcRepo := repository.NewOIDCRepository(db)
+	apiKeyRepo := repository.NewAPIKeyRepository(db)
+	permissionRepo := repository.NewPermissionRepository(db)
+	userScopeRepo := repository.NewUserScopeRepository(db)
+	impersonationRepo := repository.NewImpersonationRepository(db)
+	achievementTypeRepo := repository.NewAchievementTypeRepository(db)
+	pointsRuleRepo := repository.NewPointsRuleRepository(db)
+	achievementMemberRepo := repository.NewAchievementMemberRepository(db)
+
+	mailer, err := utils.NewMailer(config.MailConfig())
+	if err != nil {
+		log.Fatal("Failed to configure mailer: ", err)
+	}
+
+	utils.SetRevocationChecker(tokenRevocationRepo)
+	go purgeExpiredRevocations(tokenRevocationRepo)
+
+	userService := service.NewUserService(userRepo, roleRepo, studentRepo, lecturerRepo, tokenRevocationRepo, loginAttemptRepo, userScopeRepo)
 
-	userService := service.NewUserService(userRepo, roleRepo, studentRepo, lecturerRepo)
+	permissionService := service.NewPermissionService(roleRepo)
+	sessionService := service.NewSessionService(sessionRepo, refreshTokenRepo, tokenRevocationRepo, userRepo)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo, userRepo, roleRepo, permissionService)
+	roleService := service.NewRoleService(roleRepo, permissionRepo, permissionService)
+	impersonationService := service.NewImpersonationService(impersonationRepo, userRepo, roleRepo, tokenRevocationRepo)
+
+	// Policy akses data akademik (achievement, mahasiswa, dosen, report)
+	authorizer := authz.NewAuthorizer(roleRepo, studentRepo, lecturerRepo, userScopeRepo, achievementMemberRepo)
+
+	// Workflow verifikasi bertingkat per tipe prestasi
+	workflows, err := workflow.Load(config.AchievementWorkflowFile(), config.FacultyReviewMinPoints())
+	if err != nil {
+		log.Fatal("Failed to load achievement workflow: ", err)
+	}
+
+	// Schema details per tipe prestasi; tipe & custom field dikelola admin di database
+	schemas := schema.DefaultRegistry()
+	achievementTypeService := service.NewAchievementTypeService(achievementTypeRepo, schemas)
+
+	// Perhitungan poin berbasis aturan yang dikelola admin
+	pointsService := service.NewPointsService(
+		pointsRuleRepo,
+		repository.NewAchievementReferenceRepository(db),
+		repository.NewAchievementRepository(database.MongoDB.Collection("achievements")),
+	)
+
+	middleware.SetPermissionResolver(permissionService)
+	middleware.SetAPIKeyAuthenticator(apiKeyService)
+	middleware.SetImpersonationAuditor(impersonationService)
 
 	examAPI := app.Group("/uas/api")
 
-	setupAuthRoutes(examAPI, userRepo, roleRepo, studentRepo, lecturerRepo)
-	setupUserRoutes(examAPI, userService, userRepo, roleRepo)
+	setupAuthRoutes(examAPI, userRepo, roleRepo, studentRepo, lecturerRepo, tokenRevocationRepo, refreshTokenRepo, passwordResetRepo, loginAttemptRepo, twoFactorRepo, sessionRepo, oidcRepo, mailer, sessionService, impersonationService)
+	setupUserRoutes(examAPI, userService, sessionService, impersonationService, userRepo, roleRepo)
+	setupAPIKeyRoutes(examAPI, apiKeyService, userRepo)
+	setupRoleRoutes(examAPI, roleService, userRepo, roleRepo)
+	setupAchievementTypeRoutes(examAPI, achievementTypeService, userRepo, roleRepo)
+	setupPointsRoutes(examAPI, pointsService, userRepo, roleRepo)
 
 	SetupReportRoutes(
 		examAPI,
@@ -32,7 +97,10 @@ func SetupRoutes(app *fiber.App) {
 		lecturerRepo,
 		roleRepo,
 		reportRepo,
+		authorizer,
 	)
+	SetupAchievementRoutes(examAPI, userRepo, roleRepo, studentRepo, lecturerRepo, database.MongoDB, authorizer, workflows, achievementTypeRepo, schemas, pointsService, achievementMemberRepo)
+	SetupStudentLecturerRoutes(examAPI, userRepo, roleRepo, studentRepo, lecturerRepo, database.MongoDB, authorizer)
 
 	examAPI.Get("/health", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -43,4 +111,22 @@ func SetupRoutes(app *fiber.App) {
 	})
 
 	examAPI.Get("/swagger/*", swagger.HandlerDefault)
+
+	// Public key untuk verifikasi token oleh service lain
+	app.Get("/.well-known/jwks.json", func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+		return c.JSON(utils.JWKS())
+	})
+}
+
+// purgeExpiredRevocations - hapus token revoked yang sudah expired secara berkala
+func purgeExpiredRevocations(repo repository.TokenRevocationRepository) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		if _, err := repo.DeleteExpired(); err != nil {
+			log.Println("Warning: failed to purge expired revoked tokens:", err)
+		}
+	}
 }
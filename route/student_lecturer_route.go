@@ -1,6 +1,7 @@
 package route
 
 import (
+	"UAS/app/authz"
 	"UAS/app/repository"
 	"UAS/app/service"
 	"UAS/database"
@@ -17,6 +18,7 @@ func SetupStudentLecturerRoutes(
 	studentRepo repository.StudentRepository,
 	lecturerRepo repository.LecturerRepository,
 	mongoDB *mongo.Database,
+	authorizer *authz.Authorizer,
 ) {
 
 	achievementRefRepo := repository.NewAchievementReferenceRepository(database.PgDB)
@@ -31,6 +33,7 @@ func SetupStudentLecturerRoutes(
 		roleRepo,
 		achievementRepo,
 		achievementRefRepo,
+		authorizer,
 	)
 
 	students := router.Group("/students")
@@ -11,6 +11,8 @@ import (
 func setupUserRoutes(
 	router fiber.Router, 
 	userService *service.UserService,
+	sessionService *service.SessionService,
+	impersonationService *service.ImpersonationService,
 	userRepo repository.UserRepository,
 	roleRepo repository.RoleRepository,
 ) {
@@ -18,6 +20,9 @@ func setupUserRoutes(
 	
 	user := userRoutes.Group("", middleware.AdminOnly(roleRepo))
 	userRoutes.Get("/", userService.GetAll)
+	user.Get("/locked", userService.GetLockedAccounts)
+	user.Get("/impersonations", impersonationService.List)
+	user.Get("/impersonations/:id", impersonationService.GetByID)
 	userRoutes.Get("/:id", userService.GetByID)
 	userRoutes.Get("/search", userService.SearchByName)
 
@@ -26,4 +31,12 @@ func setupUserRoutes(
 	user.Delete("/:id", userService.Delete, middleware.RequireAuth(userRepo),middleware.AdminOnly(roleRepo))
 	user.Put("/:id/role", userService.UpdateRole, middleware.RequireAuth(userRepo),middleware.AdminOnly(roleRepo))
 	user.Get("/inactive", userService.GetInactiveUsers, middleware.RequireAuth(userRepo),middleware.AdminOnly(roleRepo))
+	user.Post("/:id/revoke-sessions", middleware.DenyImpersonation, userService.RevokeSessions)
+	user.Post("/:id/unlock", userService.UnlockAccount)
+	user.Get("/:id/sessions", sessionService.GetUserSessions)
+	user.Delete("/:id/sessions/:sessionId", middleware.DenyImpersonation, sessionService.RevokeUserSession)
+	user.Post("/:id/impersonate", impersonationService.Start)
+	user.Get("/:id/scopes", userService.GetUserScopes)
+	user.Post("/:id/scopes", userService.AddUserScope)
+	user.Delete("/:id/scopes/:scopeId", userService.RemoveUserScope)
 }
\ No newline at end of file
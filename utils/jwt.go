@@ -1,6 +1,7 @@
 package utils
 
 import (
+	"errors"
 	"time"
 
 	"UAS/app/models"
@@ -10,73 +11,213 @@ import (
 	"github.com/google/uuid"
 )
 
-var jwtSecret = []byte("your-secret-key-change-this") 
+var ErrTokenRevoked = errors.New("token has been revoked")
 
-func GenerateToken(user *models.User, permissions []string) (string, error) {
-	claims := models.JWTClaims{
-		UserID:      user.ID.String(),
-		Email:       user.Email,
-		RoleID:      user.RoleID.String(),
-		Permissions: permissions,
+// twoFactorChallengeAudience - challenge token tidak boleh dipakai sebagai access/refresh token
+const twoFactorChallengeAudience = "2fa-challenge"
+
+const TwoFactorChallengeTTL = 5 * time.Minute
+
+// AccessTokenTTL - masa berlaku access token
+const AccessTokenTTL = 10 * time.Hour
+
+// RevocationChecker - dicek setiap kali token divalidasi (diimplementasikan oleh TokenRevocationRepository).
+// sessionID kosong untuk token tanpa sesi (refresh token, impersonation).
+type RevocationChecker interface {
+	IsTokenRevoked(jti, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error)
+}
+
+var revocationChecker RevocationChecker
+
+func SetRevocationChecker(checker RevocationChecker) {
+	revocationChecker = checker
+}
+
+func checkRevoked(claims *jwt.RegisteredClaims, sessionID string) error {
+	if revocationChecker == nil {
+		return nil
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return fmt.Errorf("invalid subject in token: %w", err)
+	}
+
+	var issuedAt time.Time
+	if claims.IssuedAt != nil {
+		issuedAt = claims.IssuedAt.Time
+	}
+
+	revoked, err := revocationChecker.IsTokenRevoked(claims.ID, sessionID, userID, issuedAt)
+	if err != nil {
+		return fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return ErrTokenRevoked
+	}
+	return nil
+}
+
+// GenerateToken - sessionID menghubungkan access token ke sesi login (lihat SessionRepository).
+// Permission tidak disimpan di token, selalu di-resolve dari role saat request (middleware.RequireAuth).
+func GenerateToken(user *models.User, sessionID uuid.UUID) (string, *models.JWTClaims, error) {
+	claims := &models.JWTClaims{
+		UserID:    user.ID.String(),
+		Email:     user.Email,
+		RoleID:    user.RoleID.String(),
+		SessionID: sessionID.String(),
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(10 * time.Hour)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
 			Issuer:    "achievement-system",
 			Subject:   user.ID.String(),
+			ID:        uuid.New().String(),
+		},
+	}
+
+	signed, err := signClaims(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
+}
+
+// GenerateImpersonationToken - access token untuk target user yang ditandai klaim "act"
+// berisi admin pembuatnya. Tidak punya sesi maupun refresh token.
+func GenerateImpersonationToken(target *models.User, admin *models.User, impersonationID uuid.UUID, ttl time.Duration) (string, *models.JWTClaims, error) {
+	claims := &models.JWTClaims{
+		UserID: target.ID.String(),
+		Email:  target.Email,
+		RoleID: target.RoleID.String(),
+		Act: &models.ImpersonationActor{
+			Subject:         admin.ID.String(),
+			Email:           admin.Email,
+			ImpersonationID: impersonationID.String(),
+		},
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			NotBefore: jwt.NewNumericDate(time.Now()),
+			Issuer:    "achievement-system",
+			Subject:   target.ID.String(),
+			ID:        uuid.New().String(),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	signed, err := signClaims(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
-func GenerateRefreshToken(userID uuid.UUID) (string, error) {
-	claims := jwt.RegisteredClaims{
-		ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
-		IssuedAt:  jwt.NewNumericDate(time.Now()),
-		Subject:   userID.String(),
+// GenerateRefreshToken - refresh token selalu terikat ke satu token family (lihat RefreshTokenRepository)
+func GenerateRefreshToken(userID uuid.UUID, familyID uuid.UUID) (string, *models.RefreshClaims, error) {
+	claims := &models.RefreshClaims{
+		FamilyID: familyID.String(),
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(7 * 24 * time.Hour)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Subject:   userID.String(),
+			ID:        uuid.New().String(),
+		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(jwtSecret)
+	signed, err := signClaims(claims)
+	if err != nil {
+		return "", nil, err
+	}
+	return signed, claims, nil
 }
 
 func ValidateToken(tokenString string) (*models.JWTClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return jwtSecret, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &models.JWTClaims{}, verificationKey)
 
 	if err != nil {
 		return nil, err
 	}
 
 	if claims, ok := token.Claims.(*models.JWTClaims); ok && token.Valid {
+		if isChallengeToken(&claims.RegisteredClaims) {
+			return nil, jwt.ErrTokenInvalidAudience
+		}
+		if err := checkRevoked(&claims.RegisteredClaims, claims.SessionID); err != nil {
+			return nil, err
+		}
 		return claims, nil
 	}
 
 	return nil, jwt.ErrSignatureInvalid
 }
 
-func ValidateRefreshToken(tokenString string) (*jwt.RegisteredClaims, error) {
+func ValidateRefreshToken(tokenString string) (*models.RefreshClaims, error) {
 	token, err := jwt.ParseWithClaims(
 			tokenString, 
-			&jwt.RegisteredClaims{},  
-			func(token *jwt.Token) (interface{}, error) {
-					if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-							return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-					}
-					return jwtSecret, nil
-			},
+			&models.RefreshClaims{},  
+			verificationKey,
 	)
 	
 	if err != nil {
 			return nil, err
 	}
 	
-	if claims, ok := token.Claims.(*jwt.RegisteredClaims); ok && token.Valid {
+	if claims, ok := token.Claims.(*models.RefreshClaims); ok && token.Valid {
+			if err := checkRevoked(&claims.RegisteredClaims, ""); err != nil {
+					return nil, err
+			}
 			return claims, nil
 	}
 	
 	return nil, jwt.ErrSignatureInvalid
-}
\ No newline at end of file
+}
+// GenerateTwoFactorChallengeToken - token singkat antara langkah password dan langkah kode 2FA
+func GenerateTwoFactorChallengeToken(userID uuid.UUID, purpose string) (string, error) {
+	claims := models.TwoFactorChallengeClaims{
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(TwoFactorChallengeTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "achievement-system",
+			Subject:   userID.String(),
+			Audience:  jwt.ClaimStrings{twoFactorChallengeAudience},
+			ID:        uuid.New().String(),
+		},
+	}
+
+	return signClaims(claims)
+}
+
+func ValidateTwoFactorChallengeToken(tokenString, purpose string) (uuid.UUID, error) {
+	token, err := jwt.ParseWithClaims(
+		tokenString,
+		&models.TwoFactorChallengeClaims{},
+		verificationKey,
+		jwt.WithAudience(twoFactorChallengeAudience),
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	claims, ok := token.Claims.(*models.TwoFactorChallengeClaims)
+	if !ok || !token.Valid {
+		return uuid.Nil, jwt.ErrSignatureInvalid
+	}
+	if claims.Purpose != purpose {
+		return uuid.Nil, errors.New("challenge token has wrong purpose")
+	}
+	if err := checkRevoked(&claims.RegisteredClaims, ""); err != nil {
+		return uuid.Nil, err
+	}
+
+	return uuid.Parse(claims.Subject)
+}
+
+func isChallengeToken(claims *jwt.RegisteredClaims) bool {
+	for _, aud := range claims.Audience {
+		if aud == twoFactorChallengeAudience {
+			return true
+		}
+	}
+	return false
+}
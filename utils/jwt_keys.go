@@ -0,0 +1,313 @@
+package utils
+
+import (
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"sync"
+
+	"UAS/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// legacyHMACSecret - secret lama yang dulu di-hardcode, hanya dipakai jika JWT_SECRET kosong
+const legacyHMACSecret = "your-secret-key-change-this"
+
+type SigningKey struct {
+	ID          string
+	Method      jwt.SigningMethod
+	SignKey     interface{} // []byte, *rsa.PrivateKey atau ed25519.PrivateKey
+	VerifyKey   interface{} // []byte, *rsa.PublicKey atau ed25519.PublicKey
+	CanSign     bool
+	IsSymmetric bool
+}
+
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+	// key HMAC untuk token lama yang tidak punya header kid
+	legacy *SigningKey
+}
+
+var (
+	keySetMu   sync.RWMutex
+	currentSet *KeySet
+)
+
+// LoadJWTKeys - baca konfigurasi key dari env / file PEM. Dipanggil sekali saat startup.
+func LoadJWTKeys() error {
+	set, err := buildKeySet(config.JWTConfig())
+	if err != nil {
+		return err
+	}
+
+	keySetMu.Lock()
+	currentSet = set
+	keySetMu.Unlock()
+
+	log.Printf("JWT signing key loaded: kid=%s alg=%s (%d verification keys)",
+		set.active.ID, set.active.Method.Alg(), len(set.keys))
+	return nil
+}
+
+func getKeySet() *KeySet {
+	keySetMu.RLock()
+	set := currentSet
+	keySetMu.RUnlock()
+	if set != nil {
+		return set
+	}
+
+	if err := LoadJWTKeys(); err != nil {
+		log.Fatal("Failed to load JWT keys: ", err)
+	}
+
+	keySetMu.RLock()
+	defer keySetMu.RUnlock()
+	return currentSet
+}
+
+func buildKeySet(cfg config.JWTKeyConfig) (*KeySet, error) {
+	set := &KeySet{keys: map[string]*SigningKey{}}
+
+	secret := cfg.Secret
+	if secret == "" {
+		secret = legacyHMACSecret
+		if cfg.Algorithm == "HS256" {
+			log.Println("Warning: JWT_SECRET is not set, using insecure default secret")
+		}
+	}
+	hmacKey := &SigningKey{
+		ID:          "hs256-default",
+		Method:      jwt.SigningMethodHS256,
+		SignKey:     []byte(secret),
+		VerifyKey:   []byte(secret),
+		CanSign:     true,
+		IsSymmetric: true,
+	}
+
+	switch cfg.Algorithm {
+	case "HS256":
+		if cfg.KeyID != "" {
+			hmacKey.ID = cfg.KeyID
+		}
+		set.active = hmacKey
+
+	case "RS256", "EdDSA":
+		pemBytes := []byte(cfg.PrivateKeyPEM)
+		if len(pemBytes) == 0 {
+			if cfg.PrivateKeyFile == "" {
+				return nil, fmt.Errorf("JWT_PRIVATE_KEY or JWT_PRIVATE_KEY_FILE is required for %s", cfg.Algorithm)
+			}
+			data, err := os.ReadFile(cfg.PrivateKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read JWT private key: %w", err)
+			}
+			pemBytes = data
+		}
+
+		key, err := parsePrivateKey(cfg.Algorithm, pemBytes)
+		if err != nil {
+			return nil, err
+		}
+		key.ID = cfg.KeyID
+		if key.ID == "" {
+			key.ID = keyThumbprint(key.VerifyKey)
+		}
+		set.active = key
+
+	default:
+		return nil, fmt.Errorf("unsupported JWT_ALGORITHM: %s", cfg.Algorithm)
+	}
+
+	set.keys[set.active.ID] = set.active
+
+	// Token HS256 lama (tanpa kid) tetap bisa diverifikasi selama secret masih dikonfigurasi.
+	// Secret default tidak pernah diterima setelah pindah ke key asimetris.
+	if set.active.IsSymmetric {
+		set.legacy = hmacKey
+	} else if cfg.Secret != "" {
+		hmacKey.CanSign = false
+		set.legacy = hmacKey
+	}
+
+	for kid, path := range cfg.VerificationKeyFiles {
+		if _, exists := set.keys[kid]; exists {
+			return nil, fmt.Errorf("duplicate JWT key id: %s", kid)
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read JWT verification key %s: %w", kid, err)
+		}
+		key, err := parsePublicKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT verification key %s: %w", kid, err)
+		}
+		key.ID = kid
+		set.keys[kid] = key
+	}
+
+	return set, nil
+}
+
+func parsePrivateKey(algorithm string, pemBytes []byte) (*SigningKey, error) {
+	switch algorithm {
+	case "RS256":
+		privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA private key: %w", err)
+		}
+		return &SigningKey{
+			Method:    jwt.SigningMethodRS256,
+			SignKey:   privateKey,
+			VerifyKey: &privateKey.PublicKey,
+			CanSign:   true,
+		}, nil
+
+	case "EdDSA":
+		privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pemBytes)
+		if err != nil {
+			return nil, fmt.Errorf("invalid Ed25519 private key: %w", err)
+		}
+		edKey, ok := privateKey.(ed25519.PrivateKey)
+		if !ok {
+			return nil, errors.New("private key is not an Ed25519 key")
+		}
+		return &SigningKey{
+			Method:    jwt.SigningMethodEdDSA,
+			SignKey:   edKey,
+			VerifyKey: edKey.Public(),
+			CanSign:   true,
+		}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported JWT algorithm: %s", algorithm)
+}
+
+// parsePublicKey - algoritma ditentukan dari tipe key di file PEM
+func parsePublicKey(pemBytes []byte) (*SigningKey, error) {
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM(pemBytes); err == nil {
+		return &SigningKey{Method: jwt.SigningMethodRS256, VerifyKey: rsaKey}, nil
+	}
+	if edKey, err := jwt.ParseEdPublicKeyFromPEM(pemBytes); err == nil {
+		return &SigningKey{Method: jwt.SigningMethodEdDSA, VerifyKey: edKey}, nil
+	}
+
+	// File private key juga diterima, hanya bagian public yang dipakai
+	for _, alg := range []string{"RS256", "EdDSA"} {
+		if key, err := parsePrivateKey(alg, pemBytes); err == nil {
+			key.SignKey = nil
+			key.CanSign = false
+			return key, nil
+		}
+	}
+
+	return nil, errors.New("unsupported key type, expected RSA or Ed25519 PEM")
+}
+
+func keyThumbprint(publicKey interface{}) string {
+	var raw []byte
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		raw = k.N.Bytes()
+	case ed25519.PublicKey:
+		raw = k
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:8])
+}
+
+// signClaims - tanda tangani claims dengan key aktif dan sertakan header kid
+func signClaims(claims jwt.Claims) (string, error) {
+	key := getKeySet().active
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	return token.SignedString(key.SignKey)
+}
+
+// verificationKey - keyfunc untuk jwt.Parse: pilih key berdasarkan kid dan pastikan algoritmanya cocok
+func verificationKey(token *jwt.Token) (interface{}, error) {
+	set := getKeySet()
+
+	var key *SigningKey
+	if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+		key = set.keys[kid]
+		if key == nil {
+			return nil, fmt.Errorf("unknown signing key: %s", kid)
+		}
+	} else {
+		key = set.legacy
+		if key == nil {
+			return nil, errors.New("token has no key id")
+		}
+	}
+
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+
+	return key.VerifyKey, nil
+}
+
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+type JWKSet struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS - public key yang aktif untuk verifikasi (key HMAC tidak pernah dipublikasikan)
+func JWKS() JWKSet {
+	set := getKeySet()
+	jwks := JWKSet{Keys: []JWK{}}
+
+	kids := make([]string, 0, len(set.keys))
+	for kid := range set.keys {
+		kids = append(kids, kid)
+	}
+	sort.Strings(kids)
+
+	for _, kid := range kids {
+		key := set.keys[kid]
+		switch pub := key.VerifyKey.(type) {
+		case *rsa.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "RSA",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				N:   base64.RawURLEncoding.EncodeToString(pub.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			jwks.Keys = append(jwks.Keys, JWK{
+				Kty: "OKP",
+				Kid: kid,
+				Use: "sig",
+				Alg: key.Method.Alg(),
+				Crv: "Ed25519",
+				X:   base64.RawURLEncoding.EncodeToString(pub),
+			})
+		}
+	}
+
+	return jwks
+}
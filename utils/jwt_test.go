@@ -0,0 +1,113 @@
+package utils
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"UAS/app/models"
+
+	"github.com/google/uuid"
+)
+
+// fakeRevocations - revoke per jti atau per sesi (sid)
+type fakeRevocations struct {
+	jtis     map[string]bool
+	sessions map[string]bool
+}
+
+func (f *fakeRevocations) IsTokenRevoked(jti, sessionID string, userID uuid.UUID, issuedAt time.Time) (bool, error) {
+	return f.jtis[jti] || (sessionID != "" && f.sessions[sessionID]), nil
+}
+
+func withRevocations(t *testing.T, f *fakeRevocations) {
+	t.Helper()
+	t.Setenv("JWT_ALGORITHM", "HS256")
+	t.Setenv("JWT_SECRET", "test-secret")
+	if err := LoadJWTKeys(); err != nil {
+		t.Fatal(err)
+	}
+	SetRevocationChecker(f)
+	t.Cleanup(func() { SetRevocationChecker(nil) })
+}
+
+func TestValidateTokenRejectsEveryTokenOfRevokedSession(t *testing.T) {
+	revocations := &fakeRevocations{jtis: map[string]bool{}, sessions: map[string]bool{}}
+	withRevocations(t, revocations)
+
+	user := &models.User{ID: uuid.New(), RoleID: uuid.New(), Email: "a@example.com"}
+	sessionID := uuid.New()
+	otherSessionID := uuid.New()
+
+	// Dua access token dari sesi yang sama (sebelum & sesudah refresh), satu dari sesi lain
+	before, _, err := GenerateToken(user, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after, _, err := GenerateToken(user, sessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, _, err := GenerateToken(user, otherSessionID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, token := range []string{before, after, other} {
+		if _, err := ValidateToken(token); err != nil {
+			t.Fatalf("token rejected before revocation: %v", err)
+		}
+	}
+
+	revocations.sessions[sessionID.String()] = true
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"token issued before refresh", before, ErrTokenRevoked},
+		{"token issued after refresh", after, ErrTokenRevoked},
+		{"token of another session", other, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ValidateToken(tt.token)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("err = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTokenRejectsRevokedJTI(t *testing.T) {
+	revocations := &fakeRevocations{jtis: map[string]bool{}, sessions: map[string]bool{}}
+	withRevocations(t, revocations)
+
+	user := &models.User{ID: uuid.New(), RoleID: uuid.New()}
+	token, claims, err := GenerateToken(user, uuid.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.SessionID == "" {
+		t.Fatal("access token has no sid claim")
+	}
+
+	revocations.jtis[claims.ID] = true
+	if _, err := ValidateToken(token); !errors.Is(err, ErrTokenRevoked) {
+		t.Errorf("err = %v, want %v", err, ErrTokenRevoked)
+	}
+}
+
+func TestChallengeTokenIsNotAnAccessToken(t *testing.T) {
+	withRevocations(t, &fakeRevocations{})
+
+	challenge, err := GenerateTwoFactorChallengeToken(uuid.New(), models.TwoFactorPurposeVerify)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ValidateToken(challenge); err == nil {
+		t.Error("challenge token accepted as access token")
+	}
+}
@@ -0,0 +1,95 @@
+package utils
+
+import (
+	"fmt"
+	"log"
+	"net/smtp"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"UAS/config"
+)
+
+type MailMessage struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer - pengirim email, implementasi dipilih lewat MAIL_DRIVER
+type Mailer interface {
+	Send(msg MailMessage) error
+}
+
+// NewMailer - driver "smtp" butuh SMTP_HOST, selain itu email hanya ditulis ke log / file
+func NewMailer(cfg config.MailSettings) (Mailer, error) {
+	switch cfg.Driver {
+	case "smtp":
+		if cfg.Host == "" {
+			return nil, fmt.Errorf("SMTP_HOST is required for MAIL_DRIVER=smtp")
+		}
+		return &SMTPMailer{cfg: cfg}, nil
+	case "log", "file", "":
+		return &LogMailer{From: cfg.From, FilePath: cfg.LogFile}, nil
+	}
+
+	return nil, fmt.Errorf("unsupported MAIL_DRIVER: %s", cfg.Driver)
+}
+
+type SMTPMailer struct {
+	cfg config.MailSettings
+}
+
+func (m *SMTPMailer) Send(msg MailMessage) error {
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+
+	return smtp.SendMail(addr, auth, m.cfg.From, []string{msg.To}, buildMessage(m.cfg.From, msg))
+}
+
+// LogMailer - untuk development: email ditulis ke file (atau stdout) dan tidak benar-benar dikirim
+type LogMailer struct {
+	From     string
+	FilePath string
+
+	mu sync.Mutex
+}
+
+func (m *LogMailer) Send(msg MailMessage) error {
+	entry := fmt.Sprintf("----- %s -----\n%s\n", time.Now().Format(time.RFC3339), buildMessage(m.From, msg))
+
+	if m.FilePath == "" {
+		log.Print(entry)
+		return nil
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	f, err := os.OpenFile(m.FilePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(entry)
+	return err
+}
+
+func buildMessage(from string, msg MailMessage) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + from + "\r\n")
+	b.WriteString("To: " + msg.To + "\r\n")
+	b.WriteString("Subject: " + msg.Subject + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(msg.Body)
+	return []byte(b.String())
+}
@@ -0,0 +1,370 @@
+package utils
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"UAS/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	oidcDiscoveryTTL = time.Hour
+	// JWKS diambil ulang saat ada kid baru, tapi tidak lebih sering dari ini
+	oidcJWKSMinRefresh = time.Minute
+)
+
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+}
+
+type OIDCTokenResponse struct {
+	IDToken     string `json:"id_token"`
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// OIDCIDToken - claim ID token yang sudah diverifikasi, Claims berisi semua claim mentah
+type OIDCIDToken struct {
+	Issuer            string
+	Subject           string
+	Email             string
+	EmailVerified     bool
+	Name              string
+	PreferredUsername string
+	Claims            map[string]interface{}
+}
+
+// StringClaim - ambil claim sebagai string (angka juga diterima, mis. NIM numerik)
+func (t *OIDCIDToken) StringClaim(name string) string {
+	switch v := t.Claims[name].(type) {
+	case string:
+		return strings.TrimSpace(v)
+	case float64:
+		return fmt.Sprintf("%.0f", v)
+	}
+	return ""
+}
+
+// OIDCProvider - client authorization code + PKCE untuk satu identity provider.
+// Discovery dan JWKS diambil saat pertama dipakai lalu di-cache.
+type OIDCProvider struct {
+	cfg        config.OIDCSettings
+	httpClient *http.Client
+
+	mu            sync.Mutex
+	discovery     *oidcDiscovery
+	discoveredAt  time.Time
+	keys          map[string]interface{}
+	keysFetchedAt time.Time
+}
+
+func NewOIDCProvider(cfg config.OIDCSettings) *OIDCProvider {
+	return &OIDCProvider{
+		cfg:        cfg,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		keys:       map[string]interface{}{},
+	}
+}
+
+func (p *OIDCProvider) Enabled() bool {
+	return p != nil && p.cfg.Enabled()
+}
+
+// GeneratePKCE - code_verifier acak dan code_challenge S256 (RFC 7636)
+func GeneratePKCE() (verifier, challenge string, err error) {
+	verifier, err = GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+	sum := sha256.Sum256([]byte(verifier))
+	return verifier, base64.RawURLEncoding.EncodeToString(sum[:]), nil
+}
+
+func (p *OIDCProvider) AuthCodeURL(state, nonce, codeChallenge string) (string, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return "", err
+	}
+
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.cfg.ClientID)
+	params.Set("redirect_uri", p.cfg.RedirectURL)
+	params.Set("scope", strings.Join(p.cfg.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+
+	separator := "?"
+	if strings.Contains(discovery.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return discovery.AuthorizationEndpoint + separator + params.Encode(), nil
+}
+
+// Exchange - tukar authorization code dengan token (client_secret_basic jika secret dikonfigurasi)
+func (p *OIDCProvider) Exchange(code, codeVerifier string) (*OIDCTokenResponse, error) {
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.cfg.RedirectURL)
+	form.Set("code_verifier", codeVerifier)
+	if p.cfg.ClientSecret == "" {
+		form.Set("client_id", p.cfg.ClientID)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, discovery.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if p.cfg.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(p.cfg.ClientID), url.QueryEscape(p.cfg.ClientSecret))
+	}
+
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		var oauthErr struct {
+			Error            string `json:"error"`
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&oauthErr)
+		return nil, fmt.Errorf("token endpoint returned %d: %s %s", resp.StatusCode, oauthErr.Error, oauthErr.ErrorDescription)
+	}
+
+	var token OIDCTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("invalid token response: %w", err)
+	}
+	if token.IDToken == "" {
+		return nil, errors.New("token response has no id_token")
+	}
+
+	return &token, nil
+}
+
+// VerifyIDToken - cek tanda tangan (JWKS), issuer, audience, expiry dan nonce
+func (p *OIDCProvider) VerifyIDToken(rawIDToken, nonce string) (*OIDCIDToken, error) {
+	claims := jwt.MapClaims{}
+	_, err := jwt.ParseWithClaims(
+		rawIDToken,
+		claims,
+		p.idTokenKey,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "ES256", "ES384", "EdDSA"}),
+		jwt.WithIssuer(p.cfg.Issuer),
+		jwt.WithAudience(p.cfg.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(30*time.Second),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("invalid id_token: %w", err)
+	}
+
+	if tokenNonce, _ := claims["nonce"].(string); tokenNonce != nonce {
+		return nil, errors.New("invalid id_token: nonce mismatch")
+	}
+
+	token := &OIDCIDToken{Issuer: p.cfg.Issuer, Claims: claims}
+	token.Subject, _ = claims["sub"].(string)
+	token.Email, _ = claims["email"].(string)
+	token.Name, _ = claims["name"].(string)
+	token.PreferredUsername, _ = claims["preferred_username"].(string)
+
+	// Beberapa IdP mengirim email_verified sebagai string
+	switch v := claims["email_verified"].(type) {
+	case bool:
+		token.EmailVerified = v
+	case string:
+		token.EmailVerified = v == "true"
+	}
+
+	if token.Subject == "" {
+		return nil, errors.New("invalid id_token: missing sub")
+	}
+
+	return token, nil
+}
+
+func (p *OIDCProvider) getDiscovery() (*oidcDiscovery, error) {
+	if !p.Enabled() {
+		return nil, errors.New("OIDC is not configured")
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovery != nil && time.Since(p.discoveredAt) < oidcDiscoveryTTL {
+		return p.discovery, nil
+	}
+
+	var discovery oidcDiscovery
+	if err := p.getJSON(p.cfg.Issuer+"/.well-known/openid-configuration", &discovery); err != nil {
+		return nil, fmt.Errorf("OIDC discovery failed: %w", err)
+	}
+	if strings.TrimSuffix(discovery.Issuer, "/") != p.cfg.Issuer {
+		return nil, fmt.Errorf("OIDC discovery issuer mismatch: %s", discovery.Issuer)
+	}
+	if discovery.AuthorizationEndpoint == "" || discovery.TokenEndpoint == "" || discovery.JWKSURI == "" {
+		return nil, errors.New("OIDC discovery document is incomplete")
+	}
+
+	p.discovery = &discovery
+	p.discoveredAt = time.Now()
+	return p.discovery, nil
+}
+
+func (p *OIDCProvider) idTokenKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	discovery, err := p.getDiscovery()
+	if err != nil {
+		return nil, err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+
+	// Kid belum dikenal: kemungkinan IdP baru rotasi key
+	if time.Since(p.keysFetchedAt) < oidcJWKSMinRefresh {
+		return nil, fmt.Errorf("unknown id_token signing key: %s", kid)
+	}
+
+	var set struct {
+		Keys []JWK `json:"keys"`
+	}
+	if err := p.getJSON(discovery.JWKSURI, &set); err != nil {
+		return nil, fmt.Errorf("failed to fetch IdP JWKS: %w", err)
+	}
+
+	keys := map[string]interface{}{}
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		if key, err := jwk.PublicKey(); err == nil {
+			keys[jwk.Kid] = key
+		}
+	}
+	p.keys = keys
+	p.keysFetchedAt = time.Now()
+
+	if key := p.lookupKey(kid); key != nil {
+		return key, nil
+	}
+	return nil, fmt.Errorf("unknown id_token signing key: %s", kid)
+}
+
+// lookupKey - token tanpa kid hanya diterima jika IdP punya tepat satu key
+func (p *OIDCProvider) lookupKey(kid string) interface{} {
+	if kid != "" {
+		return p.keys[kid]
+	}
+	if len(p.keys) == 1 {
+		for _, key := range p.keys {
+			return key
+		}
+	}
+	return nil
+}
+
+func (p *OIDCProvider) getJSON(endpoint string, out interface{}) error {
+	resp, err := p.httpClient.Get(endpoint)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s returned %d", endpoint, resp.StatusCode)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(out)
+}
+
+// PublicKey - konversi JWK (RSA, EC P-256/P-384, Ed25519) ke public key Go
+func (k JWK) PublicKey() (interface{}, error) {
+	decode := base64.RawURLEncoding.DecodeString
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decode(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decode(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		default:
+			return nil, fmt.Errorf("unsupported EC curve: %s", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decode(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+
+	case "OKP":
+		if k.Crv != "Ed25519" {
+			return nil, fmt.Errorf("unsupported OKP curve: %s", k.Crv)
+		}
+		x, err := decode(k.X)
+		if err != nil {
+			return nil, err
+		}
+		if len(x) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid Ed25519 key size")
+		}
+		return ed25519.PublicKey(x), nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type: %s", k.Kty)
+}
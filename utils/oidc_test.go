@@ -0,0 +1,345 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"UAS/config"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	mockClientID    = "uas-client"
+	mockRedirectURL = "http://localhost:3000/uas/api/auth/oidc/callback"
+	mockKeyID       = "mock-key-1"
+)
+
+// mockIdP - identity provider minimal: discovery, authorize, token (dengan cek PKCE) dan JWKS
+type mockIdP struct {
+	t      *testing.T
+	server *httptest.Server
+	key    *rsa.PrivateKey
+
+	mu sync.Mutex
+	// code -> code_challenge dan nonce dari request authorize
+	codes map[string]mockAuthorization
+}
+
+type mockAuthorization struct {
+	challenge string
+	nonce     string
+}
+
+func newMockIdP(t *testing.T) *mockIdP {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	idp := &mockIdP{t: t, key: key, codes: map[string]mockAuthorization{}}
+
+	mux := http.NewServeMux()
+	discovery := func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{
+			"issuer":                 idp.server.URL,
+			"authorization_endpoint": idp.server.URL + "/authorize",
+			"token_endpoint":         idp.server.URL + "/token",
+			"jwks_uri":               idp.server.URL + "/jwks",
+		})
+	}
+	mux.HandleFunc("/.well-known/openid-configuration", discovery)
+	// Dokumen yang sama di path lain, issuer-nya tetap server.URL (untuk tes issuer mismatch)
+	mux.HandleFunc("/realms/other/.well-known/openid-configuration", discovery)
+	mux.HandleFunc("/authorize", idp.authorize)
+	mux.HandleFunc("/token", idp.token)
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"keys": []JWK{{
+				Kty: "RSA",
+				Kid: mockKeyID,
+				Use: "sig",
+				Alg: "RS256",
+				N:   base64.RawURLEncoding.EncodeToString(key.PublicKey.N.Bytes()),
+				E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.PublicKey.E)).Bytes()),
+			}},
+		})
+	})
+
+	idp.server = httptest.NewServer(mux)
+	t.Cleanup(idp.server.Close)
+	return idp
+}
+
+func (idp *mockIdP) provider() *OIDCProvider {
+	return NewOIDCProvider(config.OIDCSettings{
+		Issuer:      idp.server.URL,
+		ClientID:    mockClientID,
+		RedirectURL: mockRedirectURL,
+		Scopes:      []string{"openid", "email", "profile"},
+	})
+}
+
+// authorize - user langsung dianggap login, redirect ke redirect_uri dengan code + state
+func (idp *mockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if q.Get("client_id") != mockClientID || q.Get("redirect_uri") != mockRedirectURL ||
+		q.Get("code_challenge_method") != "S256" || q.Get("code_challenge") == "" {
+		http.Error(w, "bad authorize request", http.StatusBadRequest)
+		return
+	}
+
+	code, err := GenerateSecureToken(16)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	idp.mu.Lock()
+	idp.codes[code] = mockAuthorization{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	idp.mu.Unlock()
+
+	redirect := url.Values{}
+	redirect.Set("code", code)
+	redirect.Set("state", q.Get("state"))
+	http.Redirect(w, r, q.Get("redirect_uri")+"?"+redirect.Encode(), http.StatusFound)
+}
+
+func (idp *mockIdP) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_request"})
+		return
+	}
+
+	idp.mu.Lock()
+	auth, ok := idp.codes[r.PostForm.Get("code")]
+	// Authorization code hanya boleh dipakai sekali
+	delete(idp.codes, r.PostForm.Get("code"))
+	idp.mu.Unlock()
+
+	if !ok || r.PostForm.Get("grant_type") != "authorization_code" ||
+		r.PostForm.Get("client_id") != mockClientID || r.PostForm.Get("redirect_uri") != mockRedirectURL {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	sum := sha256.Sum256([]byte(r.PostForm.Get("code_verifier")))
+	if base64.RawURLEncoding.EncodeToString(sum[:]) != auth.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{
+			"error":             "invalid_grant",
+			"error_description": "PKCE verification failed",
+		})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"id_token":     idp.signIDToken(auth.nonce, nil),
+		"access_token": "mock-access-token",
+		"token_type":   "Bearer",
+		"expires_in":   3600,
+	})
+}
+
+// signIDToken - ID token valid, lalu claim di overrides menimpa (nil = hapus claim)
+func (idp *mockIdP) signIDToken(nonce string, overrides jwt.MapClaims) string {
+	idp.t.Helper()
+
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":            idp.server.URL,
+		"aud":            mockClientID,
+		"sub":            "idp-user-1",
+		"email":          "student@example.ac.id",
+		"email_verified": true,
+		"nonce":          nonce,
+		"iat":            now.Unix(),
+		"exp":            now.Add(5 * time.Minute).Unix(),
+	}
+	for name, value := range overrides {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = mockKeyID
+	signed, err := token.SignedString(idp.key)
+	if err != nil {
+		idp.t.Fatalf("sign id_token: %v", err)
+	}
+	return signed
+}
+
+// login - jalankan redirect ke IdP dan kembalikan code + state dari callback
+func (idp *mockIdP) login(t *testing.T, authURL string) (code, state string) {
+	t.Helper()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize request: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusFound {
+		t.Fatalf("authorize returned %d", resp.StatusCode)
+	}
+
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("invalid callback: %v", err)
+	}
+	if !strings.HasPrefix(callback.String(), mockRedirectURL) {
+		t.Fatalf("redirected to %s, want %s", callback, mockRedirectURL)
+	}
+	return callback.Query().Get("code"), callback.Query().Get("state")
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestOIDCAuthorizationCodeFlow(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	verifier, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatalf("GeneratePKCE: %v", err)
+	}
+	authURL, err := provider.AuthCodeURL("state-123", "nonce-123", challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+
+	code, state := idp.login(t, authURL)
+	if state != "state-123" {
+		t.Fatalf("state = %q, want state-123", state)
+	}
+
+	tokens, err := provider.Exchange(code, verifier)
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+
+	idToken, err := provider.VerifyIDToken(tokens.IDToken, "nonce-123")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if idToken.Subject != "idp-user-1" || idToken.Email != "student@example.ac.id" || !idToken.EmailVerified {
+		t.Fatalf("unexpected claims: %+v", idToken)
+	}
+}
+
+func TestOIDCExchangeRejectsWrongCodeVerifier(t *testing.T) {
+	idp := newMockIdP(t)
+	provider := idp.provider()
+
+	_, challenge, err := GeneratePKCE()
+	if err != nil {
+		t.Fatalf("GeneratePKCE: %v", err)
+	}
+	authURL, err := provider.AuthCodeURL("state", "nonce", challenge)
+	if err != nil {
+		t.Fatalf("AuthCodeURL: %v", err)
+	}
+	code, _ := idp.login(t, authURL)
+
+	// Verifier dari pasangan PKCE lain (mis. code dicuri dan dipakai di sesi penyerang)
+	otherVerifier, _, _ := GeneratePKCE()
+	if _, err := provider.Exchange(code, otherVerifier); err == nil {
+		t.Fatal("Exchange accepted a code_verifier that does not match the code_challenge")
+	}
+}
+
+func TestOIDCVerifyIDTokenRejects(t *testing.T) {
+	idp := newMockIdP(t)
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	resign := func(key interface{}, method jwt.SigningMethod, kid string) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{
+			"iss":   idp.server.URL,
+			"aud":   mockClientID,
+			"sub":   "idp-user-1",
+			"nonce": "nonce",
+			"exp":   time.Now().Add(5 * time.Minute).Unix(),
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		nonce string
+	}{
+		{"nonce mismatch", idp.signIDToken("nonce", nil), "other-nonce"},
+		{"missing nonce", idp.signIDToken("nonce", jwt.MapClaims{"nonce": nil}), "nonce"},
+		{"wrong issuer", idp.signIDToken("nonce", jwt.MapClaims{"iss": "https://evil.example.com"}), "nonce"},
+		{"wrong audience", idp.signIDToken("nonce", jwt.MapClaims{"aud": "other-client"}), "nonce"},
+		{"expired beyond leeway", idp.signIDToken("nonce", jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}), "nonce"},
+		{"missing exp", idp.signIDToken("nonce", jwt.MapClaims{"exp": nil}), "nonce"},
+		{"missing sub", idp.signIDToken("nonce", jwt.MapClaims{"sub": nil}), "nonce"},
+		{"signed by unknown key", resign(otherKey, jwt.SigningMethodRS256, mockKeyID), "nonce"},
+		{"unknown kid", resign(idp.key, jwt.SigningMethodRS256, "rotated-away"), "nonce"},
+		{"HMAC with client id", resign([]byte(mockClientID), jwt.SigningMethodHS256, mockKeyID), "nonce"},
+		{"garbage", "not-a-jwt", "nonce"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Provider baru per kasus supaya batas refresh JWKS tidak saling mempengaruhi
+			if _, err := idp.provider().VerifyIDToken(tt.token, tt.nonce); err == nil {
+				t.Fatal("VerifyIDToken accepted an invalid id_token")
+			}
+		})
+	}
+}
+
+func TestOIDCVerifyIDTokenAcceptsClockSkewWithinLeeway(t *testing.T) {
+	idp := newMockIdP(t)
+
+	raw := idp.signIDToken("nonce", jwt.MapClaims{"exp": time.Now().Add(-10 * time.Second).Unix()})
+	if _, err := idp.provider().VerifyIDToken(raw, "nonce"); err != nil {
+		t.Fatalf("VerifyIDToken rejected a token expired within the leeway: %v", err)
+	}
+}
+
+func TestOIDCDiscoveryIssuerMismatch(t *testing.T) {
+	idp := newMockIdP(t)
+
+	// Issuer yang dikonfigurasi berbeda dengan issuer di dokumen discovery
+	provider := NewOIDCProvider(config.OIDCSettings{
+		Issuer:      idp.server.URL + "/realms/other",
+		ClientID:    mockClientID,
+		RedirectURL: mockRedirectURL,
+	})
+	if _, err := provider.AuthCodeURL("state", "nonce", "challenge"); err == nil {
+		t.Fatal("AuthCodeURL accepted a discovery document from another issuer")
+	}
+}
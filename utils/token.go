@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+)
+
+// GenerateSecureToken - token acak yang aman untuk URL (dipakai untuk reset password dsb.)
+func GenerateSecureToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken - hanya hash SHA-256 dari token yang disimpan di database
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// GenerateAPIKey - format <awalan>_<prefix>_<secret>. Prefix disimpan apa adanya untuk lookup,
+// key lengkap hanya disimpan dalam bentuk hash.
+func GenerateAPIKey(keyPrefix string) (key, prefix string, err error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", err
+	}
+	prefix = hex.EncodeToString(b)
+
+	secret, err := GenerateSecureToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	return keyPrefix + "_" + prefix + "_" + secret, prefix, nil
+}
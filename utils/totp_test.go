@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// Secret & vektor dari RFC 6238 Appendix B (SHA1), dipotong ke 6 digit
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPRFCVectors(t *testing.T) {
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+	}
+
+	for _, tt := range tests {
+		now := time.Unix(tt.unix, 0)
+		step, ok := ValidateTOTP(rfcSecret, tt.code, now)
+		if !ok {
+			t.Errorf("t=%d: code %s rejected", tt.unix, tt.code)
+			continue
+		}
+		if step != tt.unix/totpPeriod {
+			t.Errorf("t=%d: step = %d, want %d", tt.unix, step, tt.unix/totpPeriod)
+		}
+	}
+}
+
+func TestValidateTOTPSkew(t *testing.T) {
+	code := "050471" // step 1111111111/30
+	base := time.Unix(1111111111, 0)
+
+	tests := []struct {
+		name   string
+		offset time.Duration
+		want   bool
+	}{
+		{"same step", 0, true},
+		{"one step later", 30 * time.Second, true},
+		{"one step earlier", -30 * time.Second, true},
+		{"two steps later", 60 * time.Second, false},
+		{"two steps earlier", -60 * time.Second, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(rfcSecret, code, base.Add(tt.offset)); ok != tt.want {
+				t.Errorf("ok = %v, want %v", ok, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPRejectsMalformed(t *testing.T) {
+	now := time.Unix(59, 0)
+	tests := []struct {
+		name, secret, code string
+	}{
+		{"short code", rfcSecret, "28708"},
+		{"long code", rfcSecret, "2870820"},
+		{"wrong code", rfcSecret, "000000"},
+		{"invalid secret", "not-base32!", "287082"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := ValidateTOTP(tt.secret, tt.code, now); ok {
+				t.Errorf("code %q accepted", tt.code)
+			}
+		})
+	}
+}
+
+func TestValidateTOTPAcceptsLowercaseSecretAndSpaces(t *testing.T) {
+	if _, ok := ValidateTOTP(strings.ToLower(rfcSecret), " 287082 ", time.Unix(59, 0)); !ok {
+		t.Error("code rejected")
+	}
+}
+
+func TestGenerateTOTPSecretRoundTrip(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	code := hotp(key, now.Unix()/totpPeriod)
+	if _, ok := ValidateTOTP(secret, code, now); !ok {
+		t.Error("freshly generated code rejected")
+	}
+}
+
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != 10 {
+		t.Fatalf("got %d codes, want 10", len(codes))
+	}
+
+	seen := map[string]bool{}
+	for _, code := range codes {
+		if len(code) != 11 || code[5] != '-' {
+			t.Errorf("unexpected format %q", code)
+		}
+		if seen[code] {
+			t.Errorf("duplicate code %q", code)
+		}
+		seen[code] = true
+	}
+
+	if got := NormalizeRecoveryCode(" ABCDE-FGHJK "); got != "abcde-fghjk" {
+		t.Errorf("NormalizeRecoveryCode = %q", got)
+	}
+}